@@ -0,0 +1,18 @@
+module json_codec_example
+
+go 1.23.3
+
+require (
+	github.com/GorgoFramework/gorgo v0.0.4
+	github.com/goccy/go-json v0.10.5
+	github.com/valyala/fasthttp v1.62.0
+)
+
+require (
+	github.com/BurntSushi/toml v1.5.0 // indirect
+	github.com/andybalholm/brotli v1.1.1 // indirect
+	github.com/klauspost/compress v1.18.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+)
+
+replace github.com/GorgoFramework/gorgo => ../../
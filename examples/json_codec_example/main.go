@@ -0,0 +1,30 @@
+package main
+
+import (
+	"io"
+	"log"
+
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+	gojson "github.com/goccy/go-json"
+)
+
+// goJSON plugs goccy/go-json into Gorgo
+type goJSON struct{}
+
+func (goJSON) Marshal(v interface{}) ([]byte, error)      { return gojson.Marshal(v) }
+func (goJSON) Unmarshal(data []byte, v interface{}) error { return gojson.Unmarshal(data, v) }
+func (goJSON) NewEncoder(w io.Writer) gorgo.JSONEncoder   { return gojson.NewEncoder(w) }
+
+func main() {
+	gorgo.SetJSONCodec(goJSON{})
+
+	app := gorgo.New()
+
+	app.Get("/", func(ctx *gorgo.Context) error {
+		return ctx.JSON(gorgo.Map{"message": "Hello, World!", "codec": "goccy/go-json"})
+	})
+
+	if err := app.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+	"github.com/valyala/fasthttp"
+)
+
+// BenchmarkContextJSON compares ctx.JSON with encoding/json and goccy/go-json:
+//
+//	go test -bench ContextJSON -benchmem
+func BenchmarkContextJSON(b *testing.B) {
+	payload := gorgo.Map{
+		"id":    12345,
+		"name":  "Gorgo",
+		"tags":  []string{"fast", "http", "framework"},
+		"owner": gorgo.Map{"id": 1, "email": "owner@example.com"},
+	}
+
+	for _, bc := range []struct {
+		name  string
+		codec gorgo.JSONCodec
+	}{
+		{"encoding/json", gorgo.StdJSONCodec{}},
+		{"goccy/go-json", goJSON{}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			gorgo.SetJSONCodec(bc.codec)
+			defer gorgo.SetJSONCodec(nil)
+
+			fastCtx := &fasthttp.RequestCtx{}
+			ctx := gorgo.NewContext(fastCtx, nil, nil)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				fastCtx.Response.ResetBody()
+				if err := ctx.JSON(payload); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
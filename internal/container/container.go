@@ -1,13 +1,20 @@
 package container
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 )
 
+// ErrServiceExists is returned by Register in strict mode when the name is
+// already registered
+var ErrServiceExists = errors.New("service already registered")
+
 type Container struct {
 	services map[string]interface{}
+	strict   bool
 	mu       sync.RWMutex
 }
 
@@ -17,7 +24,42 @@ func NewContainer() *Container {
 	}
 }
 
-func (c *Container) Register(name string, service interface{}) {
+// SetStrict selects whether Register refuses names that are already
+// registered (true) or overwrites them (false, the default)
+func (c *Container) SetStrict(strict bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.strict = strict
+}
+
+// Register adds a service under name. An existing service with that name
+// is overwritten, unless the container is strict; then ErrServiceExists is
+// returned and the existing service is kept.
+func (c *Container) Register(name string, service interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.services[name]; exists && c.strict {
+		return fmt.Errorf("%w: %s", ErrServiceExists, name)
+	}
+	c.services[name] = service
+	return nil
+}
+
+// RegisterIfAbsent adds a service unless name is already registered. It
+// reports whether the service was added.
+func (c *Container) RegisterIfAbsent(name string, service interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.services[name]; exists {
+		return false
+	}
+	c.services[name] = service
+	return true
+}
+
+// Replace adds or overwrites a service even in strict mode, for
+// intentional overrides such as replacing a default implementation
+func (c *Container) Replace(name string, service interface{}) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.services[name] = service
@@ -45,3 +87,52 @@ func (c *Container) GetTyped(name string, target interface{}) error {
 	targetValue.Elem().Set(serviceValue)
 	return nil
 }
+
+// FindByType returns the name of the service assignable to serviceType.
+// The same service registered under several names counts once; distinct
+// matching services are ambiguous. The first name in sorted order is
+// returned for determinism.
+func (c *Container) FindByType(serviceType reflect.Type) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.services))
+	for name := range c.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var found string
+	var match interface{}
+	for _, name := range names {
+		service := c.services[name]
+		if service == nil || !reflect.TypeOf(service).AssignableTo(serviceType) {
+			continue
+		}
+		if found == "" {
+			found, match = name, service
+			continue
+		}
+		if !sameService(match, service) {
+			return "", fmt.Errorf("services %s and %s both provide %s", found, name, serviceType)
+		}
+	}
+
+	if found == "" {
+		return "", fmt.Errorf("no service provides %s", serviceType)
+	}
+	return found, nil
+}
+
+// sameService reports whether a and b are the same instance
+func sameService(a, b interface{}) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Chan, reflect.Func, reflect.Slice, reflect.UnsafePointer:
+		return va.Pointer() == vb.Pointer()
+	}
+	return va.Type().Comparable() && a == b
+}
@@ -1,7 +1,9 @@
 package container
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -106,6 +108,41 @@ func TestContainer_RegisterOverwrite(t *testing.T) {
 	}
 }
 
+func TestContainer_Strict(t *testing.T) {
+	container := NewContainer()
+	container.SetStrict(true)
+
+	if err := container.Register("service", &TestService{Name: "original"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := container.Register("service", &TestService{Name: "second"})
+	if !errors.Is(err, ErrServiceExists) {
+		t.Fatalf("expected ErrServiceExists, got %v", err)
+	}
+	if retrieved, _ := container.Get("service"); retrieved.(*TestService).Name != "original" {
+		t.Error("strict Register must keep the existing service")
+	}
+
+	container.Replace("service", &TestService{Name: "replaced"})
+	if retrieved, _ := container.Get("service"); retrieved.(*TestService).Name != "replaced" {
+		t.Error("Replace must overwrite in strict mode")
+	}
+}
+
+func TestContainer_RegisterIfAbsent(t *testing.T) {
+	container := NewContainer()
+
+	if !container.RegisterIfAbsent("service", &TestService{Name: "first"}) {
+		t.Fatal("expected the first registration to succeed")
+	}
+	if container.RegisterIfAbsent("service", &TestService{Name: "second"}) {
+		t.Error("expected the second registration to be skipped")
+	}
+	if retrieved, _ := container.Get("service"); retrieved.(*TestService).Name != "first" {
+		t.Error("RegisterIfAbsent must not overwrite")
+	}
+}
+
 func TestContainer_Get(t *testing.T) {
 	container := NewContainer()
 	service := &TestService{Name: "test", ID: 42}
@@ -310,3 +347,29 @@ func BenchmarkContainer_GetTyped(b *testing.B) {
 		container.GetTyped("benchmark-service", &retrieved)
 	}
 }
+
+func TestContainer_FindByType(t *testing.T) {
+	container := NewContainer()
+	service := &TestService{Name: "test", ID: 1}
+	container.Register("primary", service)
+	container.Register("alias", service)
+	container.Register("impl", &TestImplementation{value: "a"})
+
+	name, err := container.FindByType(reflect.TypeOf(service))
+	if err != nil || name != "alias" {
+		t.Fatalf("expected alias, got %q (%v)", name, err)
+	}
+
+	if _, err := container.FindByType(reflect.TypeOf((*TestInterface)(nil)).Elem()); err != nil {
+		t.Fatalf("expected interface to resolve, got %v", err)
+	}
+
+	container.Register("other", &TestImplementation{value: "b"})
+	if _, err := container.FindByType(reflect.TypeOf((*TestInterface)(nil)).Elem()); err == nil {
+		t.Fatal("expected ambiguous services to fail")
+	}
+
+	if _, err := container.FindByType(reflect.TypeOf("")); err == nil {
+		t.Fatal("expected missing service to fail")
+	}
+}
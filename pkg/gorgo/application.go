@@ -2,27 +2,74 @@ package gorgo
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/GorgoFramework/gorgo/internal/container"
 	"github.com/valyala/fasthttp"
 )
 
+// Container keys of framework services
+const (
+	// EventBusService is the application *EventBus
+	EventBusService = "eventbus"
+	// RouterService is the application *Router
+	RouterService = "router"
+)
+
 type HandlerFunc func(ctx *Context) error
 type Map map[string]any
 
 type Application struct {
-	container       *container.Container
-	pluginManager   *PluginManager
-	config          Config
-	server          *fasthttp.Server
-	router          *Router
-	middlewareChain *MiddlewareChain
+	container        *container.Container
+	pluginManager    *PluginManager
+	config           Config
+	server           *fasthttp.Server
+	router           *Router
+	middlewareChain  *MiddlewareChain
+	preMiddleware    *MiddlewareChain
+	serverOptions    []func(s *fasthttp.Server)
+	versioning       *versioning
+	connTracker      atomic.Pointer[connTracker]
+	errorHandler     func(ctx *Context, err error)
+	errorHooks       []func(ctx *Context, err error)
+	errorFormat      ErrorFormat
+	notFoundHandler  HandlerFunc
+	trustedProxies   []*net.IPNet
+	injected         []*injectedHandler
+	injectErrors     []error
+	maintenance      atomic.Pointer[MaintenanceOptions]
+	flags            *FeatureFlags
+	contextExtractor func(ctx *Context) context.Context
+	events           *eventDispatcher
+	starting         atomic.Bool
+	draining         atomic.Bool
+	readinessPaths   map[string]bool
+	buildInfo        atomic.Pointer[BuildInfo]
+
+	// Running state, set by Serve
+	runMu sync.Mutex
+	run   *runState
+	addr  net.Addr
+}
+
+// runState lets Shutdown stop a running application
+type runState struct {
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 type Config struct {
@@ -30,27 +77,122 @@ type Config struct {
 		Name    string `toml:"name"`
 		Version string `toml:"version"`
 		Debug   bool   `toml:"debug"`
+		// CrashOnPanic lets panics in goroutines started with Go crash the
+		// process instead of being recovered
+		CrashOnPanic bool `toml:"crash_on_panic"`
+
+		// Request lifecycle events are published by EventWorkers goroutines
+		// from a buffer of EventBufferSize events; when it is full, events
+		// are dropped. Default 1024 events and 4 workers.
+		EventBufferSize int `toml:"event_buffer_size"`
+		EventWorkers    int `toml:"event_workers"`
+
+		// StrictResponseSchema replaces responses that don't match their
+		// route's ResponseSchema with a 500 error instead of only logging.
+		// Response schemas are only checked in debug mode.
+		StrictResponseSchema bool `toml:"strict_response_schema"`
+
+		// StrictServices makes registering a service name twice an error,
+		// including two plugins registering the same name, which otherwise
+		// overwrites the first service with a warning
+		StrictServices bool `toml:"strict_services"`
 	} `toml:"app"`
 
 	Server struct {
-		Host string `toml:"host"`
-		Port int    `toml:"port"`
+		Host              string `toml:"host"`
+		Port              int    `toml:"port"`
+		StreamRequestBody bool   `toml:"stream_request_body"`
+		// MaxRequestBodySize in bytes, defaults to 4 MB
+		MaxRequestBodySize int `toml:"max_request_body_size"`
+
+		// Slow client protection. FastHTTP cannot enforce a minimum data
+		// rate, so these deadlines bound how long a client may trickle a
+		// request (read), a response (write) or hold an idle keep-alive connection.
+		// Durations are Go duration strings, e.g. "10s". Zero means unlimited.
+		ReadTimeout  time.Duration `toml:"read_timeout"`
+		WriteTimeout time.Duration `toml:"write_timeout"`
+		IdleTimeout  time.Duration `toml:"idle_timeout"`
+
+		// MaxConnsPerIP limits concurrent connections from a single client
+		// IP, zero means unlimited. New connections over the limit are
+		// rejected with 429. Behind a load balancer or proxy all clients
+		// share the proxy's IP, so leave this unset or size it for the
+		// proxy's connection pool.
+		MaxConnsPerIP int `toml:"max_conns_per_ip"`
+
+		// MaxHeaderSize bounds the request line and headers in bytes, larger
+		// requests are rejected with 431. Defaults to 4 KB.
+		MaxHeaderSize int `toml:"max_header_size"`
+		// MaxURLLength bounds the request URI in bytes, longer URIs are
+		// rejected with 414. Zero leaves only the MaxHeaderSize limit.
+		MaxURLLength int `toml:"max_url_length"`
+
+		// TLS serves HTTPS when both files are set. With TLSClientCAFile
+		// clients may present a certificate signed by one of its CAs,
+		// which MTLSMiddleware requires.
+		TLSCertFile     string `toml:"tls_cert_file"`
+		TLSKeyFile      string `toml:"tls_key_file"`
+		TLSClientCAFile string `toml:"tls_client_ca_file"`
+
+		// TrustedProxies lists the IPs and CIDR ranges of proxies whose
+		// X-Forwarded-* headers are honored by ctx.ExternalBaseURL and
+		// related helpers
+		TrustedProxies []string `toml:"trusted_proxies"`
+
+		// DrainSignal starts draining (see SetDraining), e.g. "SIGUSR2".
+		// Defaults to SIGUSR1; "none" disables it.
+		DrainSignal string `toml:"drain_signal"`
+
+		// DisablePathNormalizing routes on the path as sent, keeping
+		// duplicate slashes that are otherwise collapsed, so a wildcard
+		// can capture values like "http://example.com". Paths with "."
+		// or ".." segments are then rejected with 400.
+		DisablePathNormalizing bool `toml:"disable_path_normalizing"`
+
+		// ShutdownTimeout bounds the whole shutdown: app.stopping handlers,
+		// stopping plugins and waiting for in-flight requests. Connections
+		// still open then are closed. Defaults to 30s.
+		ShutdownTimeout time.Duration `toml:"shutdown_timeout"`
 	} `toml:"server"`
 
 	Plugins map[string]map[string]interface{} `toml:"plugins"`
+
+	// Flags are the initial feature flag definitions
+	Flags map[string]Flag `toml:"flags"`
 }
 
+// DefaultShutdownTimeout bounds the shutdown unless server.shutdown_timeout
+// is set
+const DefaultShutdownTimeout = 30 * time.Second
+
+// configPath is the application config file
+const configPath = "config/app.toml"
+
 func New() *Application {
 	app := &Application{
 		container:       container.NewContainer(),
 		config:          Config{},
 		router:          NewRouter(),
 		middlewareChain: NewMiddlewareChain(),
+		preMiddleware:   NewMiddlewareChain(),
+		versioning:      newVersioning(),
+		flags:           NewFeatureFlags(),
 	}
 
 	app.pluginManager = NewPluginManager(app.container)
+	app.container.Register(EventBusService, app.pluginManager.GetEventBus())
+	app.container.Register(MetricsService, NoopMetrics{})
+	app.container.Register(RouterService, app.router)
+	app.container.Register(FlagsService, app.flags)
 
 	app.loadConfig()
+	app.container.SetStrict(app.config.App.StrictServices)
+	app.loadTrustedProxies()
+	app.router.SetPathNormalization(!app.config.Server.DisablePathNormalizing)
+	app.flags.Load(app.config.Flags)
+	app.SetBuildInfo(ReadBuildInfo(app.config.App.Version))
+	setGoroutineEventBus(app.pluginManager.GetEventBus())
+	SetCrashOnPanic(app.config.App.CrashOnPanic)
 	app.setupDefaultMiddleware()
 	app.printBanner()
 
@@ -66,8 +208,8 @@ func (a *Application) loadConfig() {
 	a.config.Server.Port = 3000
 
 	// TODO: Add custom config path
-	if _, err := os.Stat("config/app.toml"); err == nil {
-		if _, err := toml.DecodeFile("config/app.toml", &a.config); err != nil {
+	if _, err := os.Stat(configPath); err == nil {
+		if _, err := toml.DecodeFile(configPath, &a.config); err != nil {
 			log.Printf("Warning: failed to load config/app.toml: %v", err)
 		}
 	}
@@ -94,7 +236,17 @@ func (a *Application) printBanner() {
 %s v%s
 Powered by Gorgo Framework
 `
-	fmt.Printf(banner, a.config.App.Name, a.config.App.Version)
+	info := a.BuildInfo()
+	fmt.Printf(banner, a.config.App.Name, info.Version)
+	if info.Commit != "" {
+		fmt.Printf("Commit %s, built %s with %s\n", info.Commit, info.BuildTime, info.GoVersion)
+	}
+
+	if a.config.App.Debug {
+		for name, config := range a.config.Redacted().Plugins {
+			fmt.Printf("Plugin %s config: %v\n", name, config)
+		}
+	}
 }
 
 // Methods for working with plugins
@@ -109,6 +261,12 @@ func (a *Application) GetPlugin(name string) (Plugin, bool) {
 	return a.pluginManager.GetPlugin(name)
 }
 
+// FindPluginsByTag returns the enabled plugins tagged with tag, e.g.
+// app.FindPluginsByTag("cache"), in load order
+func (a *Application) FindPluginsByTag(tag string) []Plugin {
+	return a.pluginManager.FindPluginsByTag(tag)
+}
+
 func (a *Application) GetEventBus() *EventBus {
 	return a.pluginManager.GetEventBus()
 }
@@ -117,7 +275,27 @@ func (a *Application) HotReloadPlugin(name string, newConfig map[string]interfac
 	return a.pluginManager.HotReloadPlugin(name, newConfig)
 }
 
+// ConfigureServer registers a function that can tune the underlying
+// fasthttp.Server (Concurrency, MaxRequestsPerConn, ReduceMemoryUsage, ...).
+// Configurators run in registration order right before the server starts,
+// after the settings from the config file have been applied. The Handler
+// is always reset to the application's handler afterwards.
+func (a *Application) ConfigureServer(configure func(s *fasthttp.Server)) *Application {
+	a.serverOptions = append(a.serverOptions, configure)
+	return a
+}
+
 // Methods for working with middleware
+
+// Pre adds middleware that runs before routing, so it may rewrite the
+// request method or path used to resolve the route
+func (a *Application) Pre(middlewares ...MiddlewareFunc) *Application {
+	for _, middleware := range middlewares {
+		a.preMiddleware.Add(middleware)
+	}
+	return a
+}
+
 func (a *Application) Use(middleware MiddlewareFunc) *Application {
 	a.middlewareChain.Add(middleware)
 	return a
@@ -155,9 +333,122 @@ func (a *Application) EnableAuth(authFunc func(ctx *Context) (interface{}, error
 	return a
 }
 
+// SetRequestContextExtractor sets a function that builds the Go context of
+// each request, e.g. from traceparent, baggage or tenant headers. It runs
+// before maintenance checks and all middleware; ctx.Context() returns its
+// result, starting from a context canceled on server shutdown.
+//
+//	app.SetRequestContextExtractor(func(ctx *gorgo.Context) context.Context {
+//	    return context.WithValue(ctx.Context(), tenantKey{}, ctx.GetHeader("X-Tenant-ID"))
+//	})
+func (a *Application) SetRequestContextExtractor(extractor func(ctx *Context) context.Context) *Application {
+	a.contextExtractor = extractor
+	return a
+}
+
+// ErrorHandler sets the handler that writes error responses. It receives
+// errors returned by handlers and middleware (status preset to 500),
+// panics recovered by RecoveryMiddleware and errors the framework reports
+// to the client, such as BindJSONValidated failures, which replace the
+// default ErrorResponse JSON. Panics and framework errors are passed as
+// *HTTPError carrying the suggested status and code; use errors.As to
+// inspect them. The handler runs before the response is sent, so it can set
+// the status, headers and body. If it panics, the plain-text 500 is sent.
+func (a *Application) ErrorHandler(handler func(ctx *Context, err error)) *Application {
+	a.errorHandler = handler
+	return a
+}
+
+// OnError adds a hook that runs when a handler or middleware returns an
+// error or panics, e.g. to report errors or count them. Hooks run in the
+// order added, before the error handler writes the response, so they can
+// add headers or context values for it. Recovered panics are passed as an
+// *HTTPError wrapping the panic value. Add hooks before serving requests.
+func (a *Application) OnError(hook func(ctx *Context, err error)) *Application {
+	a.errorHooks = append(a.errorHooks, hook)
+	return a
+}
+
+// NotFound sets the handler for requests that match no route. It runs
+// through the global middleware chain like a route handler, so recovery,
+// logging and CORS headers also apply to misses. The default handler
+// responds 404 "Not Found".
+//
+//	app.NotFound(func(ctx *gorgo.Context) error {
+//	    return ctx.Error(gorgo.NotFoundStatus, "not_found", "No such endpoint")
+//	})
+func (a *Application) NotFound(handler HandlerFunc) *Application {
+	a.notFoundHandler = handler
+	return a
+}
+
+func defaultNotFoundHandler(ctx *Context) error {
+	ctx.fastCtx.SetStatusCode(NotFoundStatus)
+	ctx.fastCtx.SetBodyString("Not Found")
+	return nil
+}
+
 func (a *Application) Run() error {
+	return a.RunContext(context.Background())
+}
+
+// RunContext runs the application until the process receives SIGINT/SIGTERM,
+// the given context is canceled or Shutdown is called, whichever happens
+// first. The context is passed to plugins on Start and (without its
+// cancellation) on Stop.
+func (a *Application) RunContext(ctx context.Context) error {
+	tlsConfig, err := a.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	addr := fmt.Sprintf("%s:%d", a.config.Server.Host, a.config.Server.Port)
+
+	// Bind synchronously so that failures are returned to the caller
+	// before any plugin is started
+	ln, err := net.Listen("tcp4", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+
+	return a.Serve(ctx, ln)
+}
+
+// Serve runs the application like RunContext on an existing listener,
+// e.g. one bound to an ephemeral port in tests. The listener is closed
+// when the application stops. TLS settings from the config file are not
+// applied; wrap the listener with tls.NewListener instead.
+func (a *Application) Serve(ctx context.Context, ln net.Listener) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	run := &runState{cancel: cancel, done: make(chan struct{})}
+	a.runMu.Lock()
+	if a.run != nil {
+		a.runMu.Unlock()
+		ln.Close()
+		return errors.New("application is already running")
+	}
+	a.run = run
+	a.addr = ln.Addr()
+	a.runMu.Unlock()
+
+	defer func() {
+		a.runMu.Lock()
+		a.run = nil
+		a.addr = nil
+		a.runMu.Unlock()
+		close(run.done)
+	}()
+
+	a.applyPluginDefaults()
+
 	// Initialize plugins
 	if err := a.pluginManager.InitializePlugins(a.config.Plugins); err != nil {
+		ln.Close()
 		return fmt.Errorf("failed to initialize plugins: %v", err)
 	}
 
@@ -167,64 +458,238 @@ func (a *Application) Run() error {
 		a.middlewareChain.Add(middleware)
 	}
 
+	// Add routes from plugins
+	for _, route := range a.pluginManager.GetRoutes() {
+		a.addRoute(route)
+	}
+
+	// Plugin services are registered now, so every dependency must resolve
+	if err := a.resolveInjected(); err != nil {
+		ln.Close()
+		return fmt.Errorf("failed to resolve handler dependencies: %w", err)
+	}
+
+	tracker := newConnTracker(a.metrics())
+	a.connTracker.Store(tracker)
+	a.events = newEventDispatcher(a.pluginManager.GetEventBus(), a.metrics, a.config.App.EventBufferSize, a.config.App.EventWorkers)
+
+	a.server = &fasthttp.Server{
+		Handler:           a.handleRequest,
+		StreamRequestBody: a.config.Server.StreamRequestBody,
+		// FastHTTP would otherwise read multipart bodies of known length
+		// up front, bypassing MultipartReader and the body size limit
+		DisablePreParseMultipartForm: a.config.Server.StreamRequestBody,
+		MaxRequestBodySize:           a.maxRequestBodySize(),
+		ReadTimeout:                  a.config.Server.ReadTimeout,
+		WriteTimeout:                 a.config.Server.WriteTimeout,
+		IdleTimeout:                  a.config.Server.IdleTimeout,
+		MaxConnsPerIP:                a.config.Server.MaxConnsPerIP,
+		ReadBufferSize:               a.config.Server.MaxHeaderSize,
+		ConnState:                    tracker.connState,
+	}
+
+	for _, configure := range a.serverOptions {
+		configure(a.server)
+	}
+	a.server.Handler = a.handleRequest
+
+	addr := ln.Addr().String()
+	log.Printf("Server starting on %s", addr)
+
+	// Serve while plugins start so readiness probes can report progress;
+	// other requests get 503 until startup is complete
+	a.starting.Store(true)
+	serverErr := make(chan error, 1)
+	Go(func() {
+		// Report a recovered panic as a server failure so Run returns
+		err := errors.New("server stopped unexpectedly")
+		defer func() { serverErr <- err }()
+		err = a.server.Serve(ln)
+	})
+
 	// Start plugins
-	ctx := context.Background()
 	if err := a.pluginManager.StartPlugins(ctx); err != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.shutdownTimeout())
+		defer cancel()
+		if errors.Is(a.server.ShutdownWithContext(shutdownCtx), context.DeadlineExceeded) {
+			tracker.closeAll()
+		}
+		a.events.close(shutdownCtx)
+		// Plugins started before the failing one must release their resources
+		if stopErr := a.pluginManager.StopPlugins(shutdownCtx); stopErr != nil {
+			log.Printf("Error stopping plugins: %v", stopErr)
+		}
+		a.starting.Store(false)
 		return fmt.Errorf("failed to start plugins: %v", err)
 	}
+	a.starting.Store(false)
 
 	// Publish application starting event
 	a.pluginManager.GetEventBus().Publish(ctx, "app.starting", map[string]interface{}{
-		"config": a.config,
+		"config": a.config.Redacted(),
 	})
 
-	a.server = &fasthttp.Server{
-		Handler: a.handleRequest,
+	// Publish server started event
+	a.pluginManager.GetEventBus().Publish(ctx, "server.started", map[string]interface{}{
+		"address": addr,
+	})
+
+	return a.waitForShutdown(ctx, serverErr)
+}
+
+// Shutdown stops a running application like a SIGTERM would: plugins are
+// stopped, in-flight requests finish and Run returns. It waits until the
+// application has stopped or ctx is done.
+func (a *Application) Shutdown(ctx context.Context) error {
+	a.runMu.Lock()
+	run := a.run
+	a.runMu.Unlock()
+
+	if run == nil {
+		return errors.New("application is not running")
 	}
 
-	go func() {
-		addr := fmt.Sprintf("%s:%d", a.config.Server.Host, a.config.Server.Port)
-		log.Printf("Server starting on %s", addr)
+	run.cancel()
+	select {
+	case <-run.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
 
-		// Publish server started event
-		a.pluginManager.GetEventBus().Publish(ctx, "server.started", map[string]interface{}{
-			"address": addr,
-		})
+// Addr returns the address the server listens on, nil when it is not
+// running. With port 0 it contains the port chosen by the system.
+func (a *Application) Addr() net.Addr {
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+	return a.addr
+}
 
-		if err := a.server.ListenAndServe(addr); err != nil {
-			log.Fatalf("Server failed to start: %v", err)
+func (a *Application) maxRequestBodySize() int {
+	if a.config.Server.MaxRequestBodySize > 0 {
+		return a.config.Server.MaxRequestBodySize
+	}
+	return fasthttp.DefaultMaxRequestBodySize
+}
+
+// applyPluginDefaults fills config.Plugins with the default configuration
+// of every registered ConfigurablePlugin for keys missing from the config file
+func (a *Application) applyPluginDefaults() {
+	if a.config.Plugins == nil {
+		a.config.Plugins = make(map[string]map[string]interface{})
+	}
+
+	for _, plugin := range a.pluginManager.getSortedPlugins() {
+		configurable, ok := plugin.(ConfigurablePlugin)
+		if !ok {
+			continue
 		}
-	}()
 
-	a.waitForShutdown()
+		name := plugin.GetMetadata().Name
+		config := a.config.Plugins[name]
+		if config == nil {
+			config = make(map[string]interface{})
+			a.config.Plugins[name] = config
+		}
 
-	return nil
+		var applied []string
+		for key, value := range configurable.GetDefaultConfig() {
+			if _, exists := config[key]; !exists {
+				config[key] = value
+				applied = append(applied, key)
+			}
+		}
+
+		if len(applied) > 0 {
+			sort.Strings(applied)
+			log.Printf("Plugin %s: applied default config for %s", name, strings.Join(applied, ", "))
+		}
+	}
 }
 
 func (a *Application) handleRequest(ctx *fasthttp.RequestCtx) {
-	gorgoCtx := NewContext(ctx, a.container, a.pluginManager.plugins)
+	gorgoCtx := acquireContext(ctx, a.container, a.pluginManager.plugins)
+	defer releaseContext(gorgoCtx)
+	gorgoCtx.maxBodySize = a.maxRequestBodySize()
+	gorgoCtx.errorHandler = a.errorHandler
+	gorgoCtx.errorHooks = a.errorHooks
+	gorgoCtx.errorFormat = a.errorFormat
+	gorgoCtx.trustedProxies = a.trustedProxies
+	gorgoCtx.goCtx = ctx
+	if a.contextExtractor != nil {
+		if extracted := a.contextExtractor(gorgoCtx); extracted != nil {
+			gorgoCtx.goCtx = extracted
+		}
+	}
 
-	method := string(ctx.Method())
-	path := string(ctx.Path())
+	if limit := a.config.Server.MaxURLLength; limit > 0 && len(ctx.RequestURI()) > limit {
+		gorgoCtx.Error(URITooLongStatus, "uri_too_long", fmt.Sprintf("Request URI exceeds %d bytes", limit))
+		return
+	}
+
+	// Without normalization dot segments would reach routes and middleware
+	// unresolved, so traversal attempts are rejected instead
+	if a.config.Server.DisablePathNormalizing && hasDotSegment(a.routingPath(ctx)) {
+		gorgoCtx.Error(BadRequestStatus, "invalid_path", "Request path must not contain . or .. segments")
+		return
+	}
+
+	// Move keep-alive clients to other instances while draining
+	if a.draining.Load() {
+		ctx.SetConnectionClose()
+	}
+
+	if a.rejectWhileStarting(gorgoCtx) || a.rejectForMaintenance(gorgoCtx) {
+		return
+	}
 
 	// Publish incoming request event
-	a.pluginManager.GetEventBus().Publish(context.Background(), "request.incoming", map[string]interface{}{
-		"method": method,
-		"path":   path,
-		"ip":     gorgoCtx.ClientIP(),
+	a.publishRequestEvent(RequestIncoming{
+		Method: string(ctx.Method()),
+		Path:   string(ctx.Path()),
+		IP:     gorgoCtx.ClientIP(),
 	})
 
-	handler, params := a.router.FindHandler(method, path)
-	if handler == nil {
-		ctx.SetStatusCode(404)
-		ctx.SetBodyString("Not Found")
+	// Pre-routing middleware runs before the route is resolved
+	if err := a.preMiddleware.Execute(a.dispatch)(gorgoCtx); err != nil {
+		a.handleError(gorgoCtx, err)
+	}
+}
 
-		// Publish 404 event
-		a.pluginManager.GetEventBus().Publish(context.Background(), "request.not_found", map[string]interface{}{
-			"method": method,
-			"path":   path,
-		})
-		return
+// routingPath returns the decoded request path. FastHTTP always cleans the
+// path, collapsing duplicate slashes and resolving dot segments, so with
+// normalization disabled the original path is decoded instead.
+func (a *Application) routingPath(ctx *fasthttp.RequestCtx) string {
+	if !a.config.Server.DisablePathNormalizing {
+		return string(ctx.Path())
+	}
+	original := string(ctx.URI().PathOriginal())
+	if decoded, err := url.PathUnescape(original); err == nil {
+		return decoded
+	}
+	return original
+}
+
+// dispatch resolves the route and runs the middleware chain and handler
+func (a *Application) dispatch(gorgoCtx *Context) error {
+	ctx := gorgoCtx.fastCtx
+	method := string(ctx.Method())
+	path := a.routingPath(ctx)
+
+	version, routePath := a.versioning.resolve(path, gorgoCtx.GetHeader("Accept"))
+	handler, params, pattern := a.router.findRoute(method, routePath)
+	if handler == nil && routePath != path {
+		// Routes outside the version groups keep matching when a version
+		// was negotiated through the Accept header or the default
+		version = ""
+		handler, params, pattern = a.router.findRoute(method, path)
+	}
+	gorgoCtx.apiVersion = version
+	gorgoCtx.route = pattern
+	notFound := handler == nil
+	if notFound {
+		handler = a.notFound
 	}
 
 	// Set URL parameters in context
@@ -234,77 +699,248 @@ func (a *Application) handleRequest(ctx *fasthttp.RequestCtx) {
 
 	// Apply middleware chain
 	finalHandler := a.middlewareChain.Execute(handler)
+	err := finalHandler(gorgoCtx)
 
-	if err := finalHandler(gorgoCtx); err != nil {
-		log.Printf("Handler error: %v", err)
-		ctx.SetStatusCode(500)
-		ctx.SetBodyString("Internal Server Error")
-
-		// Publish error event
-		a.pluginManager.GetEventBus().Publish(context.Background(), "request.error", map[string]interface{}{
-			"method": method,
-			"path":   path,
-			"error":  err.Error(),
+	if notFound {
+		// Publish 404 event
+		a.publishRequestEvent(RequestNotFound{
+			Method: method,
+			Path:   path,
 		})
-		return
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if gorgoCtx.StatusCode() == ForbiddenStatus {
+		gorgoCtx.audit(gorgoCtx.ClientIP(), "access", AuditForbidden, "")
 	}
 
 	// Publish successful request event
-	a.pluginManager.GetEventBus().Publish(context.Background(), "request.completed", map[string]interface{}{
-		"method": method,
-		"path":   path,
-		"status": ctx.Response.StatusCode(),
+	a.publishRequestEvent(RequestCompleted{
+		Method: method,
+		Path:   path,
+		Status: gorgoCtx.StatusCode(),
+		Size:   gorgoCtx.ResponseSize(),
+	})
+	return nil
+}
+
+func (a *Application) handleError(gorgoCtx *Context, err error) {
+	ctx := gorgoCtx.fastCtx
+
+	log.Printf("Handler error: %v", err)
+	ctx.SetStatusCode(500)
+	gorgoCtx.runErrorHooks(err)
+	if !gorgoCtx.runErrorHandler(err) {
+		gorgoCtx.writeUnhandledError(err)
+	}
+
+	// Publish error event
+	a.publishRequestEvent(RequestError{
+		Method: string(ctx.Method()),
+		Path:   string(ctx.Path()),
+		Error:  err.Error(),
 	})
 }
 
-func (a *Application) waitForShutdown() {
+func (a *Application) waitForShutdown(runCtx context.Context, serverErr <-chan error) error {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	defer signal.Stop(quit)
+
+	drain := make(chan os.Signal, 1)
+	if drainSignal, err := a.drainSignal(); err != nil {
+		log.Printf("Warning: draining on a signal is disabled: %v", err)
+	} else if drainSignal != nil {
+		signal.Notify(drain, drainSignal)
+		defer signal.Stop(drain)
+	}
+
+	var err error
+wait:
+	for {
+		select {
+		case <-drain:
+			a.SetDraining(true)
+		case <-quit:
+			break wait
+		case <-runCtx.Done():
+			break wait
+		case err = <-serverErr:
+			if err != nil {
+				log.Printf("Server failed: %v", err)
+				err = fmt.Errorf("server failed: %w", err)
+			}
+			break wait
+		}
+	}
 
 	log.Println("Shutting down server...")
 
-	ctx := context.Background()
+	// Keep context values for plugins but drop the cancellation that
+	// may have triggered the shutdown
+	timeout := a.shutdownTimeout()
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(runCtx), timeout)
+	defer cancel()
+
+	a.stopPlugins(ctx)
 
-	// Publish application stopping event
-	a.pluginManager.GetEventBus().Publish(ctx, "app.stopping", map[string]interface{}{})
+	if shutdownErr := a.server.ShutdownWithContext(ctx); shutdownErr != nil {
+		if errors.Is(shutdownErr, context.DeadlineExceeded) {
+			closed := a.connTracker.Load().closeAll()
+			log.Printf("Shutdown did not finish within %v, closed %d open connections", timeout, closed)
+		} else {
+			log.Printf("Error shutting down server: %v", shutdownErr)
+		}
+	}
+
+	log.Println("Server stopped")
+	return err
+}
+
+// publishRequestEvent publishes a request lifecycle event without waiting
+// for subscribers once the server runs
+func (a *Application) publishRequestEvent(event TypedEvent) {
+	if a.events == nil {
+		a.pluginManager.GetEventBus().publish(context.Background(), event.EventName(), eventData(event), event)
+		return
+	}
+	a.events.dispatchTyped(context.Background(), event)
+}
+
+// shutdownTimeout returns server.shutdown_timeout or the default
+func (a *Application) shutdownTimeout() time.Duration {
+	if a.config.Server.ShutdownTimeout > 0 {
+		return a.config.Server.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
+}
+
+// stopPlugins publishes the app.stopping event and stops all plugins
+func (a *Application) stopPlugins(ctx context.Context) {
+	// Deliver queued request events while subscribers are still running
+	if a.events != nil {
+		drainCtx, cancel := context.WithTimeout(ctx, DefaultPluginTimeout)
+		a.events.close(drainCtx)
+		cancel()
+	}
+
+	// Publish application stopping event. Its handlers get ctx, which is
+	// canceled after the shutdown timeout, so a slow one can't hold up the
+	// shutdown.
+	if err := a.pluginManager.GetEventBus().publishBounded(ctx, "app.stopping", map[string]interface{}{}); err != nil {
+		log.Printf("Error in app.stopping handlers: %v", err)
+	}
 
 	// Stop plugins
 	if err := a.pluginManager.StopPlugins(ctx); err != nil {
 		log.Printf("Error stopping plugins: %v", err)
 	}
+}
 
-	if err := a.server.ShutdownWithContext(ctx); err != nil {
-		log.Printf("Error shutting down server: %v", err)
-	}
-
-	log.Println("Server stopped")
+// ListRoutes returns all registered routes sorted by path and method
+func (a *Application) ListRoutes() []Route {
+	return a.router.Routes()
 }
 
 // HTTP methods with route-level middleware support
 func (a *Application) Get(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	finalHandler := a.applyRouteMiddleware(handler, middleware...)
-	a.router.AddRoute("GET", path, finalHandler)
+	a.addRoute(Route{Method: "GET", Path: path, Handler: handler, Middleware: middleware})
 }
 
 func (a *Application) Post(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	finalHandler := a.applyRouteMiddleware(handler, middleware...)
-	a.router.AddRoute("POST", path, finalHandler)
+	a.addRoute(Route{Method: "POST", Path: path, Handler: handler, Middleware: middleware})
 }
 
 func (a *Application) Put(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	finalHandler := a.applyRouteMiddleware(handler, middleware...)
-	a.router.AddRoute("PUT", path, finalHandler)
+	a.addRoute(Route{Method: "PUT", Path: path, Handler: handler, Middleware: middleware})
 }
 
 func (a *Application) Delete(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	finalHandler := a.applyRouteMiddleware(handler, middleware...)
-	a.router.AddRoute("DELETE", path, finalHandler)
+	a.addRoute(Route{Method: "DELETE", Path: path, Handler: handler, Middleware: middleware})
 }
 
 func (a *Application) Patch(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	finalHandler := a.applyRouteMiddleware(handler, middleware...)
-	a.router.AddRoute("PATCH", path, finalHandler)
+	a.addRoute(Route{Method: "PATCH", Path: path, Handler: handler, Middleware: middleware})
+}
+
+// routeMethods are the methods accepted by Routes
+var routeMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true,
+	"PATCH": true, "HEAD": true, "OPTIONS": true,
+}
+
+// Routes registers a batch of routes, e.g. a route table built from
+// configuration or generated code. All routes are validated first; on any
+// invalid or duplicate route or name nothing is registered and the errors
+// are returned joined together.
+func (a *Application) Routes(routes []Route) error {
+	var errs []error
+	keys := make(map[string]bool)
+	names := make(map[string]bool)
+
+	for i, route := range routes {
+		method := strings.ToUpper(route.Method)
+		key := method + " " + route.Path
+
+		switch {
+		case !routeMethods[method]:
+			errs = append(errs, fmt.Errorf("route %d (%s): unsupported method %q", i, key, route.Method))
+		case !strings.HasPrefix(route.Path, "/"):
+			errs = append(errs, fmt.Errorf("route %d (%s): path must start with '/'", i, key))
+		case validateRoutePath(route.Path) != nil:
+			errs = append(errs, fmt.Errorf("route %d (%s): %v", i, key, validateRoutePath(route.Path)))
+		case route.Handler == nil:
+			errs = append(errs, fmt.Errorf("route %d (%s): handler is nil", i, key))
+		case keys[key] || a.router.HasRoute(method, route.Path):
+			errs = append(errs, fmt.Errorf("route %d (%s): duplicate route", i, key))
+		}
+		keys[key] = true
+
+		if route.Name != "" {
+			if names[route.Name] || a.router.hasName(route.Name) {
+				errs = append(errs, fmt.Errorf("route %d (%s): duplicate route name %q", i, key, route.Name))
+			}
+			names[route.Name] = true
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, route := range routes {
+		a.addRoute(route)
+	}
+	return nil
+}
+
+func (a *Application) addRoute(route Route) {
+	method := strings.ToUpper(route.Method)
+	middleware := route.Middleware
+	if route.ResponseSchema != nil {
+		middleware = append([]MiddlewareFunc{a.ResponseSchema(route.ResponseSchema)}, middleware...)
+	}
+	a.router.AddRoute(method, route.Path, a.applyRouteMiddleware(route.Handler, middleware...))
+	if route.Name != "" {
+		a.router.setName(method, route.Path, route.Name)
+	}
+	if method != "OPTIONS" {
+		a.router.setPreflight(route.Path, a.preflightHandler(route.Path), false)
+	}
+}
+
+// preflightHandler answers OPTIONS requests for a path without an explicit
+// OPTIONS route with 204 and the allowed methods
+func (a *Application) preflightHandler(path string) HandlerFunc {
+	return func(ctx *Context) error {
+		ctx.Header("Allow", strings.Join(a.router.allowedMethods(path), ", "))
+		ctx.fastCtx.SetStatusCode(NoContentStatus)
+		return nil
+	}
 }
 
 func (a *Application) applyRouteMiddleware(handler HandlerFunc, middleware ...MiddlewareFunc) HandlerFunc {
@@ -331,32 +967,42 @@ func (a *Application) Group(prefix string, middleware ...MiddlewareFunc) *RouteG
 	}
 }
 
-func (rg *RouteGroup) Get(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+// withMiddleware returns the group middleware followed by middleware.
+// It always copies so routes never share the group's backing array.
+func (rg *RouteGroup) withMiddleware(middleware []MiddlewareFunc) []MiddlewareFunc {
+	return Stack(rg.middleware).With(middleware...)
+}
+
+// handle registers a route of the group. Automatic OPTIONS requests for
+// the route's path run through the group middleware only, so a CORS
+// middleware of the group answers preflights while route middleware such
+// as authentication does not reject them.
+func (rg *RouteGroup) handle(method, path string, handler HandlerFunc, middleware []MiddlewareFunc) {
 	fullPath := rg.prefix + path
-	allMiddleware := append(rg.middleware, middleware...)
-	rg.app.Get(fullPath, handler, allMiddleware...)
+	rg.app.addRoute(Route{Method: method, Path: fullPath, Handler: handler, Middleware: rg.withMiddleware(middleware)})
+
+	if len(rg.middleware) > 0 {
+		preflight := rg.app.applyRouteMiddleware(rg.app.preflightHandler(fullPath), rg.middleware...)
+		rg.app.router.setPreflight(fullPath, preflight, true)
+	}
+}
+
+func (rg *RouteGroup) Get(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+	rg.handle("GET", path, handler, middleware)
 }
 
 func (rg *RouteGroup) Post(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	fullPath := rg.prefix + path
-	allMiddleware := append(rg.middleware, middleware...)
-	rg.app.Post(fullPath, handler, allMiddleware...)
+	rg.handle("POST", path, handler, middleware)
 }
 
 func (rg *RouteGroup) Put(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	fullPath := rg.prefix + path
-	allMiddleware := append(rg.middleware, middleware...)
-	rg.app.Put(fullPath, handler, allMiddleware...)
+	rg.handle("PUT", path, handler, middleware)
 }
 
 func (rg *RouteGroup) Delete(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	fullPath := rg.prefix + path
-	allMiddleware := append(rg.middleware, middleware...)
-	rg.app.Delete(fullPath, handler, allMiddleware...)
+	rg.handle("DELETE", path, handler, middleware)
 }
 
 func (rg *RouteGroup) Patch(path string, handler HandlerFunc, middleware ...MiddlewareFunc) {
-	fullPath := rg.prefix + path
-	allMiddleware := append(rg.middleware, middleware...)
-	rg.app.Patch(fullPath, handler, allMiddleware...)
+	rg.handle("PATCH", path, handler, middleware)
 }
@@ -0,0 +1,79 @@
+package gorgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AuditEventName is the EventBus event name used for audit events
+const AuditEventName = "audit"
+
+// Audit outcomes
+const (
+	AuditSuccess   = "success"
+	AuditFailure   = "failure"
+	AuditDenied    = "denied"
+	AuditForbidden = "forbidden"
+)
+
+// AuditEvent describes a security-relevant action
+type AuditEvent struct {
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Resource  string    `json:"resource"`
+	Outcome   string    `json:"outcome"`
+	Timestamp time.Time `json:"timestamp"`
+	IP        string    `json:"ip"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// PublishAudit publishes an audit event on the bus. The event is stored
+// under the "audit" key of the event data.
+func PublishAudit(ctx context.Context, eventBus *EventBus, audit AuditEvent) error {
+	if audit.Timestamp.IsZero() {
+		audit.Timestamp = time.Now()
+	}
+	return eventBus.Publish(ctx, AuditEventName, map[string]interface{}{
+		"audit": audit,
+	})
+}
+
+// SubscribeAudit subscribes handler to audit events, e.g. to persist them
+func SubscribeAudit(eventBus *EventBus, handler func(audit AuditEvent) error) SubscriptionID {
+	return eventBus.Subscribe(AuditEventName, func(event *Event) error {
+		if audit, ok := event.Data["audit"].(AuditEvent); ok {
+			return handler(audit)
+		}
+		return nil
+	})
+}
+
+// audit publishes an audit event for the current request
+func (c *Context) audit(actor, action, outcome, reason string) {
+	eventBus := c.eventBus()
+	if eventBus == nil {
+		return
+	}
+
+	PublishAudit(context.Background(), eventBus, AuditEvent{
+		Actor:    actor,
+		Action:   action,
+		Resource: c.Method() + " " + c.Path(),
+		Outcome:  outcome,
+		IP:       c.ClientIP(),
+		Reason:   reason,
+	})
+}
+
+// auditActor returns a printable identity for an authenticated user
+func auditActor(user interface{}) string {
+	switch u := user.(type) {
+	case string:
+		return u
+	case fmt.Stringer:
+		return u.String()
+	default:
+		return "authenticated"
+	}
+}
@@ -0,0 +1,156 @@
+package gorgo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Bind decodes the request body into v according to its Content-Type:
+// JSON (application/json, */*+json), XML (application/xml, text/xml,
+// */*+xml) or forms (application/x-www-form-urlencoded,
+// multipart/form-data). Other types return an error wrapping
+// ErrUnsupportedMediaType:
+//
+//	if err := ctx.Bind(&req); errors.Is(err, gorgo.ErrUnsupportedMediaType) {
+//	    return ctx.Error(415, "unsupported_media_type", err.Error())
+//	}
+func (c *Context) Bind(v interface{}) error {
+	contentType := string(c.fastCtx.Request.Header.ContentType())
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrUnsupportedMediaType, contentType)
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return c.BindJSON(v)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return c.BindXML(v)
+	case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+		return c.BindForm(v)
+	}
+	return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+}
+
+// BindXML decodes the XML body into v
+func (c *Context) BindXML(v interface{}) error {
+	body, err := c.readBody()
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(body, v)
+}
+
+// BindForm fills the struct v points to from form values, using the
+// `form:"name"` tag of each field or its name without a tag. Strings,
+// booleans, numbers, pointers to them and slices of them are supported;
+// absent values leave the field unchanged.
+//
+//	type SignupForm struct {
+//	    Email string   `form:"email"`
+//	    Age   int      `form:"age"`
+//	    Tags  []string `form:"tag"`
+//	}
+func (c *Context) BindForm(v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("BindForm: expected a pointer to a struct, got %T", v)
+	}
+	value = value.Elem()
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := field.Tag.Get("form")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		values := c.formValues(name)
+		if len(values) == 0 {
+			continue
+		}
+		if err := setFormField(value.Field(i), values); err != nil {
+			return fmt.Errorf("form field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// formValues returns all values of a urlencoded or multipart form field
+func (c *Context) formValues(name string) []string {
+	var values []string
+	for _, value := range c.fastCtx.PostArgs().PeekMulti(name) {
+		values = append(values, string(value))
+	}
+	if len(values) > 0 {
+		return values
+	}
+
+	if form, err := c.fastCtx.MultipartForm(); err == nil {
+		return form.Value[name]
+	}
+	return nil
+}
+
+func setFormField(field reflect.Value, values []string) error {
+	switch field.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setFormValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	case reflect.Pointer:
+		target := reflect.New(field.Type().Elem())
+		if err := setFormValue(target.Elem(), values[0]); err != nil {
+			return err
+		}
+		field.Set(target)
+		return nil
+	}
+	return setFormValue(field, values[0])
+}
+
+func setFormValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", value)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
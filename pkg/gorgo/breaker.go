@@ -0,0 +1,263 @@
+package gorgo
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerStateEvent is published when a circuit breaker changes
+// state. Data contains "name", "from" and "to".
+const CircuitBreakerStateEvent = "circuit_breaker.state_changed"
+
+// ErrCircuitOpen is returned by CircuitBreaker.Execute while calls are rejected
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets calls through and counts failures
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects calls until OpenTimeout has passed
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe calls through
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerOptions configuration for a circuit breaker
+type CircuitBreakerOptions struct {
+	// Name identifies the breaker in events and logs
+	Name string
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before probing recovery
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of probe calls let through while
+	// half-open. The circuit closes once they all succeed.
+	HalfOpenRequests int
+	// IsFailure decides which errors count as failures, e.g. to ignore
+	// "not found" errors. Defaults to every non-nil error.
+	IsFailure func(err error) bool
+	// EventBus receives circuit_breaker.state_changed events, optional
+	EventBus *EventBus
+}
+
+// DefaultCircuitBreakerOptions returns default circuit breaker settings
+func DefaultCircuitBreakerOptions() CircuitBreakerOptions {
+	return CircuitBreakerOptions{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenRequests: 1,
+	}
+}
+
+// CircuitBreaker stops calling a failing dependency. After FailureThreshold
+// consecutive failures it opens and rejects calls with ErrCircuitOpen; after
+// OpenTimeout it half-opens and lets probe calls through, closing again when
+// they succeed and reopening when one fails.
+//
+//	breaker := gorgo.NewCircuitBreaker(gorgo.CircuitBreakerOptions{Name: "payments"})
+//	err := breaker.Execute(func() error {
+//	    return payments.Charge(order)
+//	})
+type CircuitBreaker struct {
+	options CircuitBreakerOptions
+
+	mu         sync.Mutex
+	state      CircuitState
+	failures   int
+	openedAt   time.Time
+	probes     int
+	successes  int
+	generation uint64
+	now        func() time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker. Zero options fall
+// back to DefaultCircuitBreakerOptions.
+func NewCircuitBreaker(options CircuitBreakerOptions) *CircuitBreaker {
+	defaults := DefaultCircuitBreakerOptions()
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = defaults.FailureThreshold
+	}
+	if options.OpenTimeout <= 0 {
+		options.OpenTimeout = defaults.OpenTimeout
+	}
+	if options.HalfOpenRequests <= 0 {
+		options.HalfOpenRequests = defaults.HalfOpenRequests
+	}
+
+	return &CircuitBreaker{
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// Name returns the breaker name
+func (b *CircuitBreaker) Name() string {
+	return b.options.Name
+}
+
+// State returns the current state
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	from := b.state
+	b.checkTimeout()
+	to := b.state
+	b.mu.Unlock()
+
+	b.publish(from, to)
+	return to
+}
+
+// Execute calls fn unless the circuit is open, in which case it returns
+// ErrCircuitOpen without calling fn. The error of fn is returned unchanged.
+// A panic in fn counts as a failure and is passed on.
+func (b *CircuitBreaker) Execute(fn func() error) error {
+	generation, err := b.before()
+	if err != nil {
+		return err
+	}
+
+	// Record the call even if fn panics, or a half-open probe slot
+	// would stay taken and the circuit would reject calls forever
+	panicked := true
+	defer func() {
+		if panicked {
+			b.after(generation, true)
+		}
+	}()
+
+	err = fn()
+	panicked = false
+	b.after(generation, b.isFailure(err))
+	return err
+}
+
+// Reset closes the circuit and clears the failure count
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	from := b.state
+	b.setState(CircuitClosed)
+	b.mu.Unlock()
+
+	b.publish(from, CircuitClosed)
+}
+
+func (b *CircuitBreaker) before() (uint64, error) {
+	b.mu.Lock()
+	from := b.state
+	b.checkTimeout()
+	to := b.state
+
+	var err error
+	switch b.state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.options.HalfOpenRequests {
+			err = ErrCircuitOpen
+		} else {
+			b.probes++
+		}
+	}
+	generation := b.generation
+	b.mu.Unlock()
+
+	b.publish(from, to)
+	return generation, err
+}
+
+// isFailure reports whether err counts as a failure
+func (b *CircuitBreaker) isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if b.options.IsFailure != nil {
+		return b.options.IsFailure(err)
+	}
+	return true
+}
+
+func (b *CircuitBreaker) after(generation uint64, failed bool) {
+	b.mu.Lock()
+	from := b.state
+
+	// Ignore results of calls that started before the last state change
+	if generation != b.generation {
+		b.mu.Unlock()
+		return
+	}
+
+	switch b.state {
+	case CircuitClosed:
+		if !failed {
+			b.failures = 0
+		} else if b.failures++; b.failures >= b.options.FailureThreshold {
+			b.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		if failed {
+			b.setState(CircuitOpen)
+		} else if b.successes++; b.successes >= b.options.HalfOpenRequests {
+			b.setState(CircuitClosed)
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+
+	b.publish(from, to)
+}
+
+// checkTimeout half-opens the circuit once OpenTimeout has passed
+func (b *CircuitBreaker) checkTimeout() {
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.options.OpenTimeout {
+		b.setState(CircuitHalfOpen)
+	}
+}
+
+func (b *CircuitBreaker) setState(state CircuitState) {
+	if state == b.state && state != CircuitClosed {
+		return
+	}
+
+	b.state = state
+	b.failures = 0
+	b.probes = 0
+	b.successes = 0
+	b.generation++
+	if state == CircuitOpen {
+		b.openedAt = b.now()
+	}
+}
+
+func (b *CircuitBreaker) publish(from, to CircuitState) {
+	if from == to {
+		return
+	}
+
+	log.Printf("Circuit breaker %s: %s -> %s", b.options.Name, from, to)
+	if b.options.EventBus == nil {
+		return
+	}
+
+	b.options.EventBus.Publish(context.Background(), CircuitBreakerStateEvent, map[string]interface{}{
+		"name": b.options.Name,
+		"from": from.String(),
+		"to":   to.String(),
+	})
+}
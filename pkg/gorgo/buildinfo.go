@@ -0,0 +1,87 @@
+package gorgo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/GorgoFramework/gorgo/pkg/gorgo.buildVersion=1.4.2 \
+//	    -X github.com/GorgoFramework/gorgo/pkg/gorgo.buildCommit=$(git rev-parse HEAD) \
+//	    -X github.com/GorgoFramework/gorgo/pkg/gorgo.buildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	buildVersion string
+	buildCommit  string
+	buildTime    string
+)
+
+// BuildInfo describes the deployed build
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// ReadBuildInfo returns the build metadata injected with ldflags. Missing
+// values come from the VCS information Go embeds in the binary, and the
+// version finally falls back to defaultVersion.
+func ReadBuildInfo(defaultVersion string) BuildInfo {
+	info := BuildInfo{
+		Version:   buildVersion,
+		Commit:    buildCommit,
+		BuildTime: buildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if embedded, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && embedded.Main.Version != "" && embedded.Main.Version != "(devel)" {
+			info.Version = embedded.Main.Version
+		}
+		for _, setting := range embedded.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = defaultVersion
+	}
+	return info
+}
+
+// SetBuildInfo replaces the build metadata reported by BuildInfo, the
+// build info endpoint and the readiness endpoint. By default it is read
+// with ReadBuildInfo, falling back to the app.version config value. The
+// startup banner is printed by New, so only ldflags affect it.
+func (a *Application) SetBuildInfo(info BuildInfo) *Application {
+	if info.GoVersion == "" {
+		info.GoVersion = runtime.Version()
+	}
+	a.buildInfo.Store(&info)
+	return a
+}
+
+// BuildInfo returns the build metadata of the application
+func (a *Application) BuildInfo() BuildInfo {
+	if info := a.buildInfo.Load(); info != nil {
+		return *info
+	}
+	return ReadBuildInfo(a.config.App.Version)
+}
+
+// BuildInfoEndpoint registers an endpoint responding with the build
+// metadata, e.g. app.BuildInfoEndpoint("/version"):
+//
+//	{"version": "1.4.2", "commit": "9d726f8...", "build_time": "2026-10-16T09:30:00Z", "go_version": "go1.23.4"}
+func (a *Application) BuildInfoEndpoint(path string) *Application {
+	a.Get(path, func(ctx *Context) error {
+		return ctx.writeJSON(a.BuildInfo())
+	})
+	return a
+}
@@ -0,0 +1,129 @@
+package gorgo
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IfMatch returns the raw If-Match request header
+func (c *Context) IfMatch() string {
+	return c.GetHeader("If-Match")
+}
+
+// IfNoneMatch returns the raw If-None-Match request header
+func (c *Context) IfNoneMatch() string {
+	return c.GetHeader("If-None-Match")
+}
+
+// IfModifiedSince returns the parsed If-Modified-Since header. All three
+// HTTP-date formats (IMF-fixdate, RFC 850 and asctime) are accepted.
+func (c *Context) IfModifiedSince() (time.Time, bool) {
+	return c.headerTime("If-Modified-Since")
+}
+
+// IfUnmodifiedSince returns the parsed If-Unmodified-Since header
+func (c *Context) IfUnmodifiedSince() (time.Time, bool) {
+	return c.headerTime("If-Unmodified-Since")
+}
+
+func (c *Context) headerTime(name string) (time.Time, bool) {
+	value := c.GetHeader(name)
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// CheckPreconditions evaluates the conditional request headers against the
+// current representation, following the order of RFC 9110 section 13.2.2.
+// It sets the ETag and Last-Modified response headers (when non-empty) and
+// returns true if it already responded with 304 Not Modified or 412
+// Precondition Failed, in which case the handler should return.
+//
+//	if ctx.CheckPreconditions(etag, user.UpdatedAt) {
+//	    return nil
+//	}
+func (c *Context) CheckPreconditions(etag string, lastModified time.Time) bool {
+	if etag != "" {
+		c.Header("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	// HTTP dates have second precision
+	lastModified = lastModified.Truncate(time.Second)
+
+	if ifMatch := c.IfMatch(); ifMatch != "" {
+		if !etagListMatches(ifMatch, etag, true) {
+			return c.preconditionFailed()
+		}
+	} else if since, ok := c.IfUnmodifiedSince(); ok && !lastModified.IsZero() {
+		if lastModified.After(since) {
+			return c.preconditionFailed()
+		}
+	}
+
+	safe := c.Method() == "GET" || c.Method() == "HEAD"
+
+	if ifNoneMatch := c.IfNoneMatch(); ifNoneMatch != "" {
+		if etagListMatches(ifNoneMatch, etag, false) {
+			if safe {
+				return c.notModified()
+			}
+			return c.preconditionFailed()
+		}
+	} else if since, ok := c.IfModifiedSince(); ok && safe && !lastModified.IsZero() {
+		if !lastModified.After(since) {
+			return c.notModified()
+		}
+	}
+
+	return false
+}
+
+func (c *Context) notModified() bool {
+	c.fastCtx.SetStatusCode(NotModifiedStatus)
+	c.fastCtx.ResetBody()
+	return true
+}
+
+func (c *Context) preconditionFailed() bool {
+	c.fastCtx.SetStatusCode(PreconditionFailedStatus)
+	c.fastCtx.ResetBody()
+	return true
+}
+
+// etagListMatches reports whether etag matches the comma separated list of
+// entity tags in header. Strong comparison requires both tags to be strong.
+func etagListMatches(header, etag string, strong bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return etag != ""
+	}
+	if etag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strong {
+			if !isWeakETag(candidate) && !isWeakETag(etag) && candidate == etag {
+				return true
+			}
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func isWeakETag(etag string) bool {
+	return strings.HasPrefix(etag, "W/")
+}
@@ -0,0 +1,113 @@
+package gorgo
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Plugin config accessors. Plugin configuration comes from TOML (integers
+// decode as int64), JSON (numbers decode as float64) or Go maps, so the
+// accessors coerce between compatible representations and fall back to
+// the default when a value is missing or cannot be converted.
+
+// GetStringConfig returns a string value
+func GetStringConfig(config map[string]interface{}, key, defaultValue string) string {
+	if value, ok := config[key].(string); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// GetStringSliceConfig returns a list of strings, skipping non-string items
+func GetStringSliceConfig(config map[string]interface{}, key string) []string {
+	switch value := config[key].(type) {
+	case []string:
+		return value
+	case []interface{}:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// GetIntConfig returns an integer value. Whole floats and numeric strings
+// are accepted.
+func GetIntConfig(config map[string]interface{}, key string, defaultValue int) int {
+	switch value := config[key].(type) {
+	case int:
+		return value
+	case int64:
+		return int(value)
+	case int32:
+		return int(value)
+	case float64:
+		if value == math.Trunc(value) {
+			return int(value)
+		}
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// GetFloatConfig returns a float value. Integers and numeric strings are accepted.
+func GetFloatConfig(config map[string]interface{}, key string, defaultValue float64) float64 {
+	switch value := config[key].(type) {
+	case float64:
+		return value
+	case float32:
+		return float64(value)
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case int32:
+		return float64(value)
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
+
+// GetBoolConfig returns a boolean value. Strings such as "true", "false",
+// "1" and "0" are accepted.
+func GetBoolConfig(config map[string]interface{}, key string, defaultValue bool) bool {
+	switch value := config[key].(type) {
+	case bool:
+		return value
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			return b
+		}
+	}
+	return defaultValue
+}
+
+// GetDurationConfig returns a duration value. Go duration strings such as
+// "30s" or "5m" are preferred; plain numbers are interpreted as seconds
+// for compatibility with older configs.
+func GetDurationConfig(config map[string]interface{}, key string, defaultValue time.Duration) time.Duration {
+	switch value := config[key].(type) {
+	case time.Duration:
+		return value
+	case string:
+		if d, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+			return d
+		}
+	case int, int64, int32, float64, float32:
+		seconds := GetFloatConfig(config, key, 0)
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return defaultValue
+}
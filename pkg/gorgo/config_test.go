@@ -0,0 +1,177 @@
+package gorgo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetIntConfig(t *testing.T) {
+	config := map[string]interface{}{
+		"int":      5,
+		"int64":    int64(6),
+		"float":    float64(7),
+		"fraction": 7.5,
+		"string":   "8",
+		"invalid":  "eight",
+	}
+
+	tests := map[string]int{
+		"int":      5,
+		"int64":    6,
+		"float":    7,
+		"fraction": -1,
+		"string":   8,
+		"invalid":  -1,
+		"missing":  -1,
+	}
+
+	for key, expected := range tests {
+		if got := GetIntConfig(config, key, -1); got != expected {
+			t.Errorf("GetIntConfig(%q): expected %d, got %d", key, expected, got)
+		}
+	}
+}
+
+func TestGetFloatConfig(t *testing.T) {
+	config := map[string]interface{}{
+		"float":   0.5,
+		"int":     2,
+		"int64":   int64(3),
+		"string":  "1.25",
+		"invalid": "half",
+	}
+
+	tests := map[string]float64{
+		"float":   0.5,
+		"int":     2,
+		"int64":   3,
+		"string":  1.25,
+		"invalid": -1,
+		"missing": -1,
+	}
+
+	for key, expected := range tests {
+		if got := GetFloatConfig(config, key, -1); got != expected {
+			t.Errorf("GetFloatConfig(%q): expected %v, got %v", key, expected, got)
+		}
+	}
+}
+
+func TestGetBoolConfig(t *testing.T) {
+	config := map[string]interface{}{
+		"bool":    true,
+		"true":    "true",
+		"false":   "false",
+		"zero":    "0",
+		"invalid": "maybe",
+		"number":  1,
+	}
+
+	tests := map[string]bool{
+		"bool":    true,
+		"true":    true,
+		"false":   false,
+		"zero":    false,
+		"invalid": true,
+		"number":  true,
+		"missing": true,
+	}
+
+	for key, expected := range tests {
+		if got := GetBoolConfig(config, key, true); got != expected {
+			t.Errorf("GetBoolConfig(%q): expected %v, got %v", key, expected, got)
+		}
+	}
+}
+
+func TestGetDurationConfig(t *testing.T) {
+	config := map[string]interface{}{
+		"string":   "30s",
+		"compound": "1m30s",
+		"duration": 2 * time.Second,
+		"int":      int64(10),
+		"float":    1.5,
+		"invalid":  "soon",
+	}
+
+	tests := map[string]time.Duration{
+		"string":   30 * time.Second,
+		"compound": 90 * time.Second,
+		"duration": 2 * time.Second,
+		"int":      10 * time.Second,
+		"float":    1500 * time.Millisecond,
+		"invalid":  time.Minute,
+		"missing":  time.Minute,
+	}
+
+	for key, expected := range tests {
+		if got := GetDurationConfig(config, key, time.Minute); got != expected {
+			t.Errorf("GetDurationConfig(%q): expected %v, got %v", key, expected, got)
+		}
+	}
+}
+
+func TestGetStringConfig(t *testing.T) {
+	config := map[string]interface{}{
+		"string": "value",
+		"number": 1,
+		"list":   []interface{}{"a", 1, "b"},
+		"typed":  []string{"c"},
+	}
+
+	if got := GetStringConfig(config, "string", "default"); got != "value" {
+		t.Errorf("Expected 'value', got %q", got)
+	}
+	if got := GetStringConfig(config, "number", "default"); got != "default" {
+		t.Errorf("Expected 'default' for non-string value, got %q", got)
+	}
+	if got := GetStringSliceConfig(config, "list"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v", got)
+	}
+	if got := GetStringSliceConfig(config, "typed"); !reflect.DeepEqual(got, []string{"c"}) {
+		t.Errorf("Expected [c], got %v", got)
+	}
+	if got := GetStringSliceConfig(config, "missing"); got != nil {
+		t.Errorf("Expected nil for missing key, got %v", got)
+	}
+}
+
+func TestRedactConfig(t *testing.T) {
+	MarkSecretConfigKey("signing_key")
+
+	config := map[string]interface{}{
+		"host":         "localhost",
+		"password":     "hunter2",
+		"auth_token":   "abc",
+		"signing_key":  "k",
+		"dsn":          "postgres://app:hunter2@db:5432/app?sslmode=require",
+		"url":          "host=db user=app password=hunter2",
+		"nested":       map[string]interface{}{"secret": "s", "port": 5432},
+		"max_conns":    25,
+		"redis_secret": "r",
+	}
+
+	redacted := RedactConfig(config)
+
+	for _, key := range []string{"password", "auth_token", "signing_key", "redis_secret"} {
+		if redacted[key] != RedactedValue {
+			t.Errorf("Expected %s to be redacted, got %v", key, redacted[key])
+		}
+	}
+	if redacted["dsn"] != "postgres://app:***@db:5432/app?sslmode=require" {
+		t.Errorf("Expected DSN password to be redacted, got %v", redacted["dsn"])
+	}
+	if redacted["url"] != "host=db user=app password=***" {
+		t.Errorf("Expected key/value password to be redacted, got %v", redacted["url"])
+	}
+	if nested := redacted["nested"].(map[string]interface{}); nested["secret"] != RedactedValue || nested["port"] != 5432 {
+		t.Errorf("Expected nested secrets to be redacted, got %v", nested)
+	}
+	if redacted["host"] != "localhost" || redacted["max_conns"] != 25 {
+		t.Errorf("Expected other values to be kept, got %v", redacted)
+	}
+	if config["password"] != "hunter2" {
+		t.Error("Expected the original config to be unchanged")
+	}
+}
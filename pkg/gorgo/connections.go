@@ -0,0 +1,115 @@
+package gorgo
+
+import (
+	"net"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// connTracker counts open connections per client IP and reports the total
+// as the http_connections_active gauge. Per-IP counts are not exported as
+// metrics, since a series per client IP would grow without bound.
+type connTracker struct {
+	mu      sync.Mutex
+	perIP   map[string]int
+	conns   map[net.Conn]struct{}
+	total   int
+	metrics Metrics
+}
+
+func newConnTracker(metrics Metrics) *connTracker {
+	return &connTracker{
+		perIP:   make(map[string]int),
+		conns:   make(map[net.Conn]struct{}),
+		metrics: metrics,
+	}
+}
+
+// connState is used as the fasthttp.Server ConnState hook
+func (t *connTracker) connState(conn net.Conn, state fasthttp.ConnState) {
+	var delta int
+	switch state {
+	case fasthttp.StateNew:
+		delta = 1
+	case fasthttp.StateClosed, fasthttp.StateHijacked:
+		delta = -1
+	default:
+		return
+	}
+
+	ip := connIP(conn)
+
+	t.mu.Lock()
+	if delta > 0 {
+		t.conns[conn] = struct{}{}
+	} else {
+		delete(t.conns, conn)
+	}
+	if n := t.perIP[ip] + delta; n <= 0 {
+		delete(t.perIP, ip)
+	} else {
+		t.perIP[ip] = n
+	}
+	t.total += delta
+	total := t.total
+	t.mu.Unlock()
+
+	t.metrics.Gauge("http_connections_active", float64(total), nil)
+}
+
+// snapshot returns a copy of the open connection counts per IP
+func (t *connTracker) snapshot() map[string]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make(map[string]int, len(t.perIP))
+	for ip, n := range t.perIP {
+		result[ip] = n
+	}
+	return result
+}
+
+// closeAll closes every open connection, e.g. ones still busy when the
+// shutdown timeout expires, and returns how many there were
+func (t *connTracker) closeAll() int {
+	t.mu.Lock()
+	conns := make([]net.Conn, 0, len(t.conns))
+	for conn := range t.conns {
+		conns = append(conns, conn)
+	}
+	t.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+	return len(conns)
+}
+
+func connIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// ConnectionsPerIP returns the number of open connections per client IP.
+// It is empty until the server is running.
+func (a *Application) ConnectionsPerIP() map[string]int {
+	tracker := a.connTracker.Load()
+	if tracker == nil {
+		return map[string]int{}
+	}
+	return tracker.snapshot()
+}
+
+// metrics returns the Metrics registered in the container
+func (a *Application) metrics() Metrics {
+	if service, ok := a.container.Get(MetricsService); ok {
+		if metrics, ok := service.(Metrics); ok {
+			return metrics
+		}
+	}
+	return NoopMetrics{}
+}
@@ -1,14 +1,29 @@
 package gorgo
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
 	"mime/multipart"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/GorgoFramework/gorgo/internal/container"
 	"github.com/valyala/fasthttp"
 )
 
+// Context is the request context passed to handlers and middleware. The
+// application reuses Contexts across requests, so a Context and anything
+// obtained from it, such as FastHTTP(), must not be retained or used after
+// the handler returns. Copy the values a goroutine needs instead.
 type Context struct {
 	fastCtx *fasthttp.RequestCtx
 
@@ -17,6 +32,18 @@ type Context struct {
 	params    map[string]string
 	data      map[string]interface{} // Additional data
 	mu        sync.RWMutex
+
+	maxBodySize int
+	apiVersion  string
+	route       string
+	aborted     bool
+	startTime   time.Time
+
+	errorHandler   func(ctx *Context, err error)
+	errorHooks     []func(ctx *Context, err error)
+	errorFormat    ErrorFormat
+	goCtx          context.Context
+	trustedProxies []*net.IPNet
 }
 
 func NewContext(ctx *fasthttp.RequestCtx, container *container.Container, plugins map[string]Plugin) *Context {
@@ -26,21 +53,102 @@ func NewContext(ctx *fasthttp.RequestCtx, container *container.Container, plugin
 		plugins:   plugins,
 		params:    make(map[string]string),
 		data:      make(map[string]interface{}),
+
+		maxBodySize: fasthttp.DefaultMaxRequestBodySize,
+		startTime:   time.Now(),
 	}
 }
 
+// contextPool holds released Contexts with their maps for reuse
+var contextPool = sync.Pool{
+	New: func() interface{} {
+		return &Context{
+			params: make(map[string]string),
+			data:   make(map[string]interface{}),
+		}
+	},
+}
+
+// acquireContext returns a pooled Context initialized like NewContext
+func acquireContext(ctx *fasthttp.RequestCtx, container *container.Container, plugins map[string]Plugin) *Context {
+	c := contextPool.Get().(*Context)
+	c.fastCtx = ctx
+	c.container = container
+	c.plugins = plugins
+	c.maxBodySize = fasthttp.DefaultMaxRequestBodySize
+	c.startTime = time.Now()
+	return c
+}
+
+// releaseContext resets c and returns it to the pool. c must not be used
+// afterwards.
+func releaseContext(c *Context) {
+	params, data := c.params, c.data
+	clear(params)
+	clear(data)
+	*c = Context{params: params, data: data}
+	contextPool.Put(c)
+}
+
 func (c *Context) GetService(name string) (interface{}, bool) {
 	return c.container.Get(name)
 }
 
+// eventBus returns the application event bus registered in the container
+func (c *Context) eventBus() *EventBus {
+	if service, ok := c.container.Get(EventBusService); ok {
+		if eventBus, ok := service.(*EventBus); ok {
+			return eventBus
+		}
+	}
+	return nil
+}
+
 func (c *Context) GetPlugin(name string) (Plugin, bool) {
 	plugin, ok := c.plugins[name]
 	return plugin, ok
 }
 
 func (c *Context) JSON(data Map) error {
+	return c.writeJSON(data)
+}
+
+func (c *Context) writeJSON(data interface{}) error {
 	c.fastCtx.Response.Header.SetContentType("application/json")
-	return json.NewEncoder(c.fastCtx.Response.BodyWriter()).Encode(data)
+	return GetJSONCodec().NewEncoder(c.fastCtx.Response.BodyWriter()).Encode(data)
+}
+
+// MaxPrettyJSONSize is the largest compact JSON body JSONPretty indents.
+// Larger bodies are sent compact, since indentation can multiply the size
+// of deeply nested data.
+var MaxPrettyJSONSize = 1 << 20
+
+// maxJSONIndent bounds the indent string of JSONPretty
+const maxJSONIndent = 8
+
+// JSONPretty writes data as indented JSON for human-readable debug and
+// admin endpoints. indent may contain up to 8 spaces or tabs.
+func (c *Context) JSONPretty(data interface{}, indent string) error {
+	if len(indent) > maxJSONIndent || strings.Trim(indent, " \t") != "" {
+		return fmt.Errorf("invalid JSON indent %q: use up to %d spaces or tabs", indent, maxJSONIndent)
+	}
+
+	body, err := GetJSONCodec().Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if len(body) <= MaxPrettyJSONSize {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, body, "", indent); err != nil {
+			return err
+		}
+		body = indented.Bytes()
+	}
+
+	c.fastCtx.Response.Header.SetContentType("application/json")
+	c.fastCtx.SetBody(append(body, '\n'))
+	return nil
 }
 
 func (c *Context) String(data string) error {
@@ -55,10 +163,24 @@ func (c *Context) HTML(html string) error {
 	return nil
 }
 
+// XML writes data with encoding/xml. A Map is written as a <response>
+// element with one child element per key.
 func (c *Context) XML(data interface{}) error {
+	switch m := data.(type) {
+	case Map:
+		data = xmlMap{name: "response", data: m}
+	case map[string]interface{}:
+		data = xmlMap{name: "response", data: m}
+	}
+
+	body, err := xml.Marshal(data)
+	if err != nil {
+		return err
+	}
+
 	c.fastCtx.Response.Header.SetContentType("application/xml")
-	// Simple XML serialization (can be improved)
-	c.fastCtx.SetBodyString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<response></response>")
+	c.fastCtx.SetBodyString(xml.Header)
+	c.fastCtx.Response.AppendBody(body)
 	return nil
 }
 
@@ -67,11 +189,83 @@ func (c *Context) Status(code int) *Context {
 	return c
 }
 
+// StatusCode returns the response status code, 200 if none was set
+func (c *Context) StatusCode() int {
+	if status := c.fastCtx.Response.StatusCode(); status > 0 {
+		return status
+	}
+	return OKStatus
+}
+
+// ResponseSize returns the size of the response body in bytes. For a
+// streamed body it is the Content-Length, or -1 when the length is unknown.
+func (c *Context) ResponseSize() int {
+	if c.fastCtx.Response.IsBodyStream() {
+		if length := c.fastCtx.Response.Header.ContentLength(); length >= 0 {
+			return length
+		}
+		return -1
+	}
+	return len(c.fastCtx.Response.Body())
+}
+
+// Header sets a response header, replacing any value set before
 func (c *Context) Header(key, value string) *Context {
 	c.fastCtx.Response.Header.Set(key, value)
 	return c
 }
 
+// AddHeader appends a response header value, keeping values set before, so
+// multi-valued headers such as Link or Vary can be sent as separate lines.
+// Set-Cookie headers are kept per cookie name: adding a cookie with a new
+// name keeps the others, while the same name replaces it.
+func (c *Context) AddHeader(key, value string) *Context {
+	c.fastCtx.Response.Header.Add(key, value)
+	return c
+}
+
+// DelHeader removes all values of a response header
+func (c *Context) DelHeader(key string) *Context {
+	c.fastCtx.Response.Header.Del(key)
+	return c
+}
+
+// SetHeaders sets several response headers. Like Header, each value
+// replaces any value set before for that key; use AddHeader to append.
+func (c *Context) SetHeaders(headers map[string]string) *Context {
+	for key, value := range headers {
+		c.fastCtx.Response.Header.Set(key, value)
+	}
+	return c
+}
+
+// Headers returns a copy of the response headers set so far, including
+// Content-Type and one value per Set-Cookie header
+func (c *Context) Headers() http.Header {
+	headers := make(http.Header)
+	c.fastCtx.Response.Header.VisitAll(func(key, value []byte) {
+		headers.Add(string(key), string(value))
+	})
+	return headers
+}
+
+// Deprecate marks the endpoint as deprecated by setting the Deprecation
+// header, the Sunset header (as an HTTP-date) when sunset is non-zero and a
+// Link header with rel="deprecation" pointing to infoURL when it is non-empty.
+func (c *Context) Deprecate(sunset time.Time, infoURL string) *Context {
+	c.fastCtx.Response.Header.Set("Deprecation", "true")
+
+	if !sunset.IsZero() {
+		c.fastCtx.Response.Header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+
+	if infoURL != "" {
+		c.fastCtx.Response.Header.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, infoURL))
+	}
+
+	return c
+}
+
 func (c *Context) Cookie(cookie *fasthttp.Cookie) *Context {
 	c.fastCtx.Response.Header.SetCookie(cookie)
 	return c
@@ -96,6 +290,51 @@ func (c *Context) ParamDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// ParamInt parses the path parameter key as a base-10 int
+func (c *Context) ParamInt(key string) (int, error) {
+	value, err := strconv.Atoi(c.params[key])
+	if err != nil {
+		return 0, paramError(key, c.params[key], "integer", err)
+	}
+	return value, nil
+}
+
+// ParamInt64 parses the path parameter key as a base-10 int64
+func (c *Context) ParamInt64(key string) (int64, error) {
+	value, err := strconv.ParseInt(c.params[key], 10, 64)
+	if err != nil {
+		return 0, paramError(key, c.params[key], "integer", err)
+	}
+	return value, nil
+}
+
+// ParamBool parses the path parameter key with strconv.ParseBool, which
+// accepts 1, t, true, 0, f, false and their upper-case forms
+func (c *Context) ParamBool(key string) (bool, error) {
+	value, err := strconv.ParseBool(c.params[key])
+	if err != nil {
+		return false, paramError(key, c.params[key], "boolean", err)
+	}
+	return value, nil
+}
+
+// ParamIntDefault returns the path parameter key as an int, or defaultValue
+// when it is missing or not a valid integer
+func (c *Context) ParamIntDefault(key string, defaultValue int) int {
+	value, err := c.ParamInt(key)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func paramError(key, raw, kind string, err error) error {
+	if numErr, ok := err.(*strconv.NumError); ok {
+		err = numErr.Err
+	}
+	return fmt.Errorf("path parameter %q: invalid %s %q: %w", key, kind, raw, err)
+}
+
 func (c *Context) HasParam(key string) bool {
 	_, exists := c.params[key]
 	return exists
@@ -186,17 +425,163 @@ func (c *Context) FormFile(key string) (*multipart.FileHeader, error) {
 	return c.fastCtx.FormFile(key)
 }
 
+// MultipartReader returns a streaming reader over the parts of a
+// multipart/form-data body so large uploads can be processed one part at
+// a time. Enable server.stream_request_body to avoid buffering the body;
+// reads then fail with ErrBodyTooLarge past server.max_request_body_size.
+// It consumes the body and is mutually exclusive with FormValue and
+// FormFile, which need the fully parsed form.
+func (c *Context) MultipartReader() (*multipart.Reader, error) {
+	contentType := string(c.fastCtx.Request.Header.ContentType())
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("content type %q is not multipart", mediaType)
+	}
+
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, fmt.Errorf("multipart boundary not found")
+	}
+
+	return multipart.NewReader(c.BodyStream(), boundary), nil
+}
+
 // Methods for working with request body
+
+// ContentLength returns the Content-Length of the request, or -1 when it
+// is absent, e.g. for chunked requests
+func (c *Context) ContentLength() int {
+	if length := c.fastCtx.Request.Header.ContentLength(); length >= 0 {
+		return length
+	}
+	return -1
+}
+
+// IsChunked reports whether the request body was sent with chunked
+// transfer encoding
+func (c *Context) IsChunked() bool {
+	return c.fastCtx.Request.Header.ContentLength() == -1 ||
+		strings.Contains(strings.ToLower(c.GetHeader("Transfer-Encoding")), "chunked")
+}
+
+// RequestTrailer returns a trailer field sent after a chunked body, or an empty
+// string when the client didn't declare it in the Trailer header. With
+// server.stream_request_body trailers are only available once the body
+// stream has been read to the end.
+func (c *Context) RequestTrailer(key string) string {
+	for _, trailer := range c.fastCtx.Request.Header.PeekTrailerKeys() {
+		if strings.EqualFold(string(trailer), key) {
+			return c.GetHeader(key)
+		}
+	}
+	return ""
+}
+
+// Body returns the request body. A streamed body
+// (server.stream_request_body) is read into memory first; since FastHTTP
+// doesn't limit streamed bodies, Body returns nil when it exceeds
+// server.max_request_body_size. Use PeekBody to tell that apart from an
+// empty body.
 func (c *Context) Body() []byte {
-	return c.fastCtx.Request.Body()
+	body, err := c.readBody()
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+// PeekBody returns the request body and keeps it available for the
+// handler, e.g. for body logging or HMAC signature checks in middleware.
+// A streamed body (server.stream_request_body) is read into memory once,
+// so it costs up to server.max_request_body_size per request; larger bodies
+// return ErrBodyTooLarge. Body, BindJSON and BodyStream read the buffered
+// copy afterwards.
+func (c *Context) PeekBody() ([]byte, error) {
+	return c.readBody()
+}
+
+// BodyStream returns the request body as an io.Reader. When
+// server.stream_request_body is enabled the body is read incrementally
+// from the connection and reads fail with ErrBodyTooLarge past
+// server.max_request_body_size; otherwise the reader wraps the buffered body.
+// Reading the stream consumes the body, so Body() must not be relied on afterwards.
+func (c *Context) BodyStream() io.Reader {
+	if stream := c.fastCtx.RequestBodyStream(); stream != nil {
+		// FastHTTP doesn't limit streamed bodies
+		return &bodyLimitReader{reader: stream, remaining: int64(c.maxBodySize)}
+	}
+	return bytes.NewReader(c.fastCtx.Request.Body())
 }
 
 func (c *Context) BodyString() string {
 	return string(c.Body())
 }
 
+// BindJSON decodes the JSON body into v with the configured JSONCodec.
+// A streamed body over server.max_request_body_size returns ErrBodyTooLarge.
 func (c *Context) BindJSON(v interface{}) error {
-	return json.Unmarshal(c.Body(), v)
+	body, err := c.readBody()
+	if err != nil {
+		return err
+	}
+	return GetJSONCodec().Unmarshal(body, v)
+}
+
+// BindJSONWithNumbers decodes the JSON body like BindJSON, but numbers in
+// interface{} values and maps are decoded as json.Number instead of
+// float64. A float64 holds integers exactly only up to 2^53, so larger IDs
+// such as Postgres bigint keys lose precision; json.Number keeps the
+// original digits for Int64 or Float64 conversion.
+func (c *Context) BindJSONWithNumbers(v interface{}) error {
+	body, err := c.readBody()
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// BodyMap decodes a JSON object body into a Map. As with encoding/json,
+// numbers are decoded as float64. The body is limited to
+// server.max_request_body_size even when request streaming is enabled.
+func (c *Context) BodyMap() (Map, error) {
+	body, err := c.readBody()
+	if err != nil {
+		return nil, err
+	}
+
+	var result Map
+	if err := GetJSONCodec().Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return result, nil
+}
+
+// readBody returns the request body, enforcing the maximum body size
+// for streamed bodies (buffered bodies are already limited by the server)
+func (c *Context) readBody() ([]byte, error) {
+	stream := c.fastCtx.RequestBodyStream()
+	if stream == nil {
+		return c.fastCtx.Request.Body(), nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(stream, int64(c.maxBodySize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > c.maxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+
+	// Keep the body available for later reads
+	c.fastCtx.Request.SetBodyRaw(body)
+	return body, nil
 }
 
 // Methods for redirects
@@ -205,6 +590,45 @@ func (c *Context) Redirect(url string, statusCode int) error {
 	return nil
 }
 
+// StartTime returns when handling of the request started
+func (c *Context) StartTime() time.Time {
+	return c.startTime
+}
+
+// Elapsed returns the time since the request started
+func (c *Context) Elapsed() time.Duration {
+	return time.Since(c.startTime)
+}
+
+// Route returns the pattern of the matched route, e.g. "/users/:id", or
+// an empty string when no route matched. Use it instead of Path to group
+// metrics and logs by endpoint.
+func (c *Context) Route() string {
+	return c.route
+}
+
+// Abort stops the middleware chain, the remaining middleware and the
+// route handler are not called
+func (c *Context) Abort() {
+	c.aborted = true
+}
+
+func (c *Context) IsAborted() bool {
+	return c.aborted
+}
+
+// AbortWithRedirect redirects to url and aborts the chain, e.g. to send
+// unauthenticated users to a login page. The status must be a 3xx code.
+func (c *Context) AbortWithRedirect(statusCode int, url string) error {
+	if statusCode < 300 || statusCode > 399 {
+		return fmt.Errorf("invalid redirect status code %d", statusCode)
+	}
+
+	c.fastCtx.Redirect(url, statusCode)
+	c.Abort()
+	return nil
+}
+
 // Methods for working with IP
 func (c *Context) ClientIP() string {
 	return c.fastCtx.RemoteIP().String()
@@ -227,7 +651,75 @@ func (c *Context) URL() string {
 	return c.fastCtx.URI().String()
 }
 
-// Get base FastHTTP context (for advanced usage)
+// Methods for working with the protocol
+//
+// FastHTTP only implements HTTP/1.x, so HTTP/2 connections terminated by
+// a proxy in front of the application are seen as HTTP/1.1 here and
+// HTTP/2 server push is not available.
+
+// Protocol returns the request protocol, e.g. "HTTP/1.1"
+func (c *Context) Protocol() string {
+	return string(c.fastCtx.Request.Header.Protocol())
+}
+
+// ProtoMajor returns the major HTTP version of the request
+func (c *Context) ProtoMajor() int {
+	major, _ := c.protoVersion()
+	return major
+}
+
+// ProtoMinor returns the minor HTTP version of the request
+func (c *Context) ProtoMinor() int {
+	_, minor := c.protoVersion()
+	return minor
+}
+
+// IsHTTP2 reports whether the request was made over HTTP/2
+func (c *Context) IsHTTP2() bool {
+	return c.ProtoMajor() == 2
+}
+
+func (c *Context) protoVersion() (int, int) {
+	var major, minor int
+	if _, err := fmt.Sscanf(c.Protocol(), "HTTP/%d.%d", &major, &minor); err != nil {
+		// "HTTP/2" has no minor version
+		fmt.Sscanf(c.Protocol(), "HTTP/%d", &major)
+	}
+	return major, minor
+}
+
+// Trailer declares response trailers that will be sent after a chunked body.
+// Trailer values are set with Header once the body has been streamed.
+// Trailers are only sent with chunked transfer encoding.
+func (c *Context) Trailer(names ...string) error {
+	for _, name := range names {
+		if err := c.fastCtx.Response.Header.AddTrailer(name); err != nil {
+			return fmt.Errorf("invalid trailer %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Context returns the Go context of the request, for passing to database,
+// cache and outbound calls. It is canceled when the server shuts down and
+// carries the values added by the request context extractor.
+func (c *Context) Context() context.Context {
+	if c.goCtx == nil {
+		return context.Background()
+	}
+	return c.goCtx
+}
+
+// SetContext replaces the Go context of the request, e.g. to add values
+// or a deadline in middleware
+func (c *Context) SetContext(ctx context.Context) *Context {
+	c.goCtx = ctx
+	return c
+}
+
+// FastHTTP returns the underlying FastHTTP request context for advanced
+// usage. FastHTTP reuses it for the next request once the handler returns,
+// so it must not be retained.
 func (c *Context) FastHTTP() *fasthttp.RequestCtx {
 	return c.fastCtx
 }
@@ -1,10 +1,25 @@
 package gorgo
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/GorgoFramework/gorgo/internal/container"
 	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
 )
 
 func TestContextParamMethods(t *testing.T) {
@@ -68,3 +83,1566 @@ func TestContextParamMethods(t *testing.T) {
 		t.Error("Modifying returned params map should not affect original parameters")
 	}
 }
+
+func TestContextTypedParams(t *testing.T) {
+	gorgoCtx := NewContext(&fasthttp.RequestCtx{}, container.NewContainer(), make(map[string]Plugin))
+	gorgoCtx.SetParam("id", "42")
+	gorgoCtx.SetParam("big", "9000000000")
+	gorgoCtx.SetParam("flag", "true")
+	gorgoCtx.SetParam("name", "john")
+
+	if id, err := gorgoCtx.ParamInt("id"); err != nil || id != 42 {
+		t.Errorf("ParamInt(id) = %d, %v; want 42", id, err)
+	}
+	if big, err := gorgoCtx.ParamInt64("big"); err != nil || big != 9000000000 {
+		t.Errorf("ParamInt64(big) = %d, %v; want 9000000000", big, err)
+	}
+	if flag, err := gorgoCtx.ParamBool("flag"); err != nil || !flag {
+		t.Errorf("ParamBool(flag) = %v, %v; want true", flag, err)
+	}
+
+	_, err := gorgoCtx.ParamInt("name")
+	if err == nil {
+		t.Fatal("ParamInt(name) should fail")
+	}
+	if !strings.Contains(err.Error(), `"name"`) || !strings.Contains(err.Error(), `"john"`) {
+		t.Errorf("error should mention the parameter and raw value, got %q", err)
+	}
+	if _, err := gorgoCtx.ParamBool("name"); err == nil {
+		t.Error("ParamBool(name) should fail")
+	}
+	if _, err := gorgoCtx.ParamInt("missing"); err == nil {
+		t.Error("ParamInt(missing) should fail")
+	}
+
+	if got := gorgoCtx.ParamIntDefault("id", 1); got != 42 {
+		t.Errorf("ParamIntDefault(id) = %d, want 42", got)
+	}
+	if got := gorgoCtx.ParamIntDefault("name", 1); got != 1 {
+		t.Errorf("ParamIntDefault(name) = %d, want 1", got)
+	}
+}
+
+func TestContextError(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	gorgoCtx := NewContext(ctx, container.NewContainer(), make(map[string]Plugin))
+
+	err := gorgoCtx.Error(422, "validation_failed", "Invalid input", map[string]interface{}{
+		"email": "must be a valid email",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if ctx.Response.StatusCode() != 422 {
+		t.Errorf("Expected status 422, got %d", ctx.Response.StatusCode())
+	}
+
+	var response ErrorResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Code != "validation_failed" {
+		t.Errorf("Expected code 'validation_failed', got '%s'", response.Code)
+	}
+
+	if response.Details["email"] != "must be a valid email" {
+		t.Errorf("Expected email detail, got %v", response.Details["email"])
+	}
+}
+
+func TestQuerySchemaValidate(t *testing.T) {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/users?limit=abc&sort=name")
+	gorgoCtx := NewContext(ctx, container.NewContainer(), make(map[string]Plugin))
+
+	schema := QuerySchema{
+		"limit":  {Type: QueryInt},
+		"sort":   {Allowed: []string{"created", "updated"}},
+		"q":      {Required: true},
+		"page":   {Type: QueryInt, Default: "1"},
+		"active": {Type: QueryBool},
+	}
+
+	violations := schema.Validate(gorgoCtx)
+	if len(violations) != 3 {
+		t.Fatalf("Expected 3 violations, got %d: %v", len(violations), violations)
+	}
+
+	for _, name := range []string{"limit", "sort", "q"} {
+		if _, ok := violations[name]; !ok {
+			t.Errorf("Expected violation for '%s'", name)
+		}
+	}
+
+	if gorgoCtx.Query("page") != "1" {
+		t.Errorf("Expected default page '1', got '%s'", gorgoCtx.Query("page"))
+	}
+}
+
+func TestContextPagination(t *testing.T) {
+	tests := []struct {
+		uri      string
+		expected Pagination
+	}{
+		{"/items", Pagination{Page: 1, Limit: 20, Offset: 0}},
+		{"/items?page=3&limit=10", Pagination{Page: 3, Limit: 10, Offset: 20}},
+		{"/items?page=-1&limit=abc", Pagination{Page: 1, Limit: 20, Offset: 0}},
+		{"/items?limit=1000000", Pagination{Page: 1, Limit: 100, Offset: 0}},
+		{"/items?offset=50&limit=25", Pagination{Page: 3, Limit: 25, Offset: 50}},
+		// An offset that would overflow falls back to the first page
+		{fmt.Sprintf("/items?page=%d&limit=100", math.MaxInt), Pagination{Page: 1, Limit: 100, Offset: 0}},
+		{fmt.Sprintf("/items?page=%d&limit=100", math.MaxInt/100+1), Pagination{Page: math.MaxInt/100 + 1, Limit: 100, Offset: math.MaxInt / 100 * 100}},
+		{fmt.Sprintf("/items?page=%d&limit=100", math.MaxInt/100+2), Pagination{Page: 1, Limit: 100, Offset: 0}},
+		{fmt.Sprintf("/items?offset=%d&limit=1", math.MaxInt), Pagination{Page: math.MaxInt, Limit: 1, Offset: math.MaxInt}},
+	}
+
+	for _, tt := range tests {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(tt.uri)
+		gorgoCtx := NewContext(ctx, container.NewContainer(), make(map[string]Plugin))
+
+		result := gorgoCtx.Pagination(DefaultPaginationOptions())
+		if result != tt.expected {
+			t.Errorf("%s: expected %+v, got %+v", tt.uri, tt.expected, result)
+		}
+	}
+}
+
+func TestAuthMiddlewareAudit(t *testing.T) {
+	c := container.NewContainer()
+	eventBus := NewEventBus()
+	c.Register(EventBusService, eventBus)
+
+	var audits []AuditEvent
+	SubscribeAudit(eventBus, func(audit AuditEvent) error {
+		audits = append(audits, audit)
+		return nil
+	})
+
+	handler := AuthMiddleware(func(ctx *Context) (interface{}, error) {
+		if ctx.GetHeader("Authorization") == "" {
+			return nil, errors.New("missing token")
+		}
+		return "alice", nil
+	})(func(ctx *Context) error { return nil })
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/admin")
+	handler(NewContext(ctx, c, make(map[string]Plugin)))
+
+	ctx = &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/admin")
+	ctx.Request.Header.Set("Authorization", "token")
+	handler(NewContext(ctx, c, make(map[string]Plugin)))
+
+	if len(audits) != 2 {
+		t.Fatalf("Expected 2 audit events, got %d", len(audits))
+	}
+
+	if audits[0].Outcome != AuditFailure || audits[0].Reason != "missing token" {
+		t.Errorf("Expected failure audit with reason, got %+v", audits[0])
+	}
+
+	if audits[1].Outcome != AuditSuccess || audits[1].Actor != "alice" {
+		t.Errorf("Expected success audit for alice, got %+v", audits[1])
+	}
+
+	if audits[1].Resource != "GET /admin" {
+		t.Errorf("Expected resource 'GET /admin', got '%s'", audits[1].Resource)
+	}
+}
+
+func TestContextStartTime(t *testing.T) {
+	before := time.Now()
+	ctx := NewContext(&fasthttp.RequestCtx{}, container.NewContainer(), make(map[string]Plugin))
+
+	if ctx.StartTime().Before(before) || ctx.StartTime().After(time.Now()) {
+		t.Errorf("Expected start time to be set on creation, got %v", ctx.StartTime())
+	}
+
+	time.Sleep(time.Millisecond)
+	if ctx.Elapsed() < time.Millisecond {
+		t.Errorf("Expected elapsed time of at least 1ms, got %v", ctx.Elapsed())
+	}
+}
+
+func TestContextCheckPreconditions(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	etag := `"v2"`
+
+	tests := []struct {
+		name     string
+		method   string
+		headers  map[string]string
+		handled  bool
+		expected int
+	}{
+		{"no conditions", "GET", nil, false, 200},
+		{"if-none-match hit", "GET", map[string]string{"If-None-Match": `"v1", W/"v2"`}, true, NotModifiedStatus},
+		{"if-none-match miss", "GET", map[string]string{"If-None-Match": `"v1"`}, false, 200},
+		{"if-none-match on write", "PUT", map[string]string{"If-None-Match": "*"}, true, PreconditionFailedStatus},
+		{"if-modified-since not modified", "GET", map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:00:00 GMT"}, true, NotModifiedStatus},
+		{"if-modified-since rfc850", "GET", map[string]string{"If-Modified-Since": "Wednesday, 01-May-24 12:00:00 GMT"}, true, NotModifiedStatus},
+		{"if-modified-since asctime", "GET", map[string]string{"If-Modified-Since": "Wed May  1 12:00:00 2024"}, true, NotModifiedStatus},
+		{"if-modified-since modified", "GET", map[string]string{"If-Modified-Since": "Tue, 30 Apr 2024 12:00:00 GMT"}, false, 200},
+		{"if-none-match wins over date", "GET", map[string]string{"If-None-Match": `"v1"`, "If-Modified-Since": "Wed, 01 May 2024 12:00:00 GMT"}, false, 200},
+		{"if-match hit", "PUT", map[string]string{"If-Match": `"v2"`}, false, 200},
+		{"if-match miss", "PUT", map[string]string{"If-Match": `"v1"`}, true, PreconditionFailedStatus},
+		{"if-match weak", "PUT", map[string]string{"If-Match": `W/"v2"`}, true, PreconditionFailedStatus},
+		{"if-unmodified-since failed", "PUT", map[string]string{"If-Unmodified-Since": "Tue, 30 Apr 2024 12:00:00 GMT"}, true, PreconditionFailedStatus},
+	}
+
+	for _, tt := range tests {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.Header.SetMethod(tt.method)
+		for name, value := range tt.headers {
+			fastCtx.Request.Header.Set(name, value)
+		}
+		ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+		if handled := ctx.CheckPreconditions(etag, lastModified); handled != tt.handled {
+			t.Errorf("%s: expected handled %v, got %v", tt.name, tt.handled, handled)
+		}
+		if status := fastCtx.Response.StatusCode(); status != tt.expected {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.expected, status)
+		}
+		if got := string(fastCtx.Response.Header.Peek("ETag")); got != etag {
+			t.Errorf("%s: expected ETag header %s, got %s", tt.name, etag, got)
+		}
+	}
+}
+
+type signupRequest struct {
+	Email string `json:"email"`
+}
+
+func (r signupRequest) Validate() error {
+	if r.Email == "" {
+		return ValidationErrors{"email": "is required"}
+	}
+	return nil
+}
+
+func TestContextBindJSONValidated(t *testing.T) {
+	tests := []struct {
+		body   string
+		ok     bool
+		status int
+		code   string
+	}{
+		{`{"email":"a@b.c"}`, true, 200, ""},
+		{`{"email":`, false, BadRequestStatus, "invalid_json"},
+		{`{}`, false, UnprocessableEntityStatus, "validation_failed"},
+	}
+
+	for _, tt := range tests {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetBodyString(tt.body)
+		ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+		var req signupRequest
+		if ok := ctx.BindJSONValidated(&req); ok != tt.ok {
+			t.Errorf("Expected %v for body %s, got %v", tt.ok, tt.body, ok)
+		}
+		if status := fastCtx.Response.StatusCode(); status != tt.status {
+			t.Errorf("Expected status %d for body %s, got %d", tt.status, tt.body, status)
+		}
+		if tt.ok {
+			continue
+		}
+
+		var response ErrorResponse
+		if err := json.Unmarshal(fastCtx.Response.Body(), &response); err != nil {
+			t.Fatalf("Expected JSON error response, got %q", fastCtx.Response.Body())
+		}
+		if response.Code != tt.code {
+			t.Errorf("Expected code %q, got %q", tt.code, response.Code)
+		}
+	}
+
+	// A malformed tag is a server error
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.SetBodyString(`{"quantity":1}`)
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+	var bad struct {
+		Quantity int `json:"quantity" validate:"min=one"`
+	}
+	if ctx.BindJSONValidated(&bad) {
+		t.Error("Expected a malformed tag to fail")
+	}
+	if status := fastCtx.Response.StatusCode(); status != InternalServerErrorStatus {
+		t.Errorf("Expected 500 for a malformed tag, got %d", status)
+	}
+
+	// Custom error handler
+	fastCtx = &fasthttp.RequestCtx{}
+	fastCtx.Request.SetBodyString(`{}`)
+	ctx = NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+	var handled *HTTPError
+	ctx.errorHandler = func(ctx *Context, err error) {
+		errors.As(err, &handled)
+		ctx.Status(handled.Status).String("custom")
+	}
+
+	var req signupRequest
+	if ctx.BindJSONValidated(&req) {
+		t.Error("Expected validation to fail")
+	}
+	if handled == nil || handled.Details["email"] != "is required" {
+		t.Errorf("Expected field errors to reach the error handler, got %+v", handled)
+	}
+	if string(fastCtx.Response.Body()) != "custom" {
+		t.Errorf("Expected custom body, got %q", fastCtx.Response.Body())
+	}
+}
+
+func TestContextAddHeader(t *testing.T) {
+	fastCtx := &fasthttp.RequestCtx{}
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+	ctx.AddHeader("Link", "</a>; rel=preload").AddHeader("Link", "</b>; rel=preload")
+	ctx.AddHeader("Set-Cookie", "a=1").AddHeader("Set-Cookie", "b=2")
+	ctx.Header("X-Single", "1").Header("X-Single", "2")
+
+	if links := fastCtx.Response.Header.PeekAll("Link"); len(links) != 2 {
+		t.Errorf("Expected 2 Link headers, got %d", len(links))
+	}
+	if cookies := strings.Count(fastCtx.Response.Header.String(), "Set-Cookie:"); cookies != 2 {
+		t.Errorf("Expected 2 Set-Cookie headers, got %d", cookies)
+	}
+	if values := fastCtx.Response.Header.PeekAll("X-Single"); len(values) != 1 || string(values[0]) != "2" {
+		t.Errorf("Expected Header to replace the value, got %q", values)
+	}
+
+	ctx.DelHeader("Link").DelHeader("Set-Cookie")
+	if header := fastCtx.Response.Header.String(); strings.Contains(header, "Link:") || strings.Contains(header, "Set-Cookie:") {
+		t.Error("Expected DelHeader to remove all values")
+	}
+}
+
+func TestContextSetHeaders(t *testing.T) {
+	fastCtx := &fasthttp.RequestCtx{}
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+	ctx.Header("Cache-Control", "no-cache").AddHeader("Vary", "Accept")
+	result := ctx.SetHeaders(map[string]string{
+		"Cache-Control":          "public, max-age=60",
+		"X-Content-Type-Options": "nosniff",
+	}).AddHeader("Vary", "Origin")
+	if result != ctx {
+		t.Error("Expected SetHeaders to return the context for chaining")
+	}
+	ctx.AddHeader("Set-Cookie", "a=1").AddHeader("Set-Cookie", "b=2")
+
+	headers := ctx.Headers()
+	if values := headers.Values("Cache-Control"); len(values) != 1 || values[0] != "public, max-age=60" {
+		t.Errorf("Expected SetHeaders to replace Cache-Control, got %q", values)
+	}
+	if got := headers.Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("Expected X-Content-Type-Options, got %q", got)
+	}
+	if values := headers.Values("Vary"); len(values) != 2 {
+		t.Errorf("Expected both Vary values, got %q", values)
+	}
+	if values := headers.Values("Set-Cookie"); len(values) != 2 {
+		t.Errorf("Expected one value per cookie, got %q", values)
+	}
+
+	headers.Set("X-Content-Type-Options", "changed")
+	if got := string(fastCtx.Response.Header.Peek("X-Content-Type-Options")); got != "nosniff" {
+		t.Errorf("Expected Headers to return a copy, got %q", got)
+	}
+}
+
+func TestFeatureFlags(t *testing.T) {
+	flags := NewFeatureFlags()
+	flags.Load(map[string]Flag{
+		"static":  {Enabled: true},
+		"beta":    {Users: []string{"alice"}},
+		"rollout": {Percentage: 50},
+	})
+
+	c := container.NewContainer()
+	c.Register(FlagsService, flags)
+	newCtx := func(user string) *Context {
+		ctx := NewContext(&fasthttp.RequestCtx{}, c, make(map[string]Plugin))
+		if user != "" {
+			ctx.Set("user", user)
+		}
+		return ctx
+	}
+
+	if !newCtx("").FeatureEnabled("static") || newCtx("").FeatureEnabled("unknown") {
+		t.Error("Expected static flags to follow Enabled")
+	}
+	if !newCtx("alice").FeatureEnabled("beta") || newCtx("bob").FeatureEnabled("beta") {
+		t.Error("Expected beta to be enabled only for alice")
+	}
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		first := newCtx(user).FeatureEnabled("rollout")
+		if first != newCtx(user).FeatureEnabled("rollout") {
+			t.Fatalf("Expected stable rollout for %s", user)
+		}
+		if first {
+			enabled++
+		}
+	}
+	if enabled < 400 || enabled > 600 {
+		t.Errorf("Expected about half of the users in a 50%% rollout, got %d/1000", enabled)
+	}
+
+	flags.Toggle("beta", true)
+	if !newCtx("bob").FeatureEnabled("beta") {
+		t.Error("Expected toggled flag to be enabled for everyone")
+	}
+}
+
+func TestContextCredentials(t *testing.T) {
+	newCtx := func(authorization string) *Context {
+		fastCtx := &fasthttp.RequestCtx{}
+		if authorization != "" {
+			fastCtx.Request.Header.Set("Authorization", authorization)
+		}
+		fastCtx.Request.Header.Set("X-API-Key", "  key123 ")
+		return NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+	}
+
+	bearer := map[string]string{
+		"Bearer abc.def":     "abc.def",
+		"bearer   abc  ":     "abc",
+		"Bearer":             "",
+		"Bearer ":            "",
+		"Bearerabc":          "",
+		"Bearer a b":         "",
+		"Basic dXNlcjpwdw==": "",
+		"":                   "",
+	}
+	for header, expected := range bearer {
+		if token := newCtx(header).BearerToken(); token != expected {
+			t.Errorf("BearerToken for %q: expected %q, got %q", header, expected, token)
+		}
+	}
+
+	if user, pass, ok := newCtx("Basic dXNlcjpwOnc=").BasicAuth(); !ok || user != "user" || pass != "p:w" {
+		t.Errorf("Expected user/p:w, got %q/%q/%v", user, pass, ok)
+	}
+	for _, header := range []string{"Basic", "Basic !!!", "Basic dXNlcg==", "Bearer dXNlcjpwdw=="} {
+		if _, _, ok := newCtx(header).BasicAuth(); ok {
+			t.Errorf("Expected BasicAuth to fail for %q", header)
+		}
+	}
+
+	if key := newCtx("").APIKey("X-API-Key"); key != "key123" {
+		t.Errorf("Expected API key key123, got %q", key)
+	}
+}
+
+type tenantKey struct{}
+
+func TestRequestContextExtractor(t *testing.T) {
+	if ctx := NewContext(&fasthttp.RequestCtx{}, container.NewContainer(), make(map[string]Plugin)); ctx.Context() == nil {
+		t.Fatal("Expected a default Go context")
+	}
+
+	app := newTestApp(t)
+
+	var order []string
+	app.Pre(func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			if ctx.Context().Value(tenantKey{}) != nil {
+				order = append(order, "pre")
+			}
+			return next(ctx)
+		}
+	})
+	app.SetRequestContextExtractor(func(ctx *Context) context.Context {
+		order = append(order, "extractor")
+		return context.WithValue(ctx.Context(), tenantKey{}, ctx.GetHeader("X-Tenant-ID"))
+	})
+
+	var tenant interface{}
+	app.Get("/", func(ctx *Context) error {
+		tenant = ctx.Context().Value(tenantKey{})
+		return nil
+	})
+
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.SetRequestURI("/")
+	fastCtx.Request.Header.Set("X-Tenant-ID", "acme")
+	app.handleRequest(fastCtx)
+
+	if tenant != "acme" {
+		t.Errorf("Expected tenant acme in handler context, got %v", tenant)
+	}
+	if strings.Join(order, ",") != "extractor,pre" {
+		t.Errorf("Expected extractor to run before middleware, got %v", order)
+	}
+}
+
+func TestContextStatusCodeAndSize(t *testing.T) {
+	fastCtx := &fasthttp.RequestCtx{}
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+	if ctx.StatusCode() != 200 || ctx.ResponseSize() != 0 {
+		t.Errorf("Expected 200 and 0 bytes before writing, got %d and %d", ctx.StatusCode(), ctx.ResponseSize())
+	}
+
+	ctx.JSON(Map{"ok": true})
+	if ctx.StatusCode() != 200 {
+		t.Errorf("Expected default status 200, got %d", ctx.StatusCode())
+	}
+	if size := ctx.ResponseSize(); size != len(`{"ok":true}`+"\n") {
+		t.Errorf("Expected size of the JSON body, got %d", size)
+	}
+
+	ctx.Status(201).String("created")
+	if ctx.StatusCode() != 201 || ctx.ResponseSize() != 7 {
+		t.Errorf("Expected 201 and 7 bytes, got %d and %d", ctx.StatusCode(), ctx.ResponseSize())
+	}
+
+	fastCtx.SetBodyStream(strings.NewReader("streamed"), -1)
+	if size := ctx.ResponseSize(); size != -1 {
+		t.Errorf("Expected -1 for a stream of unknown length, got %d", size)
+	}
+}
+
+func TestContextBindJSONWithNumbers(t *testing.T) {
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.SetBodyString(`{"id": 9007199254740993, "price": 1.5}`)
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+	var body map[string]interface{}
+	if err := ctx.BindJSONWithNumbers(&body); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	id, ok := body["id"].(json.Number)
+	if !ok {
+		t.Fatalf("Expected json.Number, got %T", body["id"])
+	}
+	if n, err := id.Int64(); err != nil || n != 9007199254740993 {
+		t.Errorf("Expected exact ID 9007199254740993, got %d (%v)", n, err)
+	}
+	if price, _ := body["price"].(json.Number).Float64(); price != 1.5 {
+		t.Errorf("Expected price 1.5, got %v", price)
+	}
+
+	if err := ctx.BindJSON(&body); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := body["id"].(float64); !ok {
+		t.Errorf("Expected BindJSON to keep decoding float64, got %T", body["id"])
+	}
+}
+
+func TestContextProxy(t *testing.T) {
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+
+	var upstreamReq fasthttp.Request
+	upstream := &fasthttp.Server{Handler: func(ctx *fasthttp.RequestCtx) {
+		ctx.Request.CopyTo(&upstreamReq)
+		ctx.SetStatusCode(201)
+		ctx.Response.Header.Set("X-Upstream", "yes")
+		ctx.Response.Header.Set("Keep-Alive", "timeout=5")
+		ctx.SetBodyString("created")
+	}}
+	go upstream.Serve(ln)
+
+	client := NewProxyClient(DefaultProxyOptions())
+	client.Dial = func(addr string) (net.Conn, error) { return ln.Dial() }
+
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.Header.SetMethod("POST")
+	fastCtx.Request.SetRequestURI("/users?page=2")
+	fastCtx.Request.Header.SetHost("api.example.com")
+	fastCtx.Request.Header.Set("X-Forwarded-For", "203.0.113.7")
+	fastCtx.Request.Header.Set("Connection", "X-Internal")
+	fastCtx.Request.Header.Set("X-Internal", "secret")
+	fastCtx.Request.Header.Set("X-Request-ID", "abc")
+	fastCtx.Request.SetBodyString(`{"name":"gorgo"}`)
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+	if err := ctx.Proxy("http://users.internal"+ctx.Path()+"?page=2", client); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(upstreamReq.Header.Method()) != "POST" || string(upstreamReq.Body()) != `{"name":"gorgo"}` {
+		t.Errorf("Expected method and body to be forwarded, got %s %q", upstreamReq.Header.Method(), upstreamReq.Body())
+	}
+	if uri := string(upstreamReq.RequestURI()); uri != "/users?page=2" {
+		t.Errorf("Expected /users?page=2 upstream, got %s", uri)
+	}
+	if host := string(upstreamReq.Header.Host()); host != "users.internal" {
+		t.Errorf("Expected upstream Host header, got %s", host)
+	}
+	if got := string(upstreamReq.Header.Peek("X-Forwarded-For")); got != "203.0.113.7, 0.0.0.0" {
+		t.Errorf("Expected client IP appended to X-Forwarded-For, got %q", got)
+	}
+	if got := string(upstreamReq.Header.Peek("X-Forwarded-Host")); got != "api.example.com" {
+		t.Errorf("Expected X-Forwarded-Host api.example.com, got %q", got)
+	}
+	if len(upstreamReq.Header.Peek("X-Internal")) != 0 {
+		t.Error("Expected headers listed in Connection to be stripped")
+	}
+	if string(upstreamReq.Header.Peek("X-Request-ID")) != "abc" {
+		t.Error("Expected end-to-end headers to be forwarded")
+	}
+
+	if fastCtx.Response.StatusCode() != 201 || string(fastCtx.Response.Body()) != "created" {
+		t.Errorf("Expected upstream response, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+	if string(fastCtx.Response.Header.Peek("X-Upstream")) != "yes" {
+		t.Error("Expected upstream headers to be copied")
+	}
+	if len(fastCtx.Response.Header.Peek("Keep-Alive")) != 0 {
+		t.Error("Expected hop-by-hop response headers to be stripped")
+	}
+
+	// Unreachable upstream
+	client = NewProxyClient(DefaultProxyOptions())
+	client.Dial = func(addr string) (net.Conn, error) { return nil, errors.New("connection refused") }
+	fastCtx = &fasthttp.RequestCtx{}
+	ctx = NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+	ctx.Proxy("http://users.internal/", client)
+	if fastCtx.Response.StatusCode() != BadGatewayStatus {
+		t.Errorf("Expected 502, got %d", fastCtx.Response.StatusCode())
+	}
+}
+
+func TestContextPeekBody(t *testing.T) {
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.SetBodyStream(strings.NewReader(`{"amount":42}`), -1)
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+	peeked, err := ctx.PeekBody()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(peeked) != `{"amount":42}` {
+		t.Errorf("Expected streamed body, got %q", peeked)
+	}
+
+	// The handler still sees the body
+	var body struct{ Amount int }
+	if err := ctx.BindJSON(&body); err != nil || body.Amount != 42 {
+		t.Errorf("Expected body to stay readable, got %+v (%v)", body, err)
+	}
+	if again, _ := ctx.PeekBody(); string(again) != `{"amount":42}` {
+		t.Errorf("Expected repeated peeks to return the body, got %q", again)
+	}
+
+	fastCtx = &fasthttp.RequestCtx{}
+	fastCtx.Request.SetBodyStream(strings.NewReader("0123456789"), -1)
+	ctx = NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+	ctx.maxBodySize = 5
+	if _, err := ctx.PeekBody(); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("Expected ErrBodyTooLarge, got %v", err)
+	}
+}
+
+func TestContextBodyMap(t *testing.T) {
+	newCtx := func(body string, stream bool) *Context {
+		fastCtx := &fasthttp.RequestCtx{}
+		if stream {
+			fastCtx.Request.SetBodyStream(strings.NewReader(body), -1)
+		} else {
+			fastCtx.Request.SetBodyString(body)
+		}
+		return NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+	}
+
+	for _, stream := range []bool{false, true} {
+		ctx := newCtx(`{"name":"gorgo","stars":42}`, stream)
+		body, err := ctx.BodyMap()
+		if err != nil || body["name"] != "gorgo" || body["stars"] != 42.0 {
+			t.Errorf("stream=%v: expected the decoded object, got %v (%v)", stream, body, err)
+		}
+		if stream && ctx.BodyString() != `{"name":"gorgo","stars":42}` {
+			t.Errorf("expected the streamed body to stay readable, got %q", ctx.BodyString())
+		}
+
+		for _, invalid := range []string{`[1,2,3]`, `"text"`, `42`, `{"name":`} {
+			if _, err := newCtx(invalid, stream).BodyMap(); err == nil || !strings.Contains(err.Error(), "invalid JSON body") {
+				t.Errorf("stream=%v: expected an invalid JSON body error for %s, got %v", stream, invalid, err)
+			}
+		}
+	}
+
+	_, err := newCtx(`[1,2,3]`, false).BodyMap()
+	if httpErr := bodyReadError(err); httpErr.Status != BadRequestStatus || httpErr.Code != "invalid_body" {
+		t.Errorf("expected a non-object body to map to 400, got %d %s", httpErr.Status, httpErr.Code)
+	}
+
+	// Streamed bodies are limited to server.max_request_body_size
+	ctx := newCtx(`{"name":"`+strings.Repeat("a", 100)+`"}`, true)
+	ctx.maxBodySize = 64
+	_, err = ctx.BodyMap()
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+	if httpErr := bodyReadError(err); httpErr.Status != PayloadTooLargeStatus || httpErr.Code != "body_too_large" {
+		t.Errorf("expected the body limit to map to 413, got %d %s", httpErr.Status, httpErr.Code)
+	}
+
+	// Returned from a handler, the mapped error responds 413
+	app := newTestApp(t)
+	app.config.Server.MaxRequestBodySize = 64
+	app.Post("/items", func(ctx *Context) error {
+		if _, err := ctx.BodyMap(); err != nil {
+			return bodyReadError(err)
+		}
+		return ctx.String("ok")
+	})
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.Header.SetMethod("POST")
+	fastCtx.Request.SetRequestURI("/items")
+	fastCtx.Request.SetBodyStream(strings.NewReader(`{"name":"`+strings.Repeat("a", 100)+`"}`), -1)
+	app.handleRequest(fastCtx)
+	if fastCtx.Response.StatusCode() != PayloadTooLargeStatus {
+		t.Errorf("expected 413 for an oversized streamed body, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+}
+
+func TestContextJSONPretty(t *testing.T) {
+	fastCtx := &fasthttp.RequestCtx{}
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+	if err := ctx.JSONPretty(Map{"plugins": []string{"sql"}}, "  "); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "{\n  \"plugins\": [\n    \"sql\"\n  ]\n}\n"
+	if body := string(fastCtx.Response.Body()); body != expected {
+		t.Errorf("Expected indented JSON %q, got %q", expected, body)
+	}
+	if ct := string(fastCtx.Response.Header.ContentType()); ct != "application/json" {
+		t.Errorf("Expected application/json, got %s", ct)
+	}
+
+	if err := ctx.JSONPretty(Map{}, strings.Repeat(" ", 64)); err == nil {
+		t.Error("Expected an error for an excessive indent")
+	}
+	if err := ctx.JSONPretty(Map{}, "--"); err == nil {
+		t.Error("Expected an error for a non-whitespace indent")
+	}
+
+	// Large bodies are sent compact
+	defer func(size int) { MaxPrettyJSONSize = size }(MaxPrettyJSONSize)
+	MaxPrettyJSONSize = 10
+	ctx.JSONPretty(Map{"plugins": []string{"sql", "redis"}}, "\t")
+	if body := string(fastCtx.Response.Body()); body != `{"plugins":["sql","redis"]}`+"\n" {
+		t.Errorf("Expected compact JSON above the size limit, got %q", body)
+	}
+}
+
+func TestContextForwarded(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies failed: %v", err)
+	}
+	if _, err := ParseTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Error("Expected error for invalid CIDR")
+	}
+
+	newCtx := func(remoteIP string, headers map[string]string) *Context {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP(remoteIP), Port: 40000})
+		fastCtx.Request.SetRequestURI("/users")
+		fastCtx.Request.Header.SetHost("app.internal:8080")
+		for name, value := range headers {
+			fastCtx.Request.Header.Set(name, value)
+		}
+		ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+		ctx.trustedProxies = proxies
+		return ctx
+	}
+
+	forwarded := map[string]string{
+		"X-Forwarded-Host":  "api.example.com, app.internal",
+		"X-Forwarded-Proto": "https",
+		"X-Forwarded-Port":  "443",
+	}
+
+	tests := []struct {
+		name     string
+		remoteIP string
+		headers  map[string]string
+		host     string
+		proto    string
+		port     string
+		baseURL  string
+	}{
+		{"trusted proxy", "10.1.2.3", forwarded, "api.example.com", "https", "443", "https://api.example.com"},
+		{"untrusted client", "203.0.113.7", forwarded, "app.internal:8080", "http", "8080", "http://app.internal:8080"},
+		{"no headers", "127.0.0.1", nil, "app.internal:8080", "http", "8080", "http://app.internal:8080"},
+		{"non-default port", "10.1.2.3", map[string]string{"X-Forwarded-Host": "api.example.com", "X-Forwarded-Port": "8443", "X-Forwarded-Proto": "https"}, "api.example.com", "https", "8443", "https://api.example.com:8443"},
+		{"invalid host", "10.1.2.3", map[string]string{"X-Forwarded-Host": "evil.com/path"}, "app.internal:8080", "http", "8080", "http://app.internal:8080"},
+	}
+
+	for _, tt := range tests {
+		ctx := newCtx(tt.remoteIP, tt.headers)
+		if got := ctx.ForwardedHost(); got != tt.host {
+			t.Errorf("%s: expected host %q, got %q", tt.name, tt.host, got)
+		}
+		if got := ctx.ForwardedProto(); got != tt.proto {
+			t.Errorf("%s: expected proto %q, got %q", tt.name, tt.proto, got)
+		}
+		if got := ctx.ForwardedPort(); got != tt.port {
+			t.Errorf("%s: expected port %q, got %q", tt.name, tt.port, got)
+		}
+		if got := ctx.ExternalBaseURL(); got != tt.baseURL {
+			t.Errorf("%s: expected base URL %q, got %q", tt.name, tt.baseURL, got)
+		}
+	}
+}
+
+func TestContextContentLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		length  int
+		chunked bool
+		trailer string
+	}{
+		{"no body", "GET / HTTP/1.1\r\nHost: a\r\n\r\n", -1, false, ""},
+		{"content length", "POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 3\r\n\r\nabc", 3, false, ""},
+		{"empty body", "POST / HTTP/1.1\r\nHost: a\r\nContent-Length: 0\r\n\r\n", 0, false, ""},
+		{"chunked", "POST / HTTP/1.1\r\nHost: a\r\nTransfer-Encoding: chunked\r\nTrailer: X-Checksum\r\n\r\n3\r\nabc\r\n0\r\nX-Checksum: abc123\r\n\r\n", -1, true, "abc123"},
+	}
+
+	for _, tt := range tests {
+		fastCtx := &fasthttp.RequestCtx{}
+		if err := fastCtx.Request.Read(bufio.NewReader(strings.NewReader(tt.raw))); err != nil {
+			t.Fatalf("%s: failed to read request: %v", tt.name, err)
+		}
+		ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+		if got := ctx.ContentLength(); got != tt.length {
+			t.Errorf("%s: expected ContentLength %d, got %d", tt.name, tt.length, got)
+		}
+		if got := ctx.IsChunked(); got != tt.chunked {
+			t.Errorf("%s: expected IsChunked %v, got %v", tt.name, tt.chunked, got)
+		}
+		if got := ctx.RequestTrailer("x-checksum"); got != tt.trailer {
+			t.Errorf("%s: expected trailer %q, got %q", tt.name, tt.trailer, got)
+		}
+	}
+}
+
+type bindTarget struct {
+	XMLName struct{} `xml:"user" json:"-" form:"-"`
+	Name    string   `xml:"name" json:"name" form:"name"`
+	Age     int      `xml:"age" json:"age" form:"age"`
+	Admin   *bool    `xml:"admin" json:"admin" form:"admin"`
+	Tags    []string `xml:"tag" json:"tags" form:"tag"`
+}
+
+func TestContextBind(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json", "application/json; charset=utf-8", `{"name":"Ann","age":42,"admin":true,"tags":["a","b"]}`},
+		{"problem json", "application/vnd.api+json", `{"name":"Ann","age":42,"admin":true,"tags":["a","b"]}`},
+		{"xml", "application/xml", `<user><name>Ann</name><age>42</age><admin>true</admin><tag>a</tag><tag>b</tag></user>`},
+		{"text xml", "text/xml", `<user><name>Ann</name><age>42</age><admin>true</admin><tag>a</tag><tag>b</tag></user>`},
+		{"form", "application/x-www-form-urlencoded", "name=Ann&age=42&admin=true&tag=a&tag=b"},
+		{"multipart", "multipart/form-data; boundary=X", "--X\r\nContent-Disposition: form-data; name=\"name\"\r\n\r\nAnn\r\n--X\r\nContent-Disposition: form-data; name=\"age\"\r\n\r\n42\r\n--X\r\nContent-Disposition: form-data; name=\"admin\"\r\n\r\ntrue\r\n--X\r\nContent-Disposition: form-data; name=\"tag\"\r\n\r\na\r\n--X\r\nContent-Disposition: form-data; name=\"tag\"\r\n\r\nb\r\n--X--\r\n"},
+	}
+
+	newCtx := func(contentType, body string) *Context {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.Header.SetMethod("POST")
+		fastCtx.Request.Header.SetContentType(contentType)
+		fastCtx.Request.SetBodyString(body)
+		return NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+	}
+
+	for _, tt := range tests {
+		var target bindTarget
+		if err := newCtx(tt.contentType, tt.body).Bind(&target); err != nil {
+			t.Errorf("%s: Bind failed: %v", tt.name, err)
+			continue
+		}
+		if target.Name != "Ann" || target.Age != 42 || target.Admin == nil || !*target.Admin || strings.Join(target.Tags, ",") != "a,b" {
+			t.Errorf("%s: unexpected result %+v", tt.name, target)
+		}
+	}
+
+	var target bindTarget
+	if err := newCtx("text/plain", "Ann").Bind(&target); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Expected ErrUnsupportedMediaType, got %v", err)
+	}
+	if err := newCtx("", "").Bind(&target); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Expected ErrUnsupportedMediaType without Content-Type, got %v", err)
+	}
+	if err := newCtx("application/x-www-form-urlencoded", "age=old").Bind(&target); err == nil || !strings.Contains(err.Error(), "age") {
+		t.Errorf("Expected invalid form field error, got %v", err)
+	}
+}
+
+func TestContextNDJSON(t *testing.T) {
+	newCtx := func(body []byte, encoding string, stream bool) *Context {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.Header.SetMethod("POST")
+		if encoding != "" {
+			fastCtx.Request.Header.Set("Content-Encoding", encoding)
+		}
+		if stream {
+			fastCtx.Request.SetBodyStream(bytes.NewReader(body), -1)
+		} else {
+			fastCtx.Request.SetBody(body)
+		}
+		ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+		ctx.maxBodySize = 64
+		return ctx
+	}
+
+	collect := func(ctx *Context) ([]string, error) {
+		var ids []string
+		err := ctx.NDJSON(func(raw json.RawMessage) error {
+			var record struct{ ID string }
+			if err := json.Unmarshal(raw, &record); err != nil {
+				return err
+			}
+			if record.ID == "reject" {
+				return errors.New("rejected")
+			}
+			ids = append(ids, record.ID)
+			return nil
+		})
+		return ids, err
+	}
+
+	body := []byte("{\"id\":\"a\"}\n\n{\"id\":\"b\"}\r\n{\"id\":\"c\"}")
+	for _, stream := range []bool{false, true} {
+		ids, err := collect(newCtx(body, "", stream))
+		if err != nil || strings.Join(ids, ",") != "a,b,c" {
+			t.Errorf("stream=%v: expected a,b,c, got %v (%v)", stream, ids, err)
+		}
+	}
+
+	var gzipped bytes.Buffer
+	w := gzip.NewWriter(&gzipped)
+	w.Write(body)
+	w.Close()
+	if ids, err := collect(newCtx(gzipped.Bytes(), "gzip", true)); err != nil || len(ids) != 3 {
+		t.Errorf("Expected gzip body to be decoded, got %v (%v)", ids, err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		line int
+		is   error
+	}{
+		{"invalid record", "{\"id\":\"a\"}\n{\"id\":\n", 2, errInvalidNDJSONRecord},
+		{"handler error", "{\"id\":\"a\"}\n{\"id\":\"b\"}\n{\"id\":\"reject\"}\n", 3, nil},
+		{"too large", strings.Repeat("{\"id\":\"aaaaaaaa\"}\n", 5), 4, ErrBodyTooLarge},
+	}
+	for _, tt := range tests {
+		_, err := collect(newCtx([]byte(tt.body), "", true))
+		var ndjsonErr *NDJSONError
+		if !errors.As(err, &ndjsonErr) || ndjsonErr.Line != tt.line {
+			t.Errorf("%s: expected error on line %d, got %v", tt.name, tt.line, err)
+			continue
+		}
+		if tt.is != nil && !errors.Is(err, tt.is) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.is, err)
+		}
+	}
+}
+
+type validatedAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type validatedSignup struct {
+	Name     string            `json:"name" validate:"required,min=3,max=10"`
+	Email    string            `json:"email" validate:"required,email"`
+	Age      int               `json:"age" validate:"min=18,max=130"`
+	Nickname string            `json:"nickname,omitempty" validate:"min=2"`
+	Tags     []string          `json:"tags" validate:"max=2"`
+	Referrer *string           `json:"referrer" validate:"required"`
+	Address  *validatedAddress `json:"address"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	referrer := "ads"
+	valid := validatedSignup{Name: "Ann", Email: "ann@example.com", Age: 30, Referrer: &referrer, Address: &validatedAddress{City: "Oslo"}}
+	if err := ValidateStruct(&valid); err != nil {
+		t.Fatalf("Expected valid struct, got %v", err)
+	}
+
+	invalid := validatedSignup{
+		Name:     "Al",
+		Email:    "Ann <ann@example.com>",
+		Age:      12,
+		Nickname: "x",
+		Tags:     []string{"a", "b", "c"},
+		Address:  &validatedAddress{},
+	}
+	err := ValidateStruct(invalid)
+	var fields ValidationErrors
+	if !errors.As(err, &fields) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	expected := ValidationErrors{
+		"name":         "must have at least 3 characters",
+		"email":        "must be a valid email address",
+		"age":          "must be at least 18",
+		"nickname":     "must have at least 2 characters",
+		"tags":         "must have at most 2 items",
+		"referrer":     "is required",
+		"address.city": "is required",
+	}
+	if fmt.Sprint(fields) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, fields)
+	}
+
+	// Numbers are checked even when zero, pointers make them optional
+	type order struct {
+		Quantity int      `json:"quantity" validate:"min=1"`
+		Discount int      `json:"discount" validate:"max=-1"`
+		Tip      *float64 `json:"tip" validate:"min=1"`
+		Note     string   `json:"note" validate:"min=5"`
+	}
+	err = ValidateStruct(order{})
+	expected = ValidationErrors{"quantity": "must be at least 1", "discount": "must be at most -1"}
+	if !errors.As(err, &fields) || fmt.Sprint(fields) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v for zero numbers, got %v", expected, err)
+	}
+	tip := 0.0
+	err = ValidateStruct(order{Quantity: 1, Discount: -1, Tip: &tip})
+	if !errors.As(err, &fields) || fmt.Sprint(fields) != fmt.Sprint(ValidationErrors{"tip": "must be at least 1"}) {
+		t.Errorf("Expected a set pointer to be checked, got %v", err)
+	}
+
+	// Tag mistakes are reported up front, even for empty fields
+	type unknownRule struct {
+		Name string `validate:"uppercase"`
+	}
+	type badMin struct {
+		Age int `validate:"min=ten"`
+	}
+	type emailOnInt struct {
+		ID int `validate:"email"`
+	}
+	type nestedBadTag struct {
+		Name   string       `validate:"required"`
+		Nested *unknownRule `json:"nested"`
+	}
+	for _, v := range []interface{}{unknownRule{}, badMin{}, emailOnInt{}, nestedBadTag{}} {
+		var tagErr *ValidationTagError
+		if err := ValidateStruct(v); !errors.As(err, &tagErr) {
+			t.Errorf("%T: expected a ValidationTagError, got %v", v, err)
+		}
+	}
+
+	type node struct {
+		Name string `json:"name" validate:"required"`
+		Next *node  `json:"next"`
+	}
+	err = ValidateStruct(node{Name: "a", Next: &node{Next: &node{Name: "c"}}})
+	if !errors.As(err, &fields) || fmt.Sprint(fields) != fmt.Sprint(ValidationErrors{"next.name": "is required"}) {
+		t.Errorf("Expected recursive types to validate, got %v", err)
+	}
+
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.SetBodyString(`{"name":"Ann","email":"not-an-email","age":5,"referrer":"ads"}`)
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+	var req validatedSignup
+	if err := ctx.BindAndValidate(&req); !errors.As(err, &fields) || len(fields) != 2 || fields["email"] == "" || fields["age"] == "" {
+		t.Errorf("Expected email and age violations, got %v", err)
+	}
+}
+
+func TestContextPoolReset(t *testing.T) {
+	app := newTestApp(t)
+	app.Get("/items/:id", func(ctx *Context) error {
+		if _, seen := ctx.Get("seen"); seen || len(ctx.Params()) != 1 || ctx.IsAborted() {
+			return ctx.String("stale")
+		}
+		ctx.Set("seen", true)
+		ctx.SetParam("extra", "1")
+		ctx.Abort()
+		return ctx.String("fresh " + ctx.Param("id"))
+	})
+
+	for i := 0; i < 3; i++ {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(fmt.Sprintf("/items/%d", i))
+		app.handleRequest(fastCtx)
+
+		if body := string(fastCtx.Response.Body()); body != fmt.Sprintf("fresh %d", i) {
+			t.Errorf("request %d: expected a reset context, got %q", i, body)
+		}
+	}
+
+	ctx := acquireContext(&fasthttp.RequestCtx{}, app.container, nil)
+	ctx.route = "/items/:id"
+	ctx.SetParam("id", "1")
+	ctx.Set("user", "alice")
+	releaseContext(ctx)
+	if ctx.fastCtx != nil || ctx.route != "" || len(ctx.params) != 0 || len(ctx.data) != 0 {
+		t.Error("expected released context to be cleared")
+	}
+}
+
+// recordingCodec counts calls and delegates to encoding/json
+type recordingCodec struct {
+	StdJSONCodec
+	calls *int
+}
+
+func (c recordingCodec) Unmarshal(data []byte, v interface{}) error {
+	*c.calls++
+	return c.StdJSONCodec.Unmarshal(data, v)
+}
+
+func (c recordingCodec) NewEncoder(w io.Writer) JSONEncoder {
+	*c.calls++
+	return c.StdJSONCodec.NewEncoder(w)
+}
+
+func TestSetJSONCodec(t *testing.T) {
+	calls := 0
+	SetJSONCodec(recordingCodec{calls: &calls})
+	t.Cleanup(func() { SetJSONCodec(nil) })
+
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.SetBodyString(`{"name":"gorgo"}`)
+	ctx := NewContext(fastCtx, container.NewContainer(), nil)
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := ctx.BindJSON(&body); err != nil || body.Name != "gorgo" {
+		t.Fatalf("BindJSON = %v, %+v", err, body)
+	}
+	if err := ctx.JSON(Map{"name": body.Name}); err != nil {
+		t.Fatalf("JSON failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the codec to be used twice, got %d", calls)
+	}
+	if got := strings.TrimSpace(string(fastCtx.Response.Body())); got != `{"name":"gorgo"}` {
+		t.Errorf("unexpected body %q", got)
+	}
+
+	SetJSONCodec(nil)
+	if _, ok := GetJSONCodec().(StdJSONCodec); !ok {
+		t.Errorf("expected SetJSONCodec(nil) to restore encoding/json, got %T", GetJSONCodec())
+	}
+}
+
+func TestContextStream(t *testing.T) {
+	app := newTestApp(t)
+	next := make(chan struct{})
+	app.Get("/export", func(ctx *Context) error {
+		ctx.Header("Content-Type", "text/csv")
+		return ctx.Stream(func(w io.Writer) error {
+			for i := 1; i <= 3; i++ {
+				if _, err := fmt.Fprintf(w, "row %d\n", i); err != nil {
+					return err
+				}
+				if i < 3 {
+					<-next
+				}
+			}
+			return nil
+		})
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go (&fasthttp.Server{Handler: app.handleRequest}).Serve(ln)
+
+	conn, err := ln.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("GET /export HTTP/1.1\r\nHost: example.com\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(conn)
+	var header fasthttp.ResponseHeader
+	if err := header.Read(reader); err != nil {
+		t.Fatalf("failed to read headers: %v", err)
+	}
+	if string(header.ContentType()) != "text/csv" || header.ContentLength() != -1 {
+		t.Errorf("expected a chunked text/csv response, got %q", header.String())
+	}
+
+	readChunk := func() string {
+		var size int
+		if _, err := fmt.Fscanf(reader, "%x\r\n", &size); err != nil {
+			t.Fatalf("failed to read chunk size: %v", err)
+		}
+		chunk := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			t.Fatalf("failed to read chunk: %v", err)
+		}
+		return string(chunk[:size])
+	}
+
+	// Each row arrives before the next one is written
+	for i := 1; i <= 3; i++ {
+		if chunk := readChunk(); chunk != fmt.Sprintf("row %d\n", i) {
+			t.Fatalf("expected row %d, got %q", i, chunk)
+		}
+		if i < 3 {
+			next <- struct{}{}
+		}
+	}
+	if chunk := readChunk(); chunk != "" {
+		t.Errorf("expected the final empty chunk, got %q", chunk)
+	}
+}
+
+func TestContextNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		offers []string
+		want   string
+	}{
+		{"", []string{"application/json", "text/html"}, "application/json"},
+		{"text/html", []string{"application/json", "text/html"}, "text/html"},
+		{"*/*", []string{"application/xml", "application/json"}, "application/xml"},
+		{"application/json;q=0.5, application/xml", []string{"application/json", "application/xml"}, "application/xml"},
+		{"text/*;q=0.8, application/json;q=0.9", []string{"text/html", "application/json"}, "application/json"},
+		{"text/*, text/plain;q=0", []string{"text/plain", "text/html"}, "text/html"},
+		{"application/json;q=0.9, */*;q=0.1", []string{"text/csv", "application/json"}, "application/json"},
+		{"image/png", []string{"application/json", "application/xml"}, "application/json"},
+		{"TEXT/HTML; Q=0.7; level=1, bogus", []string{"application/json", "text/html"}, "text/html"},
+		{"application/json", nil, ""},
+	}
+
+	for _, tt := range tests {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.Header.Set("Accept", tt.accept)
+		ctx := NewContext(fastCtx, container.NewContainer(), nil)
+		if got := ctx.Negotiate(tt.offers...); got != tt.want {
+			t.Errorf("Accept %q with offers %v: expected %q, got %q", tt.accept, tt.offers, tt.want, got)
+		}
+	}
+}
+
+func TestContextRender(t *testing.T) {
+	type user struct {
+		XMLName xml.Name `json:"-" xml:"user"`
+		ID      int      `json:"id" xml:"id"`
+		Name    string   `json:"name" xml:"name"`
+	}
+
+	render := func(accept string, data interface{}) *fasthttp.RequestCtx {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.Header.Set("Accept", accept)
+		if err := NewContext(fastCtx, container.NewContainer(), nil).Render(data); err != nil {
+			t.Fatalf("Render(%q) failed: %v", accept, err)
+		}
+		return fastCtx
+	}
+
+	tests := []struct {
+		accept      string
+		data        interface{}
+		contentType string
+		body        string
+	}{
+		{"", user{ID: 1, Name: "Ann"}, "application/json", `{"id":1,"name":"Ann"}` + "\n"},
+		{"application/xml", user{ID: 1, Name: "Ann"}, "application/xml", xml.Header + "<user><id>1</id><name>Ann</name></user>"},
+		{"text/xml, application/json;q=0.5", Map{"b": 2, "a": Map{"c": "x"}}, "text/xml", xml.Header + "<response><a><c>x</c></a><b>2</b></response>"},
+		{"text/plain", 42, "text/plain; charset=utf-8", "42"},
+		{"image/png", Map{"ok": true}, "application/json", `{"ok":true}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		fastCtx := render(tt.accept, tt.data)
+		if contentType := string(fastCtx.Response.Header.ContentType()); contentType != tt.contentType {
+			t.Errorf("Accept %q: expected content type %q, got %q", tt.accept, tt.contentType, contentType)
+		}
+		if body := string(fastCtx.Response.Body()); body != tt.body {
+			t.Errorf("Accept %q: expected body %q, got %q", tt.accept, tt.body, body)
+		}
+		if vary := string(fastCtx.Response.Header.Peek("Vary")); vary != "Accept" {
+			t.Errorf("Accept %q: expected Vary: Accept, got %q", tt.accept, vary)
+		}
+	}
+}
+
+func TestFormatSSE(t *testing.T) {
+	tests := []struct {
+		event SSEvent
+		want  string
+	}{
+		{SSEvent{Data: "hello"}, "data: hello\n\n"},
+		{SSEvent{ID: "7", Event: "update", Data: "a\nb"}, "id: 7\nevent: update\ndata: a\ndata: b\n\n"},
+		{SSEvent{Data: Map{"n": 1}, Retry: 3 * time.Second}, "retry: 3000\ndata: {\"n\":1}\n\n"},
+		{SSEvent{ID: "1\nevent: x", Data: []byte("raw")}, "id: 1event: x\ndata: raw\n\n"},
+	}
+	for _, tt := range tests {
+		got, err := formatSSE(tt.event)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("formatSSE(%+v) = %q, %v, want %q", tt.event, got, err, tt.want)
+		}
+	}
+}
+
+func TestContextSSEStream(t *testing.T) {
+	defer func(interval time.Duration) { SSEKeepAliveInterval = interval }(SSEKeepAliveInterval)
+	SSEKeepAliveInterval = 20 * time.Millisecond
+
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.Header.Set("Last-Event-ID", "41")
+	ctx := NewContext(fastCtx, container.NewContainer(), nil)
+	if id := ctx.LastEventID(); id != "41" {
+		t.Errorf("expected Last-Event-ID 41, got %q", id)
+	}
+
+	events := make(chan SSEvent)
+	go func() {
+		defer close(events)
+		events <- SSEvent{ID: "42", Data: "first"}
+		time.Sleep(50 * time.Millisecond) // long enough for a keep-alive
+		events <- SSEvent{ID: "43", Data: "second"}
+	}()
+	err := ctx.SSEStream(context.Background(), events)
+	if err != nil {
+		t.Fatalf("SSEStream failed: %v", err)
+	}
+	if ct := string(fastCtx.Response.Header.ContentType()); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", ct)
+	}
+
+	body := string(fastCtx.Response.Body())
+	if !strings.Contains(body, "id: 42\ndata: first\n\n") || !strings.Contains(body, "id: 43\ndata: second\n\n") {
+		t.Errorf("missing events in %q", body)
+	}
+	if !strings.Contains(body, ": keep-alive\n\n") {
+		t.Errorf("expected a keep-alive comment in %q", body)
+	}
+
+	// Canceling the context ends the stream even without events
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- writeSSE(cancelCtx, io.Discard, make(chan SSEvent)) }()
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("expected a clean end on cancel, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream did not end when the context was canceled")
+	}
+}
+
+func TestContextSSEStreamClientDisconnect(t *testing.T) {
+	defer func(interval time.Duration) { SSEKeepAliveInterval = interval }(SSEKeepAliveInterval)
+	SSEKeepAliveInterval = 20 * time.Millisecond
+
+	app := newTestApp(t)
+	events := make(chan SSEvent)
+	app.Get("/feed", func(ctx *Context) error {
+		return ctx.SSEStream(context.Background(), events)
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go (&fasthttp.Server{Handler: app.handleRequest}).Serve(ln)
+
+	conn, err := ln.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("GET /feed HTTP/1.1\r\nHost: example.com\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	go func() { events <- SSEvent{Data: "tick"} }()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read the stream: %v", err)
+		}
+		if strings.Contains(line, "data: tick") {
+			break
+		}
+	}
+	conn.Close()
+
+	// Once the stream has ended, nothing reads the channel anymore
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case events <- SSEvent{Data: "tick"}:
+		case <-time.After(100 * time.Millisecond):
+			return
+		case <-deadline:
+			t.Fatal("stream kept reading events after the client disconnected")
+		}
+	}
+}
+
+func TestContextSSEStreamFuncClientDisconnect(t *testing.T) {
+	defer func(interval time.Duration) { SSEKeepAliveInterval = interval }(SSEKeepAliveInterval)
+	SSEKeepAliveInterval = 20 * time.Millisecond
+
+	app := newTestApp(t)
+	producerDone := make(chan struct{})
+	app.Get("/feed", func(ctx *Context) error {
+		// The request context is only canceled at shutdown
+		return ctx.SSEStreamFunc(context.Background(), func(ctx context.Context, events chan<- SSEvent) {
+			defer close(producerDone)
+			for i := 0; ; i++ {
+				select {
+				case events <- SSEvent{Data: fmt.Sprintf("tick %d", i)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	defer ln.Close()
+	go (&fasthttp.Server{Handler: app.handleRequest}).Serve(ln)
+
+	conn, err := ln.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("GET /feed HTTP/1.1\r\nHost: example.com\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read the stream: %v", err)
+		}
+		if strings.Contains(line, "data: tick") {
+			break
+		}
+	}
+	conn.Close()
+
+	select {
+	case <-producerDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("producer kept running after the client disconnected")
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header string
+		ranges []HTTPRange
+		err    error
+	}{
+		{"", nil, nil},
+		{"bytes=0-4", []HTTPRange{{0, 5}}, nil},
+		{"bytes=5-", []HTTPRange{{5, 5}}, nil},
+		{"bytes=-3", []HTTPRange{{7, 3}}, nil},
+		{"bytes=-20", []HTTPRange{{0, 10}}, nil},
+		{"bytes=8-20", []HTTPRange{{8, 2}}, nil},
+		{"bytes=0-1, 4-5", []HTTPRange{{0, 2}, {4, 2}}, nil},
+		{"bytes=0-1,20-30", []HTTPRange{{0, 2}}, nil},
+		{"bytes=10-", nil, ErrRangeNotSatisfiable},
+		{"bytes=-0", nil, ErrRangeNotSatisfiable},
+		{"items=0-4", nil, ErrInvalidRange},
+		{"bytes=4-1", nil, ErrInvalidRange},
+		{"bytes=a-b", nil, ErrInvalidRange},
+		{"bytes=5", nil, ErrInvalidRange},
+		{"bytes=", nil, ErrInvalidRange},
+	}
+	for _, tt := range tests {
+		ranges, err := parseRange(tt.header, 10)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%q: expected error %v, got %v", tt.header, tt.err, err)
+		}
+		if fmt.Sprint(ranges) != fmt.Sprint(tt.ranges) {
+			t.Errorf("%q: expected %v, got %v", tt.header, tt.ranges, ranges)
+		}
+	}
+
+	if contentRange := (HTTPRange{Start: 5, Length: 5}).ContentRange(10); contentRange != "bytes 5-9/10" {
+		t.Errorf("unexpected Content-Range %q", contentRange)
+	}
+}
+
+func TestContextSendRange(t *testing.T) {
+	content := []byte("0123456789")
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	request := func(method string, headers map[string]string) *fasthttp.RequestCtx {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.Header.SetMethod(method)
+		for key, value := range headers {
+			fastCtx.Request.Header.Set(key, value)
+		}
+		ctx := NewContext(fastCtx, container.NewContainer(), nil)
+		ctx.Header("ETag", `"v1"`)
+		ctx.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+		if err := ctx.SendRange(bytes.NewReader(content), int64(len(content))); err != nil {
+			t.Fatalf("SendRange failed: %v", err)
+		}
+		return fastCtx
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		headers      map[string]string
+		status       int
+		contentRange string
+		body         string
+	}{
+		{"full", "GET", nil, OKStatus, "", "0123456789"},
+		{"single range", "GET", map[string]string{"Range": "bytes=2-5"}, PartialContentStatus, "bytes 2-5/10", "2345"},
+		{"suffix range", "GET", map[string]string{"Range": "bytes=-3"}, PartialContentStatus, "bytes 7-9/10", "789"},
+		{"multiple ranges", "GET", map[string]string{"Range": "bytes=0-1,4-5"}, OKStatus, "", "0123456789"},
+		{"malformed range", "GET", map[string]string{"Range": "bytes=x"}, OKStatus, "", "0123456789"},
+		{"unsatisfiable", "GET", map[string]string{"Range": "bytes=20-"}, RangeNotSatisfiableStatus, "bytes */10", ""},
+		{"not GET", "POST", map[string]string{"Range": "bytes=2-5"}, OKStatus, "", "0123456789"},
+		{"If-Range ETag", "GET", map[string]string{"Range": "bytes=2-5", "If-Range": `"v1"`}, PartialContentStatus, "bytes 2-5/10", "2345"},
+		{"If-Range stale ETag", "GET", map[string]string{"Range": "bytes=2-5", "If-Range": `"v0"`}, OKStatus, "", "0123456789"},
+		{"If-Range weak ETag", "GET", map[string]string{"Range": "bytes=2-5", "If-Range": `W/"v1"`}, OKStatus, "", "0123456789"},
+		{"If-Range date", "GET", map[string]string{"Range": "bytes=2-5", "If-Range": lastModified.Format(http.TimeFormat)}, PartialContentStatus, "bytes 2-5/10", "2345"},
+		{"If-Range stale date", "GET", map[string]string{"Range": "bytes=2-5", "If-Range": lastModified.Add(-time.Hour).Format(http.TimeFormat)}, OKStatus, "", "0123456789"},
+	}
+	for _, tt := range tests {
+		fastCtx := request(tt.method, tt.headers)
+		if fastCtx.Response.StatusCode() != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, fastCtx.Response.StatusCode())
+		}
+		if acceptRanges := string(fastCtx.Response.Header.Peek("Accept-Ranges")); acceptRanges != "bytes" {
+			t.Errorf("%s: expected Accept-Ranges: bytes, got %q", tt.name, acceptRanges)
+		}
+		if contentRange := string(fastCtx.Response.Header.Peek("Content-Range")); contentRange != tt.contentRange {
+			t.Errorf("%s: expected Content-Range %q, got %q", tt.name, tt.contentRange, contentRange)
+		}
+		if tt.status == RangeNotSatisfiableStatus {
+			continue
+		}
+		if body := string(fastCtx.Response.Body()); body != tt.body {
+			t.Errorf("%s: expected body %q, got %q", tt.name, tt.body, body)
+		}
+		if fastCtx.Response.Header.ContentLength() != len(tt.body) {
+			t.Errorf("%s: expected Content-Length %d, got %d", tt.name, len(tt.body), fastCtx.Response.Header.ContentLength())
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package gorgo
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// BearerToken returns the token of an "Authorization: Bearer <token>"
+// header. The scheme is matched case-insensitively and surrounding spaces
+// are ignored. It returns "" when the header is missing or malformed.
+func (c *Context) BearerToken() string {
+	token, ok := c.authorization("Bearer")
+	if !ok || strings.ContainsAny(token, " \t") {
+		return ""
+	}
+	return token
+}
+
+// APIKey returns the API key sent in the given header, e.g. "X-API-Key",
+// trimmed of surrounding spaces
+func (c *Context) APIKey(headerName string) string {
+	return strings.TrimSpace(c.GetHeader(headerName))
+}
+
+// BasicAuth returns the credentials of an "Authorization: Basic" header.
+// ok is false when the header is missing or malformed.
+func (c *Context) BasicAuth() (user, pass string, ok bool) {
+	encoded, ok := c.authorization("Basic")
+	if !ok {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", false
+	}
+
+	user, pass, ok = strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", false
+	}
+	return user, pass, true
+}
+
+// authorization returns the credentials of the Authorization header if it
+// uses the given scheme
+func (c *Context) authorization(scheme string) (string, bool) {
+	header := strings.TrimSpace(c.GetHeader("Authorization"))
+	if len(header) <= len(scheme) || !strings.EqualFold(header[:len(scheme)], scheme) {
+		return "", false
+	}
+
+	rest := header[len(scheme):]
+	if rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+
+	credentials := strings.TrimSpace(rest)
+	return credentials, credentials != ""
+}
@@ -0,0 +1,141 @@
+package gorgo
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// Defaults for the request event dispatcher
+const (
+	DefaultEventBufferSize = 1024
+	DefaultEventWorkers    = 4
+)
+
+// queuedEvent is an event waiting for a dispatcher worker
+type queuedEvent struct {
+	ctx     context.Context
+	name    string
+	data    map[string]interface{}
+	payload interface{}
+	// async events keep being asynchronous after shutdown
+	async bool
+}
+
+// eventDispatcher publishes events on a pool of workers, so the request
+// goroutine does not wait for subscribers. When the buffer is full, events
+// are dropped and counted instead of blocking.
+type eventDispatcher struct {
+	bus     *EventBus
+	metrics func() Metrics
+
+	mu     sync.RWMutex
+	queue  chan queuedEvent
+	closed bool
+	wg     sync.WaitGroup
+
+	dropped atomic.Int64
+}
+
+func newEventDispatcher(bus *EventBus, metrics func() Metrics, bufferSize, workers int) *eventDispatcher {
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBufferSize
+	}
+	if workers <= 0 {
+		workers = DefaultEventWorkers
+	}
+
+	d := &eventDispatcher{
+		bus:     bus,
+		metrics: metrics,
+		queue:   make(chan queuedEvent, bufferSize),
+	}
+
+	for i := 0; i < workers; i++ {
+		d.wg.Add(1)
+		Go(d.work)
+	}
+	bus.dispatcher.Store(d)
+	return d
+}
+
+// dispatchTyped queues a typed event
+func (d *eventDispatcher) dispatchTyped(ctx context.Context, event TypedEvent) {
+	d.dispatch(queuedEvent{ctx: ctx, name: event.EventName(), data: eventData(event), payload: event})
+}
+
+func (d *eventDispatcher) dispatch(event queuedEvent) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	// Events after shutdown are published synchronously, PublishAsync
+	// events in their own goroutine
+	if d.closed {
+		if event.async {
+			Go(func() { d.bus.publishQueued(event) })
+			return
+		}
+		d.bus.publish(event.ctx, event.name, event.data, event.payload)
+		return
+	}
+
+	select {
+	case d.queue <- event:
+	default:
+		dropped := d.dropped.Add(1)
+		d.metrics().Counter("gorgo_events_dropped_total", 1, Labels{"event": event.name})
+		// Log the first drop and then every 1000th to avoid flooding the log
+		if dropped == 1 || dropped%1000 == 0 {
+			log.Printf("Event buffer full, dropped %d events so far (latest: %s)", dropped, event.name)
+		}
+	}
+}
+
+func (d *eventDispatcher) work() {
+	defer d.wg.Done()
+	for event := range d.queue {
+		d.publish(event)
+	}
+}
+
+// publish runs the subscribers, recovering panics so the worker survives
+func (d *eventDispatcher) publish(event queuedEvent) {
+	defer recoverGoroutine()
+	d.bus.publishQueued(event)
+}
+
+// DroppedEvents returns the number of request events dropped because the
+// event buffer was full
+func (a *Application) DroppedEvents() int64 {
+	if a.events == nil {
+		return 0
+	}
+	return a.events.dropped.Load()
+}
+
+// close stops accepting events and waits until the queued events are
+// published or ctx is done
+func (d *eventDispatcher) close(ctx context.Context) {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+	d.bus.dispatcher.CompareAndSwap(d, nil)
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("Event dispatcher: %d events not published before shutdown", len(d.queue))
+	}
+}
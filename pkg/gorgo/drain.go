@@ -0,0 +1,63 @@
+package gorgo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// DefaultDrainSignal is the signal that starts draining unless
+// server.drain_signal is set
+const DefaultDrainSignal = "SIGUSR1"
+
+// SetDraining switches draining mode. While draining, the application keeps
+// serving every request but Ready reports false, so readiness probes fail
+// and load balancers deregister the instance, and keep-alive connections
+// are closed after their current response so clients reconnect elsewhere.
+// Draining starts on the drain signal (SIGUSR1 by default).
+func (a *Application) SetDraining(draining bool) *Application {
+	if a.draining.Swap(draining) == draining {
+		return a
+	}
+
+	if draining {
+		log.Println("Draining: readiness fails, still serving requests")
+	} else {
+		log.Println("Draining stopped: readiness restored")
+	}
+	a.pluginManager.GetEventBus().Publish(context.Background(), "app.draining", map[string]interface{}{
+		"draining": draining,
+	})
+	return a
+}
+
+// Draining reports whether the application is draining
+func (a *Application) Draining() bool {
+	return a.draining.Load()
+}
+
+// drainSignal returns the configured drain signal, nil when disabled
+func (a *Application) drainSignal() (os.Signal, error) {
+	name := strings.ToUpper(strings.TrimSpace(a.config.Server.DrainSignal))
+	switch name {
+	case "":
+		name = DefaultDrainSignal
+	case "NONE", "OFF":
+		return nil, nil
+	}
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+
+	signal, ok := drainSignals[name]
+	if !ok && a.config.Server.DrainSignal == "" {
+		// The default signal doesn't exist on this platform
+		return nil, nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("unsupported drain signal %q", a.config.Server.DrainSignal)
+	}
+	return signal, nil
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package gorgo
+
+import (
+	"os"
+	"syscall"
+)
+
+// drainSignals are the signals server.drain_signal may name
+var drainSignals = map[string]os.Signal{
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+	"SIGHUP":  syscall.SIGHUP,
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package gorgo
+
+import "os"
+
+// drainSignals is empty: Windows has no user signals, use SetDraining
+var drainSignals = map[string]os.Signal{}
@@ -0,0 +1,151 @@
+package gorgo
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrBodyTooLarge is returned when the request body exceeds the configured limit
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// ErrNotFound is returned by data access helpers when a lookup matches
+// nothing, e.g. the SQL plugin's QueryRow
+var ErrNotFound = errors.New("not found")
+
+// ErrUnsupportedMediaType is returned by ctx.Bind for a Content-Type it
+// can't decode; respond with 415
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// ErrorResponse is the standard JSON body for API errors
+type ErrorResponse struct {
+	Code    string                 `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Error writes a standard ErrorResponse with the given status code.
+// Optional details (e.g. field-level validation messages) are merged
+// into the Details map of the response. With ErrorFormatProblemJSON a
+// problem document is written instead.
+func (c *Context) Error(status int, code, message string, details ...map[string]interface{}) error {
+	response := ErrorResponse{
+		Code:    code,
+		Message: message,
+	}
+
+	for _, d := range details {
+		if response.Details == nil {
+			response.Details = make(map[string]interface{}, len(d))
+		}
+		for k, v := range d {
+			response.Details[k] = v
+		}
+	}
+
+	if c.errorFormat == ErrorFormatProblemJSON {
+		return c.Problem(errorProblem(response, status))
+	}
+	c.fastCtx.SetStatusCode(status)
+	return c.writeJSON(response)
+}
+
+// HTTPError is an error that carries the status code and ErrorResponse
+// fields of the response it should produce
+type HTTPError struct {
+	Status  int
+	Code    string
+	Message string
+	Details map[string]interface{}
+	// Type is a URI identifying the problem type, written as the "type"
+	// member with ErrorFormatProblemJSON
+	Type string
+	// Err is the underlying cause, if any
+	Err error
+}
+
+// NewHTTPError creates an HTTPError
+func NewHTTPError(status int, code, message string) *HTTPError {
+	return &HTTPError{Status: status, Code: code, Message: message}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// writeError responds with err through the application's error handler,
+// falling back to a standard ErrorResponse
+func (c *Context) writeError(err error) error {
+	if c.runErrorHandler(err) {
+		return nil
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return c.writeHTTPError(httpErr)
+	}
+	return c.Error(InternalServerErrorStatus, "internal_error", "Internal Server Error")
+}
+
+// writeUnhandledError writes the default response for an error returned
+// by a handler that the error handler didn't take care of. An HTTPError
+// keeps its status and code, any other error gets the default 500.
+func (c *Context) writeUnhandledError(err error) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		c.fastCtx.Response.ResetBody()
+		c.writeHTTPError(httpErr)
+		return
+	}
+	c.writeInternalError()
+}
+
+// writeHTTPError writes err in the configured error format
+func (c *Context) writeHTTPError(err *HTTPError) error {
+	if c.errorFormat == ErrorFormatProblemJSON {
+		return c.Problem(err.problem())
+	}
+	return c.Error(err.Status, err.Code, err.Message, err.Details)
+}
+
+// runErrorHooks runs the application's OnError hooks. A panicking hook is
+// recovered so the remaining hooks and the error response still run.
+func (c *Context) runErrorHooks(err error) {
+	for _, hook := range c.errorHooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("Panic recovered in error hook: %v", r)
+				}
+			}()
+			hook(c, err)
+		}()
+	}
+}
+
+// runErrorHandler passes err to the application's error handler and
+// reports whether it handled it. A panic in the error handler is recovered
+// and reported as unhandled, so the caller writes its default response.
+func (c *Context) runErrorHandler(err error) (handled bool) {
+	if c.errorHandler == nil {
+		return false
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Panic recovered in error handler: %v", r)
+			handled = false
+		}
+	}()
+
+	c.fastCtx.Response.ResetBody()
+	c.errorHandler(c, err)
+	return true
+}
@@ -0,0 +1,164 @@
+package gorgo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// TypedEvent is a struct event payload. EventName must work on the zero
+// value, so implement it with a value receiver.
+//
+// Fields are exposed to untyped subscribers in Event.Data under the key of
+// their `event` struct tag, or the field name without a tag.
+type TypedEvent interface {
+	EventName() string
+}
+
+// Request lifecycle events published by the application
+
+// RequestIncoming is published when a request arrives, before routing
+type RequestIncoming struct {
+	Method string `event:"method"`
+	Path   string `event:"path"`
+	IP     string `event:"ip"`
+}
+
+func (RequestIncoming) EventName() string { return "request.incoming" }
+
+// RequestCompleted is published when a handler returned without error
+type RequestCompleted struct {
+	Method string `event:"method"`
+	Path   string `event:"path"`
+	Status int    `event:"status"`
+	Size   int    `event:"size"`
+}
+
+func (RequestCompleted) EventName() string { return "request.completed" }
+
+// RequestNotFound is published when no route matches
+type RequestNotFound struct {
+	Method string `event:"method"`
+	Path   string `event:"path"`
+}
+
+func (RequestNotFound) EventName() string { return "request.not_found" }
+
+// RequestError is published when a handler returned an error
+type RequestError struct {
+	Method string `event:"method"`
+	Path   string `event:"path"`
+	Error  string `event:"error"`
+}
+
+func (RequestError) EventName() string { return "request.error" }
+
+// PublishTyped publishes a typed event. Typed subscribers receive the
+// struct, untyped subscribers its fields in Event.Data.
+//
+//	gorgo.PublishTyped(ctx, bus, OrderPlaced{ID: order.ID, Total: order.Total})
+func PublishTyped[T TypedEvent](ctx context.Context, eventBus *EventBus, event T) error {
+	return eventBus.publish(ctx, event.EventName(), eventData(event), event)
+}
+
+// SubscribeTyped subscribes handler to the events named by T.EventName().
+// Events published with the untyped Publish are decoded from their data.
+//
+//	gorgo.SubscribeTyped(bus, func(e gorgo.RequestCompleted) error {
+//	    log.Printf("%s %s -> %d", e.Method, e.Path, e.Status)
+//	    return nil
+//	})
+func SubscribeTyped[T TypedEvent](eventBus *EventBus, handler func(event T) error) SubscriptionID {
+	var zero T
+	return eventBus.Subscribe(zero.EventName(), TypedHandler(handler))
+}
+
+// TypedHandler adapts a typed handler to an EventHandler, e.g. for
+// EventSubscriber.GetEventSubscriptions:
+//
+//	return map[string]gorgo.EventHandler{
+//	    gorgo.RequestError{}.EventName(): gorgo.TypedHandler(p.onRequestError),
+//	}
+func TypedHandler[T TypedEvent](handler func(event T) error) EventHandler {
+	return func(event *Event) error {
+		if typed, ok := event.payload.(T); ok {
+			return handler(typed)
+		}
+
+		var typed T
+		if err := decodeEventData(event.Data, &typed); err != nil {
+			return fmt.Errorf("failed to decode event %s: %w", event.Name, err)
+		}
+		return handler(typed)
+	}
+}
+
+// eventData converts the fields of a struct event into event data
+func eventData(event interface{}) map[string]interface{} {
+	value := reflect.Indirect(reflect.ValueOf(event))
+	if value.Kind() != reflect.Struct {
+		return map[string]interface{}{}
+	}
+
+	data := make(map[string]interface{}, value.NumField())
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.IsExported() {
+			data[eventKey(field)] = value.Field(i).Interface()
+		}
+	}
+	return data
+}
+
+// decodeEventData fills the struct target points to from event data.
+// Missing keys are left zero; values are converted between numeric types.
+func decodeEventData(data map[string]interface{}, target interface{}) error {
+	value := reflect.ValueOf(target).Elem()
+	if value.Kind() == reflect.Pointer {
+		value.Set(reflect.New(value.Type().Elem()))
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("event type %s is not a struct", value.Type())
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		raw, exists := data[eventKey(field)]
+		if !exists || raw == nil {
+			continue
+		}
+
+		v := reflect.ValueOf(raw)
+		switch {
+		case v.Type().AssignableTo(field.Type):
+			value.Field(i).Set(v)
+		case isNumeric(v.Kind()) && isNumeric(field.Type.Kind()):
+			value.Field(i).Set(v.Convert(field.Type))
+		default:
+			return fmt.Errorf("field %s: cannot use %T as %s", field.Name, raw, field.Type)
+		}
+	}
+	return nil
+}
+
+func eventKey(field reflect.StructField) string {
+	if key := field.Tag.Get("event"); key != "" {
+		return key
+	}
+	return field.Name
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
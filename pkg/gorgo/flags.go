@@ -0,0 +1,203 @@
+package gorgo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// FlagsService is the container key of the application *FeatureFlags
+const FlagsService = "flags"
+
+// Flag defines a feature flag. A flag is on for a request if it is
+// Enabled, the user is listed in Users, or the user falls into the
+// rollout Percentage.
+type Flag struct {
+	Enabled bool `toml:"enabled" json:"enabled"`
+	// Percentage (0-100) turns the flag on for a stable share of users. The
+	// user ID is hashed when known, the client IP otherwise.
+	Percentage float64 `toml:"percentage" json:"percentage"`
+	// Users are user IDs the flag is always on for
+	Users []string `toml:"users" json:"users,omitempty"`
+}
+
+// FeatureFlags evaluates feature flags. It is registered in the container
+// as "flags"; definitions come from the [flags] config section and can be
+// replaced or toggled at runtime.
+type FeatureFlags struct {
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+func NewFeatureFlags() *FeatureFlags {
+	return &FeatureFlags{flags: make(map[string]Flag)}
+}
+
+// Enabled reports whether the flag is on for the request. Unknown flags
+// are off. ctx may be nil, then only Enabled is considered.
+func (f *FeatureFlags) Enabled(name string, ctx *Context) bool {
+	f.mu.RLock()
+	flag, exists := f.flags[name]
+	f.mu.RUnlock()
+
+	if !exists {
+		return false
+	}
+	if flag.Enabled {
+		return true
+	}
+	if ctx == nil {
+		return false
+	}
+
+	userID := flagUserID(ctx)
+	if userID != "" {
+		for _, user := range flag.Users {
+			if user == userID {
+				return true
+			}
+		}
+	}
+
+	if flag.Percentage <= 0 {
+		return false
+	}
+	subject := userID
+	if subject == "" {
+		subject = ctx.ClientIP()
+	}
+	return rolloutBucket(name, subject) < flag.Percentage*100
+}
+
+// Set defines or replaces a flag
+func (f *FeatureFlags) Set(name string, flag Flag) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flags[name] = flag
+}
+
+// Toggle turns a flag fully on or off, keeping its targeting
+func (f *FeatureFlags) Toggle(name string, enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	flag := f.flags[name]
+	flag.Enabled = enabled
+	f.flags[name] = flag
+}
+
+// Load replaces all flag definitions at once
+func (f *FeatureFlags) Load(flags map[string]Flag) {
+	loaded := make(map[string]Flag, len(flags))
+	for name, flag := range flags {
+		loaded[name] = flag
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flags = loaded
+}
+
+// Flags returns a copy of all flag definitions
+func (f *FeatureFlags) Flags() map[string]Flag {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	flags := make(map[string]Flag, len(f.flags))
+	for name, flag := range f.flags {
+		flags[name] = flag
+	}
+	return flags
+}
+
+// Handler returns an admin endpoint for the flags. GET responds with all
+// definitions; PUT and POST replace the flag named by the :name route
+// parameter with the Flag in the JSON body. Protect it with authentication.
+//
+//	app.Get("/admin/flags", flags.Handler(), adminAuth)
+//	app.Put("/admin/flags/:name", flags.Handler(), adminAuth)
+func (f *FeatureFlags) Handler() HandlerFunc {
+	return func(ctx *Context) error {
+		if ctx.Method() == "GET" {
+			flags := make(Map)
+			for name, flag := range f.Flags() {
+				flags[name] = flag
+			}
+			return ctx.JSON(flags)
+		}
+
+		name := ctx.Param("name")
+		if name == "" {
+			return ctx.Error(BadRequestStatus, "missing_flag", "Flag name is required")
+		}
+
+		var flag Flag
+		if !ctx.BindJSONValidated(&flag) {
+			return nil
+		}
+		f.Set(name, flag)
+		return ctx.JSON(Map{name: flag})
+	}
+}
+
+// FeatureEnabled reports whether the feature flag is on for this request,
+// using the FeatureFlags registered in the container
+func (c *Context) FeatureEnabled(name string) bool {
+	if service, ok := c.container.Get(FlagsService); ok {
+		if flags, ok := service.(*FeatureFlags); ok {
+			return flags.Enabled(name, c)
+		}
+	}
+	return false
+}
+
+// Flags returns the application feature flags
+func (a *Application) Flags() *FeatureFlags {
+	return a.flags
+}
+
+// ReloadFlags re-reads the [flags] section of the config file and replaces
+// all flag definitions, including ones changed at runtime
+func (a *Application) ReloadFlags() error {
+	var config struct {
+		Flags map[string]Flag `toml:"flags"`
+	}
+	if _, err := os.Stat(configPath); err == nil {
+		if _, err := toml.DecodeFile(configPath, &config); err != nil {
+			return fmt.Errorf("failed to reload flags: %w", err)
+		}
+	}
+
+	a.flags.Load(config.Flags)
+	return nil
+}
+
+// flagUserID identifies the authenticated user set by AuthMiddleware, or
+// a "user_id" value set by custom authentication
+func flagUserID(ctx *Context) string {
+	if id, ok := ctx.Get("user_id"); ok {
+		if str, ok := id.(string); ok {
+			return str
+		}
+	}
+	if user, ok := ctx.Get("user"); ok {
+		switch u := user.(type) {
+		case string:
+			return u
+		case fmt.Stringer:
+			return u.String()
+		}
+	}
+	return ""
+}
+
+// rolloutBucket maps the flag and subject to a stable bucket in [0, 10000)
+func rolloutBucket(name, subject string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(subject))
+	return float64(h.Sum32() % 10000)
+}
@@ -0,0 +1,157 @@
+package gorgo
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+)
+
+// ParseTrustedProxies parses IP addresses and CIDR ranges, e.g.
+// "10.0.0.0/8" or "127.0.0.1", into networks for SetTrustedProxies
+func ParseTrustedProxies(proxies ...string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", proxy)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// SetTrustedProxies sets the proxies whose X-Forwarded-Host,
+// X-Forwarded-Proto and X-Forwarded-Port headers are honored. Headers from
+// other clients are ignored, so they can't spoof the external URL. It
+// replaces server.trusted_proxies from the config file.
+//
+//	app.SetTrustedProxies("10.0.0.0/8", "127.0.0.1")
+func (a *Application) SetTrustedProxies(proxies ...string) error {
+	networks, err := ParseTrustedProxies(proxies...)
+	if err != nil {
+		return err
+	}
+	a.trustedProxies = networks
+	return nil
+}
+
+// loadTrustedProxies applies server.trusted_proxies from the config file
+func (a *Application) loadTrustedProxies() {
+	if len(a.config.Server.TrustedProxies) == 0 {
+		return
+	}
+	if err := a.SetTrustedProxies(a.config.Server.TrustedProxies...); err != nil {
+		log.Printf("Warning: ignoring server.trusted_proxies: %v", err)
+	}
+}
+
+// fromTrustedProxy reports whether the request was sent by a trusted proxy
+func (c *Context) fromTrustedProxy() bool {
+	if len(c.trustedProxies) == 0 {
+		return false
+	}
+	ip := c.fastCtx.RemoteIP()
+	for _, network := range c.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedHeader returns the first value of a X-Forwarded-* header sent
+// by a trusted proxy, the one closest to the client
+func (c *Context) forwardedHeader(name string) string {
+	if !c.fromTrustedProxy() {
+		return ""
+	}
+	value, _, _ := strings.Cut(c.GetHeader(name), ",")
+	return strings.TrimSpace(value)
+}
+
+// ForwardedHost returns the host the client requested: X-Forwarded-Host
+// from a trusted proxy, otherwise the Host header
+func (c *Context) ForwardedHost() string {
+	if host := c.forwardedHeader("X-Forwarded-Host"); host != "" && validHost(host) {
+		return host
+	}
+	return string(c.fastCtx.Host())
+}
+
+// ForwardedProto returns the scheme the client used, "http" or "https":
+// X-Forwarded-Proto from a trusted proxy, otherwise the connection's scheme
+func (c *Context) ForwardedProto() string {
+	switch proto := strings.ToLower(c.forwardedHeader("X-Forwarded-Proto")); proto {
+	case "http", "https":
+		return proto
+	}
+	if c.fastCtx.IsTLS() {
+		return "https"
+	}
+	return "http"
+}
+
+// ForwardedPort returns the port the client connected to: X-Forwarded-Port
+// from a trusted proxy, the port of ForwardedHost or the default port of
+// ForwardedProto
+func (c *Context) ForwardedPort() string {
+	if port := c.forwardedHeader("X-Forwarded-Port"); port != "" && isPort(port) {
+		return port
+	}
+	if _, port, err := net.SplitHostPort(c.ForwardedHost()); err == nil {
+		return port
+	}
+	if c.ForwardedProto() == "https" {
+		return "443"
+	}
+	return "80"
+}
+
+// ExternalBaseURL returns the externally visible base URL without a
+// trailing slash, e.g. "https://api.example.com", for absolute links in
+// redirects and emails. Behind a trusted proxy it is built from the
+// X-Forwarded-* headers.
+func (c *Context) ExternalBaseURL() string {
+	proto := c.ForwardedProto()
+	host := c.ForwardedHost()
+
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		port := c.ForwardedPort()
+		if (proto == "https" && port != "443") || (proto == "http" && port != "80") {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+		}
+	}
+	return proto + "://" + host
+}
+
+// validHost rejects forwarded hosts that would change the meaning of a URL
+func validHost(host string) bool {
+	return !strings.ContainsAny(host, "/\\@?# \t")
+}
+
+func isPort(port string) bool {
+	if len(port) == 0 || len(port) > 5 {
+		return false
+	}
+	for _, r := range port {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package gorgo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+// GoroutinePanicEvent is published when a goroutine started with Go panics
+const GoroutinePanicEvent = "goroutine.panic"
+
+var goroutines struct {
+	mu       sync.RWMutex
+	eventBus *EventBus
+	crash    bool
+}
+
+// Go runs fn in a new goroutine. A panic in fn is recovered, logged with
+// its stack trace and published as a goroutine.panic event instead of
+// crashing the process, unless SetCrashOnPanic(true) was called.
+// Background goroutines of the framework and plugins should use it.
+func Go(fn func()) {
+	go func() {
+		defer recoverGoroutine()
+		fn()
+	}()
+}
+
+// SetCrashOnPanic selects whether a panic in a goroutine started with Go
+// crashes the process (true) or is recovered (false, the default). It can
+// also be set with app.crash_on_panic in the config.
+func SetCrashOnPanic(crash bool) {
+	goroutines.mu.Lock()
+	defer goroutines.mu.Unlock()
+	goroutines.crash = crash
+}
+
+func setGoroutineEventBus(eventBus *EventBus) {
+	goroutines.mu.Lock()
+	defer goroutines.mu.Unlock()
+	goroutines.eventBus = eventBus
+}
+
+func recoverGoroutine() {
+	goroutines.mu.RLock()
+	crash := goroutines.crash
+	eventBus := goroutines.eventBus
+	goroutines.mu.RUnlock()
+
+	if crash {
+		return
+	}
+
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	stack := string(debug.Stack())
+	log.Printf("Recovered panic in goroutine: %v\n%s", r, stack)
+
+	if eventBus != nil {
+		eventBus.Publish(context.Background(), GoroutinePanicEvent, map[string]interface{}{
+			"panic": fmt.Sprint(r),
+			"stack": stack,
+		})
+	}
+}
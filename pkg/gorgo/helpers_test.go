@@ -0,0 +1,24 @@
+package gorgo
+
+import (
+	"testing"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+)
+
+// newTestApp returns an Application like New, but without loading the
+// config file, default middleware or the banner. Drive requests through
+// app.handleRequest, or app.Serve on an in-memory listener.
+func newTestApp(t *testing.T) *Application {
+	t.Helper()
+	c := container.NewContainer()
+	return &Application{
+		container:       c,
+		pluginManager:   NewPluginManager(c),
+		router:          NewRouter(),
+		middlewareChain: NewMiddlewareChain(),
+		preMiddleware:   NewMiddlewareChain(),
+		versioning:      newVersioning(),
+		flags:           NewFeatureFlags(),
+	}
+}
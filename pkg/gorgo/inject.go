@@ -0,0 +1,128 @@
+package gorgo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	contextType = reflect.TypeOf((*Context)(nil))
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// injectedHandler adapts a handler with service parameters
+type injectedHandler struct {
+	fn     reflect.Value
+	params []reflect.Type
+	// names are the container names of the parameters, resolved at
+	// registration or startup
+	names []string
+}
+
+// Inject adapts a handler that declares services as parameters after the
+// context. Services are resolved from the container by type, e.g. the SQL
+// plugin's pool:
+//
+//	app.Get("/users", app.Inject(func(ctx *gorgo.Context, pool *pgxpool.Pool) error {
+//	    rows, err := pool.Query(ctx.Context(), "SELECT id, name FROM users")
+//	    // ...
+//	}))
+//
+// The signature is checked immediately. Dependencies are resolved at
+// registration when possible, otherwise once plugins have registered their
+// services at startup; Run fails before serving when a handler has an
+// invalid signature or a dependency that no single service provides.
+func (a *Application) Inject(handler interface{}) HandlerFunc {
+	injected, err := newInjectedHandler(handler)
+	if err != nil {
+		a.injectErrors = append(a.injectErrors, err)
+		return func(ctx *Context) error { return err }
+	}
+
+	// Resolve what is registered already, plugin services follow at startup
+	for i, param := range injected.params {
+		if name, err := a.container.FindByType(param); err == nil {
+			injected.names[i] = name
+		}
+	}
+
+	a.injected = append(a.injected, injected)
+	return injected.handle
+}
+
+func newInjectedHandler(handler interface{}) (*injectedHandler, error) {
+	fn := reflect.ValueOf(handler)
+	if fn.Kind() != reflect.Func {
+		return nil, fmt.Errorf("inject: handler must be a function, got %T", handler)
+	}
+
+	fnType := fn.Type()
+	if fnType.NumIn() == 0 || fnType.In(0) != contextType || fnType.IsVariadic() {
+		return nil, fmt.Errorf("inject: handler %s must take *gorgo.Context as its first parameter", fnType)
+	}
+	if fnType.NumOut() != 1 || fnType.Out(0) != errorType {
+		return nil, fmt.Errorf("inject: handler %s must return error", fnType)
+	}
+
+	injected := &injectedHandler{fn: fn}
+	for i := 1; i < fnType.NumIn(); i++ {
+		injected.params = append(injected.params, fnType.In(i))
+	}
+	injected.names = make([]string, len(injected.params))
+	return injected, nil
+}
+
+// resolveInjected resolves the dependencies of all injected handlers and
+// returns the problems found, for Run to fail fast
+func (a *Application) resolveInjected() error {
+	errs := append([]error(nil), a.injectErrors...)
+	for _, injected := range a.injected {
+		for i, param := range injected.params {
+			name, err := a.container.FindByType(param)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("inject: handler %s: %w", injected.fn.Type(), err))
+				continue
+			}
+			injected.names[i] = name
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *injectedHandler) handle(ctx *Context) error {
+	args := make([]reflect.Value, len(h.params)+1)
+	args[0] = reflect.ValueOf(ctx)
+
+	for i, param := range h.params {
+		service, err := h.resolve(ctx, i)
+		if err != nil {
+			return fmt.Errorf("inject: %s: %w", param, err)
+		}
+		args[i+1] = reflect.ValueOf(service)
+	}
+
+	result := h.fn.Call(args)[0]
+	if result.IsNil() {
+		return nil
+	}
+	return result.Interface().(error)
+}
+
+// resolve returns the service for parameter i, looking it up by type when
+// it was not resolved before
+func (h *injectedHandler) resolve(ctx *Context, i int) (interface{}, error) {
+	name := h.names[i]
+	if name == "" {
+		var err error
+		if name, err = ctx.container.FindByType(h.params[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	service, ok := ctx.container.Get(name)
+	if !ok || service == nil || !reflect.TypeOf(service).AssignableTo(h.params[i]) {
+		return nil, fmt.Errorf("service %s is no longer available", name)
+	}
+	return service, nil
+}
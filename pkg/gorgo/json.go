@@ -0,0 +1,64 @@
+package gorgo
+
+import (
+	"encoding/json"
+	"io"
+	"sync/atomic"
+)
+
+// JSONCodec encodes and decodes JSON bodies. The default uses encoding/json;
+// SetJSONCodec swaps in a faster implementation such as goccy/go-json or
+// sonic without the framework depending on it.
+type JSONCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	NewEncoder(w io.Writer) JSONEncoder
+}
+
+// JSONEncoder writes JSON values to a stream, like *json.Encoder
+type JSONEncoder interface {
+	Encode(v interface{}) error
+}
+
+// StdJSONCodec is the encoding/json codec
+type StdJSONCodec struct{}
+
+func (StdJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (StdJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (StdJSONCodec) NewEncoder(w io.Writer) JSONEncoder {
+	return json.NewEncoder(w)
+}
+
+// jsonCodecHolder gives atomic.Value a single concrete type to store
+type jsonCodecHolder struct {
+	codec JSONCodec
+}
+
+var currentJSONCodec atomic.Value
+
+// SetJSONCodec sets the codec used by ctx.JSON, ctx.BindJSON, ctx.BodyMap,
+// BindJSONValidated and error responses. Call it at startup; nil restores
+// encoding/json. BindJSONWithNumbers, NDJSON and JSON Patch always use
+// encoding/json, as they rely on its json.Number and json.RawMessage types.
+//
+//	gorgo.SetJSONCodec(gojsonCodec{}) // wraps github.com/goccy/go-json
+func SetJSONCodec(codec JSONCodec) {
+	if codec == nil {
+		codec = StdJSONCodec{}
+	}
+	currentJSONCodec.Store(jsonCodecHolder{codec: codec})
+}
+
+// GetJSONCodec returns the configured JSON codec
+func GetJSONCodec() JSONCodec {
+	if holder, ok := currentJSONCodec.Load().(jsonCodecHolder); ok {
+		return holder.codec
+	}
+	return StdJSONCodec{}
+}
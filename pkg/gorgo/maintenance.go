@@ -0,0 +1,84 @@
+package gorgo
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaintenanceOptions configuration for maintenance mode
+type MaintenanceOptions struct {
+	// ExemptPaths keep being served, e.g. health checks and admin
+	// endpoints. A trailing "/*" exempts the whole subtree.
+	ExemptPaths []string
+	Message     string
+	// RetryAfter is sent in the Retry-After header when non-zero
+	RetryAfter time.Duration
+}
+
+// DefaultMaintenanceOptions returns default maintenance mode settings
+func DefaultMaintenanceOptions() MaintenanceOptions {
+	return MaintenanceOptions{
+		Message: "The service is down for maintenance, please try again later",
+	}
+}
+
+// SetMaintenanceMode turns maintenance mode on or off. While it is on,
+// every request except those to exempt paths is answered with 503 Service
+// Unavailable before routing. It is safe to call while serving requests,
+// e.g. from an admin handler or a signal handler.
+//
+//	app.SetMaintenanceMode(true, gorgo.MaintenanceOptions{
+//	    ExemptPaths: []string{"/health", "/admin/*"},
+//	    RetryAfter:  10 * time.Minute,
+//	})
+func (a *Application) SetMaintenanceMode(on bool, options ...MaintenanceOptions) *Application {
+	if !on {
+		a.maintenance.Store(nil)
+		return a
+	}
+
+	maintenanceOptions := DefaultMaintenanceOptions()
+	if len(options) > 0 {
+		maintenanceOptions = options[0]
+		if maintenanceOptions.Message == "" {
+			maintenanceOptions.Message = DefaultMaintenanceOptions().Message
+		}
+	}
+	a.maintenance.Store(&maintenanceOptions)
+	return a
+}
+
+// InMaintenance reports whether maintenance mode is on
+func (a *Application) InMaintenance() bool {
+	return a.maintenance.Load() != nil
+}
+
+// rejectForMaintenance answers the request with 503 and returns true if
+// maintenance mode is on and the path is not exempt
+func (a *Application) rejectForMaintenance(ctx *Context) bool {
+	options := a.maintenance.Load()
+	if options == nil || options.exempt(ctx.Path()) {
+		return false
+	}
+
+	if options.RetryAfter > 0 {
+		seconds := int((options.RetryAfter + time.Second - 1) / time.Second)
+		ctx.Header("Retry-After", strconv.Itoa(seconds))
+	}
+	ctx.writeError(NewHTTPError(ServiceUnavailableStatus, "maintenance", options.Message))
+	return true
+}
+
+func (o *MaintenanceOptions) exempt(path string) bool {
+	for _, exempt := range o.ExemptPaths {
+		if prefix, wildcard := strings.CutSuffix(exempt, "/*"); wildcard {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		} else if path == exempt {
+			return true
+		}
+	}
+	return false
+}
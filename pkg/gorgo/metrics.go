@@ -0,0 +1,38 @@
+package gorgo
+
+// MetricsService is the container key of the application Metrics
+const MetricsService = "metrics"
+
+// Labels are metric label pairs
+type Labels map[string]string
+
+// Metrics records application metrics. Names and labels follow the
+// Prometheus data model (e.g. "http_requests_total", {"method": "GET"}).
+// The application registers a no-op implementation by default; plugins
+// such as monitoring replace it with a collecting one.
+type Metrics interface {
+	// Counter adds value to a monotonically increasing counter
+	Counter(name string, value float64, labels Labels)
+	// Gauge sets a gauge to value
+	Gauge(name string, value float64, labels Labels)
+	// Histogram records an observation
+	Histogram(name string, value float64, labels Labels)
+}
+
+// NoopMetrics discards all metrics
+type NoopMetrics struct{}
+
+func (NoopMetrics) Counter(name string, value float64, labels Labels)   {}
+func (NoopMetrics) Gauge(name string, value float64, labels Labels)     {}
+func (NoopMetrics) Histogram(name string, value float64, labels Labels) {}
+
+// Metrics returns the Metrics registered in the container, falling back
+// to NoopMetrics so callers never need a nil check
+func (c *Context) Metrics() Metrics {
+	if service, ok := c.container.Get(MetricsService); ok {
+		if metrics, ok := service.(Metrics); ok {
+			return metrics
+		}
+	}
+	return NoopMetrics{}
+}
@@ -1,7 +1,12 @@
 package gorgo
 
 import (
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
 	"log"
+	"strings"
 	"time"
 )
 
@@ -26,40 +31,79 @@ func (mc *MiddlewareChain) Add(middleware MiddlewareFunc) *MiddlewareChain {
 	return mc
 }
 
-// Execute executes the middleware chain
+// Execute executes the middleware chain. Once the context is aborted,
+// the remaining middleware and the handler are skipped.
 func (mc *MiddlewareChain) Execute(handler HandlerFunc) HandlerFunc {
 	// Apply middleware in reverse order
 	for i := len(mc.middlewares) - 1; i >= 0; i-- {
-		handler = mc.middlewares[i](handler)
+		handler = mc.middlewares[i](skipIfAborted(handler))
 	}
 	return handler
 }
 
+func skipIfAborted(next HandlerFunc) HandlerFunc {
+	return func(ctx *Context) error {
+		if ctx.IsAborted() {
+			return nil
+		}
+		return next(ctx)
+	}
+}
+
+// Stack is a reusable bundle of middleware. Being a slice it can be
+// spread directly into route registrations:
+//
+//	admin := gorgo.NewStack(gorgo.AuthMiddleware(authFunc), gorgo.LoggerMiddleware())
+//	app.Get("/stats", statsHandler, admin...)
+//	app.Group("/admin", admin.With(auditMiddleware)...)
+type Stack []MiddlewareFunc
+
+// NewStack creates a middleware stack
+func NewStack(middlewares ...MiddlewareFunc) Stack {
+	return append(Stack(nil), middlewares...)
+}
+
+// With returns a new stack extended with middlewares, leaving s unchanged
+func (s Stack) With(middlewares ...MiddlewareFunc) Stack {
+	result := make(Stack, 0, len(s)+len(middlewares))
+	result = append(result, s...)
+	return append(result, middlewares...)
+}
+
+// Middlewares returns the middleware of the stack
+func (s Stack) Middlewares() []MiddlewareFunc {
+	return s.With()
+}
+
+// Chain returns the stack as a MiddlewareChain
+func (s Stack) Chain() *MiddlewareChain {
+	return NewMiddlewareChain(s.Middlewares()...)
+}
+
 // Built-in middleware
 
 // LoggerMiddleware logs requests
 func LoggerMiddleware() MiddlewareFunc {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(ctx *Context) error {
-			start := time.Now()
-
 			// Execute next handler
 			err := next(ctx)
 
 			// Log
-			duration := time.Since(start)
+			duration := ctx.Elapsed()
 			method := string(ctx.fastCtx.Method())
 			path := string(ctx.fastCtx.Path())
-			status := ctx.fastCtx.Response.StatusCode()
+			status := ctx.StatusCode()
 
-			log.Printf("%s %s %d %v", method, path, status, duration)
+			log.Printf("%s %s %d %dB %v", method, path, status, ctx.ResponseSize(), duration)
 
 			return err
 		}
 	}
 }
 
-// RecoveryMiddleware recovers from panics
+// RecoveryMiddleware recovers from panics, running the OnError hooks and
+// responding through the application's error handler if one is set
 func RecoveryMiddleware() MiddlewareFunc {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(ctx *Context) error {
@@ -67,7 +111,11 @@ func RecoveryMiddleware() MiddlewareFunc {
 				if r := recover(); r != nil {
 					log.Printf("Panic recovered: %v", r)
 					ctx.fastCtx.SetStatusCode(500)
-					ctx.fastCtx.SetBodyString("Internal Server Error")
+					err := &HTTPError{Status: InternalServerErrorStatus, Code: "internal_error", Message: "Internal Server Error", Err: fmt.Errorf("panic: %v", r)}
+					ctx.runErrorHooks(err)
+					if !ctx.runErrorHandler(err) {
+						ctx.writeInternalError()
+					}
 				}
 			}()
 
@@ -149,6 +197,7 @@ func RateLimitMiddleware(options RateLimitOptions) MiddlewareFunc {
 			clientIP := string(ctx.fastCtx.RemoteIP())
 
 			if !limiter.Allow(clientIP) {
+				ctx.audit(ctx.ClientIP(), "rate_limit", AuditDenied, "")
 				ctx.fastCtx.SetStatusCode(429)
 				ctx.fastCtx.SetBodyString("Too Many Requests")
 				return nil
@@ -221,9 +270,11 @@ func AuthMiddleware(authFunc func(ctx *Context) (interface{}, error)) Middleware
 		return func(ctx *Context) error {
 			user, err := authFunc(ctx)
 			if err != nil {
+				ctx.audit("anonymous", "authenticate", AuditFailure, err.Error())
 				ctx.fastCtx.SetStatusCode(401)
 				return ctx.JSON(Map{"error": "Unauthorized"})
 			}
+			ctx.audit(auditActor(user), "authenticate", AuditSuccess, "")
 
 			// Save user in context
 			ctx.Set("user", user)
@@ -249,3 +300,156 @@ func CompressionMiddleware() MiddlewareFunc {
 		}
 	}
 }
+
+// RequireHeadersMiddleware rejects requests missing any of the given headers
+func RequireHeadersMiddleware(names ...string) MiddlewareFunc {
+	return RequireHeadersMiddlewareWithOptions(RequireHeadersOptions{Headers: names})
+}
+
+// RequireHeadersMiddlewareWithOptions rejects requests missing any of the
+// configured headers, responding with an ErrorResponse listing them
+func RequireHeadersMiddlewareWithOptions(options RequireHeadersOptions) MiddlewareFunc {
+	status := options.Status
+	if status == 0 {
+		status = BadRequestStatus
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			var missing []string
+			for _, name := range options.Headers {
+				if len(ctx.fastCtx.Request.Header.Peek(name)) == 0 {
+					missing = append(missing, name)
+				}
+			}
+
+			if len(missing) > 0 {
+				message := options.Message
+				if message == "" {
+					message = "Missing required headers: " + strings.Join(missing, ", ")
+				}
+				return ctx.Error(status, "missing_headers", message, map[string]interface{}{
+					"missing": missing,
+				})
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// RequireHeadersOptions configuration for required headers
+type RequireHeadersOptions struct {
+	Headers []string
+	Status  int    // Defaults to 400
+	Message string // Defaults to a message listing the missing headers
+}
+
+// ValidateQueryMiddleware validates query parameters against the schema
+// before the handler runs. All violations are reported together in a
+// 400 ErrorResponse keyed by parameter name.
+func ValidateQueryMiddleware(schema QuerySchema) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			if violations := schema.Validate(ctx); violations != nil {
+				return ctx.Error(BadRequestStatus, "invalid_query", "Invalid query parameters", violations)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// DecompressMiddleware transparently decompresses gzip and deflate request
+// bodies. Decompression is streamed and aborted with 413 as soon as the
+// output exceeds MaxSize, so small high-ratio payloads (zip bombs) cannot
+// allocate unbounded memory.
+func DecompressMiddleware(options DecompressOptions) MiddlewareFunc {
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultDecompressOptions().MaxSize
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			encoding := strings.ToLower(strings.TrimSpace(ctx.GetHeader("Content-Encoding")))
+
+			var reader io.ReadCloser
+			var err error
+			switch encoding {
+			case "gzip", "x-gzip":
+				reader, err = gzip.NewReader(ctx.BodyStream())
+			case "deflate":
+				reader = flate.NewReader(ctx.BodyStream())
+			default:
+				return next(ctx)
+			}
+			if err != nil {
+				return ctx.Error(BadRequestStatus, "invalid_encoding", "Malformed compressed body")
+			}
+			defer reader.Close()
+
+			// Read at most one byte past the limit to detect overflow
+			body, err := io.ReadAll(io.LimitReader(reader, options.MaxSize+1))
+			if err != nil {
+				return ctx.Error(BadRequestStatus, "invalid_encoding", "Malformed compressed body")
+			}
+			if int64(len(body)) > options.MaxSize {
+				return ctx.Error(PayloadTooLargeStatus, "payload_too_large",
+					fmt.Sprintf("Decompressed body exceeds %d bytes", options.MaxSize))
+			}
+
+			ctx.fastCtx.Request.SetBodyRaw(body)
+			ctx.fastCtx.Request.Header.Del("Content-Encoding")
+
+			return next(ctx)
+		}
+	}
+}
+
+// DecompressOptions configuration for request decompression
+type DecompressOptions struct {
+	// MaxSize is the maximum decompressed body size in bytes
+	MaxSize int64
+}
+
+// DefaultDecompressOptions returns default decompression settings
+func DefaultDecompressOptions() DecompressOptions {
+	return DecompressOptions{
+		MaxSize: 10 << 20, // 10 MB
+	}
+}
+
+// PathRewriteMiddleware rewrites the request path before routing and must
+// be registered with app.Pre. A trailing "/*" in from matches the path and
+// everything below it; a "*" in to is replaced with the matched remainder.
+//
+//	app.Pre(gorgo.PathRewriteMiddleware("/api/*", "/*"))       // /api/users -> /users
+//	app.Pre(gorgo.PathRewriteMiddleware("/old/*", "/new/*"))   // /old/a/b -> /new/a/b
+//	app.Pre(gorgo.PathRewriteMiddleware("/legacy", "/current")) // exact match
+func PathRewriteMiddleware(from, to string) MiddlewareFunc {
+	prefix, wildcard := strings.CutSuffix(from, "/*")
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			path := ctx.Path()
+
+			var rewritten string
+			switch {
+			case !wildcard && path == from:
+				rewritten = to
+			case wildcard && (path == prefix || strings.HasPrefix(path, prefix+"/")):
+				rest := strings.TrimPrefix(strings.TrimPrefix(path, prefix), "/")
+				rewritten = strings.Replace(to, "*", rest, 1)
+			default:
+				return next(ctx)
+			}
+
+			if !strings.HasPrefix(rewritten, "/") {
+				rewritten = "/" + rewritten
+			}
+			ctx.fastCtx.URI().SetPath(rewritten)
+
+			return next(ctx)
+		}
+	}
+}
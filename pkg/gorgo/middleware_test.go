@@ -0,0 +1,871 @@
+package gorgo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/valyala/fasthttp"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecompressMiddleware(t *testing.T) {
+	var received string
+	handler := DecompressMiddleware(DefaultDecompressOptions())(func(ctx *Context) error {
+		received = ctx.BodyString()
+		return nil
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("Content-Encoding", "gzip")
+	ctx.Request.SetBody(gzipBytes(t, []byte(`{"name":"gorgo"}`)))
+
+	if err := handler(NewContext(ctx, container.NewContainer(), make(map[string]Plugin))); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if received != `{"name":"gorgo"}` {
+		t.Errorf("Expected decompressed body, got '%s'", received)
+	}
+
+	if len(ctx.Request.Header.Peek("Content-Encoding")) != 0 {
+		t.Error("Expected Content-Encoding header to be removed")
+	}
+}
+
+func TestDecompressMiddleware_ZipBomb(t *testing.T) {
+	// 64 MB of zeros compresses to roughly 64 KB
+	payload := gzipBytes(t, make([]byte, 64<<20))
+
+	called := false
+	handler := DecompressMiddleware(DecompressOptions{MaxSize: 1 << 20})(func(ctx *Context) error {
+		called = true
+		return nil
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.Set("Content-Encoding", "gzip")
+	ctx.Request.SetBody(payload)
+
+	if err := handler(NewContext(ctx, container.NewContainer(), make(map[string]Plugin))); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if called {
+		t.Error("Expected handler not to be called for oversized payload")
+	}
+
+	if ctx.Response.StatusCode() != PayloadTooLargeStatus {
+		t.Errorf("Expected status %d, got %d", PayloadTooLargeStatus, ctx.Response.StatusCode())
+	}
+}
+
+func TestRequireHeadersMiddleware(t *testing.T) {
+	request := func(middleware MiddlewareFunc, headers map[string]string) (*fasthttp.RequestCtx, bool) {
+		called := false
+		handler := middleware(func(ctx *Context) error {
+			called = true
+			return nil
+		})
+
+		ctx := &fasthttp.RequestCtx{}
+		for name, value := range headers {
+			ctx.Request.Header.Set(name, value)
+		}
+		if err := handler(NewContext(ctx, container.NewContainer(), make(map[string]Plugin))); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return ctx, called
+	}
+
+	required := RequireHeadersMiddleware("X-Tenant-ID", "X-Request-ID")
+
+	ctx, called := request(required, map[string]string{"X-Tenant-ID": "acme", "X-Request-ID": "1"})
+	if !called || ctx.Response.StatusCode() != OKStatus {
+		t.Errorf("Expected request with all headers to pass, got %d", ctx.Response.StatusCode())
+	}
+
+	ctx, called = request(required, map[string]string{"X-Tenant-ID": "acme"})
+	if called {
+		t.Error("Expected handler not to be called with a missing header")
+	}
+	if ctx.Response.StatusCode() != BadRequestStatus {
+		t.Errorf("Expected status %d, got %d", BadRequestStatus, ctx.Response.StatusCode())
+	}
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details struct {
+			Missing []string `json:"missing"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected an ErrorResponse, got %q", ctx.Response.Body())
+	}
+	if body.Code != "missing_headers" || body.Message != "Missing required headers: X-Request-ID" ||
+		len(body.Details.Missing) != 1 || body.Details.Missing[0] != "X-Request-ID" {
+		t.Errorf("Unexpected error response %+v", body)
+	}
+
+	// An empty value counts as missing
+	if _, called = request(required, map[string]string{"X-Tenant-ID": "", "X-Request-ID": "1"}); called {
+		t.Error("Expected an empty header to count as missing")
+	}
+
+	custom := RequireHeadersMiddlewareWithOptions(RequireHeadersOptions{
+		Headers: []string{"Authorization"},
+		Status:  UnauthorizedStatus,
+		Message: "Authentication required",
+	})
+	ctx, called = request(custom, nil)
+	if called || ctx.Response.StatusCode() != UnauthorizedStatus {
+		t.Errorf("Expected custom status %d, got %d", UnauthorizedStatus, ctx.Response.StatusCode())
+	}
+	if !strings.Contains(string(ctx.Response.Body()), `"message":"Authentication required"`) {
+		t.Errorf("Expected custom message, got %q", ctx.Response.Body())
+	}
+}
+
+func TestAbortWithRedirect(t *testing.T) {
+	loginGate := func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			if err := ctx.AbortWithRedirect(302, "/login"); err != nil {
+				return err
+			}
+			return next(ctx)
+		}
+	}
+
+	handlerCalled := false
+	chain := NewMiddlewareChain(loginGate, LoggerMiddleware())
+	handler := chain.Execute(func(ctx *Context) error {
+		handlerCalled = true
+		return nil
+	})
+
+	fastCtx := &fasthttp.RequestCtx{}
+	ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+	if err := handler(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if handlerCalled {
+		t.Error("Expected handler to be skipped after abort")
+	}
+	if fastCtx.Response.StatusCode() != 302 {
+		t.Errorf("Expected status 302, got %d", fastCtx.Response.StatusCode())
+	}
+	if location := string(fastCtx.Response.Header.Peek("Location")); !strings.HasSuffix(location, "/login") {
+		t.Errorf("Expected Location to point to /login, got %s", location)
+	}
+
+	ctx = NewContext(&fasthttp.RequestCtx{}, container.NewContainer(), make(map[string]Plugin))
+	if err := ctx.AbortWithRedirect(200, "/login"); err == nil {
+		t.Error("Expected error for non-3xx status")
+	}
+	if ctx.IsAborted() {
+		t.Error("Expected context not to be aborted on invalid status")
+	}
+}
+
+func TestPathRewriteMiddleware(t *testing.T) {
+	tests := []struct {
+		from, to, path, expected string
+	}{
+		{"/api/*", "/*", "/api/users", "/users"},
+		{"/api/*", "/*", "/api/users/42", "/users/42"},
+		{"/api/*", "/*", "/api", "/"},
+		{"/api/*", "/*", "/apiary", "/apiary"},
+		{"/old/*", "/new/*", "/old/a/b", "/new/a/b"},
+		{"/docs/*", "/help", "/docs/intro", "/help"},
+		{"/legacy", "/current", "/legacy", "/current"},
+		{"/legacy", "/current", "/legacy/x", "/legacy/x"},
+	}
+
+	for _, tt := range tests {
+		var routed string
+		handler := PathRewriteMiddleware(tt.from, tt.to)(func(ctx *Context) error {
+			routed = ctx.Path()
+			return nil
+		})
+
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(tt.path)
+		ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+		if err := handler(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if routed != tt.expected {
+			t.Errorf("PathRewriteMiddleware(%q, %q) on %s: expected %s, got %s", tt.from, tt.to, tt.path, tt.expected, routed)
+		}
+	}
+}
+
+func recordingMiddleware(name string, calls *[]string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			*calls = append(*calls, name)
+			return next(ctx)
+		}
+	}
+}
+
+func TestStack(t *testing.T) {
+	var calls []string
+	base := NewStack(recordingMiddleware("auth", &calls), recordingMiddleware("log", &calls))
+	extended := base.With(recordingMiddleware("audit", &calls))
+
+	if len(base) != 2 {
+		t.Errorf("Expected With to leave the base stack unchanged, got %d middlewares", len(base))
+	}
+
+	handler := extended.Chain().Execute(func(ctx *Context) error { return nil })
+	ctx := NewContext(&fasthttp.RequestCtx{}, container.NewContainer(), make(map[string]Plugin))
+	if err := handler(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"auth", "log", "audit"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestRouteGroupMiddlewareIsolation(t *testing.T) {
+	var calls []string
+	// Group middleware with spare capacity must not leak between routes
+	groupMiddleware := make([]MiddlewareFunc, 1, 4)
+	groupMiddleware[0] = recordingMiddleware("group", &calls)
+
+	app := newTestApp(t)
+	group := app.Group("/g", groupMiddleware...)
+	group.Get("/a", func(ctx *Context) error { return nil }, recordingMiddleware("a", &calls))
+	group.Get("/b", func(ctx *Context) error { return nil }, recordingMiddleware("b", &calls))
+
+	handler, _ := app.router.FindHandler("GET", "/g/a")
+	ctx := NewContext(&fasthttp.RequestCtx{}, container.NewContainer(), make(map[string]Plugin))
+	if err := handler(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(calls, ",") != "group,a" {
+		t.Errorf("Expected calls [group a], got %v", calls)
+	}
+}
+
+func TestGroupPreflight(t *testing.T) {
+	app := newTestApp(t)
+	handler := func(ctx *Context) error { return nil }
+	deny := func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error { return ctx.Error(UnauthorizedStatus, "unauthorized", "Unauthorized") }
+	}
+
+	public := app.Group("/public", CORSMiddleware(CORSOptions{AllowOrigin: "*"}))
+	public.Get("/items", handler)
+	public.Post("/items", handler, deny)
+	internal := app.Group("/internal", CORSMiddleware(CORSOptions{AllowOrigin: "https://admin.example.com"}))
+	internal.Get("/users/:id", handler)
+	app.Get("/plain", handler)
+
+	tests := []struct {
+		path, origin, allow string
+		status              int
+	}{
+		{"/public/items", "*", "", 200},
+		{"/internal/users/42", "https://admin.example.com", "", 200},
+		{"/plain", "", "GET, OPTIONS", NoContentStatus},
+	}
+
+	for _, tt := range tests {
+		h, _ := app.router.FindHandler("OPTIONS", tt.path)
+		if h == nil {
+			t.Fatalf("Expected automatic OPTIONS handler for %s", tt.path)
+		}
+
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.Header.SetMethod("OPTIONS")
+		ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+		if err := h(ctx); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if status := fastCtx.Response.StatusCode(); status != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, status)
+		}
+		if origin := string(fastCtx.Response.Header.Peek("Access-Control-Allow-Origin")); origin != tt.origin {
+			t.Errorf("%s: expected origin %q, got %q", tt.path, tt.origin, origin)
+		}
+		if allow := string(fastCtx.Response.Header.Peek("Allow")); allow != tt.allow {
+			t.Errorf("%s: expected Allow %q, got %q", tt.path, tt.allow, allow)
+		}
+	}
+
+	if h, _ := app.router.FindHandler("OPTIONS", "/missing"); h != nil {
+		t.Error("Expected no OPTIONS handler for unknown path")
+	}
+	if len(app.router.Routes()) != 4 {
+		t.Errorf("Expected automatic OPTIONS handlers to be unlisted, got %+v", app.router.Routes())
+	}
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	app := newTestApp(t)
+	app.SetMaintenanceMode(true, MaintenanceOptions{
+		ExemptPaths: []string{"/health", "/admin/*"},
+		RetryAfter:  90 * time.Second,
+	})
+
+	tests := []struct {
+		path     string
+		rejected bool
+	}{
+		{"/users", true},
+		{"/health", false},
+		{"/healthz", true},
+		{"/admin", false},
+		{"/admin/maintenance", false},
+	}
+
+	for _, tt := range tests {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(tt.path)
+		ctx := NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin))
+
+		if rejected := app.rejectForMaintenance(ctx); rejected != tt.rejected {
+			t.Errorf("%s: expected rejected=%v, got %v", tt.path, tt.rejected, rejected)
+		}
+		if tt.rejected {
+			if fastCtx.Response.StatusCode() != ServiceUnavailableStatus {
+				t.Errorf("%s: expected 503, got %d", tt.path, fastCtx.Response.StatusCode())
+			}
+			if retry := string(fastCtx.Response.Header.Peek("Retry-After")); retry != "90" {
+				t.Errorf("%s: expected Retry-After 90, got %q", tt.path, retry)
+			}
+		}
+	}
+
+	app.SetMaintenanceMode(false)
+	ctx := NewContext(&fasthttp.RequestCtx{}, container.NewContainer(), make(map[string]Plugin))
+	if app.InMaintenance() || app.rejectForMaintenance(ctx) {
+		t.Error("Expected maintenance mode to be off")
+	}
+}
+
+// issueCert creates a certificate signed by parent, self-signed when parent is nil
+func issueCert(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key generation failed: %v", err)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("certificate creation failed: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("certificate parsing failed: %v", err)
+	}
+	return cert, key
+}
+
+// tlsRequestCtx performs a TLS handshake over a pipe and returns a request
+// context on the server side of the connection
+func tlsRequestCtx(t *testing.T, clientCert *tls.Certificate) *fasthttp.RequestCtx {
+	serverCert, serverKey := issueCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, nil, nil)
+
+	serverConn, clientConn := net.Pipe()
+	server := tls.Server(serverConn, &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequestClientCert,
+	})
+	clientConfig := &tls.Config{InsecureSkipVerify: true}
+	if clientCert != nil {
+		clientConfig.Certificates = []tls.Certificate{*clientCert}
+	}
+	client := tls.Client(clientConn, clientConfig)
+	t.Cleanup(func() {
+		// Close the pipe directly, a TLS close_notify would block without a reader
+		clientConn.Close()
+		serverConn.Close()
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- client.Handshake() }()
+	if err := server.Handshake(); err != nil {
+		t.Fatalf("server handshake failed: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("client handshake failed: %v", err)
+	}
+
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Init2(server, nil, false)
+	return fastCtx
+}
+
+func TestMTLSMiddleware(t *testing.T) {
+	ca, caKey := issueCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	client := func(cn string, signer *x509.Certificate, signerKey *ecdsa.PrivateKey) *tls.Certificate {
+		cert, key := issueCert(t, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: cn},
+			DNSNames:     []string{cn + ".internal"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}, signer, signerKey)
+		return &tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: key}
+	}
+
+	var identity *ClientCert
+	handler := MTLSMiddleware(MTLSOptions{
+		CAPool:       pool,
+		AllowedNames: []string{"billing.internal"},
+	})(func(ctx *Context) error {
+		identity = ctx.ClientCert()
+		return nil
+	})
+
+	tests := []struct {
+		name   string
+		cert   *tls.Certificate
+		status int
+	}{
+		{"no certificate", nil, UnauthorizedStatus},
+		{"untrusted certificate", client("billing", nil, nil), ForbiddenStatus},
+		{"name not allowed", client("reports", ca, caKey), ForbiddenStatus},
+		{"allowed by SAN", client("billing", ca, caKey), OKStatus},
+	}
+
+	for _, tt := range tests {
+		identity = nil
+		fastCtx := tlsRequestCtx(t, tt.cert)
+		handler(NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin)))
+
+		if status := fastCtx.Response.StatusCode(); status != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, status)
+		}
+		if (identity != nil) != (tt.status == OKStatus) {
+			t.Errorf("%s: unexpected client identity %v", tt.name, identity)
+		}
+	}
+
+	if identity == nil || identity.CommonName != "billing" || identity.DNSNames[0] != "billing.internal" {
+		t.Errorf("Expected billing identity, got %+v", identity)
+	}
+
+	// Plain HTTP requests have no certificate
+	fastCtx := &fasthttp.RequestCtx{}
+	handler(NewContext(fastCtx, container.NewContainer(), make(map[string]Plugin)))
+	if fastCtx.Response.StatusCode() != UnauthorizedStatus {
+		t.Errorf("Expected 401 without TLS, got %d", fastCtx.Response.StatusCode())
+	}
+}
+
+func TestNotFoundRunsMiddleware(t *testing.T) {
+	app := newTestApp(t)
+	app.Use(RecoveryMiddleware())
+	app.EnableCORS(CORSOptions{AllowOrigin: "*"})
+
+	var misses []string
+	app.GetEventBus().Subscribe("request.not_found", func(event *Event) error {
+		misses = append(misses, event.Data["path"].(string))
+		return nil
+	})
+
+	request := func(path string) *fasthttp.RequestCtx {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(path)
+		app.handleRequest(fastCtx)
+		return fastCtx
+	}
+
+	fastCtx := request("/missing")
+	if fastCtx.Response.StatusCode() != NotFoundStatus || string(fastCtx.Response.Body()) != "Not Found" {
+		t.Errorf("expected default 404, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+	if origin := string(fastCtx.Response.Header.Peek("Access-Control-Allow-Origin")); origin != "*" {
+		t.Errorf("expected CORS header on 404, got %q", origin)
+	}
+
+	app.NotFound(func(ctx *Context) error {
+		return ctx.Error(NotFoundStatus, "not_found", "No such endpoint")
+	})
+	fastCtx = request("/custom")
+	if fastCtx.Response.StatusCode() != NotFoundStatus || !strings.Contains(string(fastCtx.Response.Body()), "not_found") {
+		t.Errorf("expected custom 404, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+
+	app.NotFound(func(ctx *Context) error {
+		panic("broken not found handler")
+	})
+	fastCtx = request("/panic")
+	if fastCtx.Response.StatusCode() != InternalServerErrorStatus {
+		t.Errorf("expected recovered panic to give 500, got %d", fastCtx.Response.StatusCode())
+	}
+
+	if strings.Join(misses, ",") != "/missing,/custom,/panic" {
+		t.Errorf("expected not_found events for every miss, got %v", misses)
+	}
+}
+
+func TestSLAMiddleware(t *testing.T) {
+	app := newTestApp(t)
+	app.container.Register(EventBusService, app.GetEventBus())
+	app.Use(SLAMiddleware(time.Millisecond))
+
+	slow := func(ctx *Context) error {
+		time.Sleep(5 * time.Millisecond)
+		return ctx.String("done")
+	}
+	app.Get("/users/:id", slow)
+	app.Get("/reports/:id", slow, SLAMiddleware(time.Hour))
+
+	var overruns []SLAExceeded
+	SubscribeTyped(app.GetEventBus(), func(event SLAExceeded) error {
+		overruns = append(overruns, event)
+		return nil
+	})
+
+	for _, path := range []string{"/users/1", "/reports/1"} {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(path)
+		app.handleRequest(fastCtx)
+		if fastCtx.Response.StatusCode() != OKStatus {
+			t.Errorf("%s: expected the slow request to complete, got %d", path, fastCtx.Response.StatusCode())
+		}
+	}
+
+	if len(overruns) != 1 {
+		t.Fatalf("expected 1 overrun, got %d: %+v", len(overruns), overruns)
+	}
+	overrun := overruns[0]
+	if overrun.Route != "/users/:id" || overrun.Path != "/users/1" || overrun.Budget != time.Millisecond {
+		t.Errorf("unexpected overrun event: %+v", overrun)
+	}
+	if overrun.Elapsed < 5*time.Millisecond {
+		t.Errorf("expected elapsed >= 5ms, got %v", overrun.Elapsed)
+	}
+}
+
+type schemaUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+func (u schemaUser) Validate() error {
+	if u.Name == "" {
+		return ValidationErrors{"name": "is required"}
+	}
+	return nil
+}
+
+func TestResponseSchema(t *testing.T) {
+	app := newTestApp(t)
+	app.config.App.Debug = true
+	app.config.App.StrictResponseSchema = true
+
+	respond := func(body interface{}) HandlerFunc {
+		return func(ctx *Context) error { return ctx.writeJSON(body) }
+	}
+	app.Routes([]Route{
+		{Method: "GET", Path: "/valid", Handler: respond(Map{"id": 1, "name": "Ann"}), ResponseSchema: schemaUser{}},
+		{Method: "GET", Path: "/list", Handler: respond([]Map{{"id": 1, "name": "Ann"}, {"id": 2}}), ResponseSchema: []schemaUser{}},
+		{Method: "GET", Path: "/extra", Handler: respond(Map{"id": 1, "name": "Ann", "password": "x"}), ResponseSchema: schemaUser{}},
+		{Method: "GET", Path: "/missing", Handler: respond(Map{"name": "Ann"}), ResponseSchema: schemaUser{}},
+		{Method: "GET", Path: "/invalid", Handler: respond(Map{"id": 1, "name": ""}), ResponseSchema: &schemaUser{}},
+		{Method: "GET", Path: "/wrong-type", Handler: respond(Map{"id": "1", "name": "Ann"}), ResponseSchema: schemaUser{}},
+	})
+	app.Get("/middleware", respond(Map{"id": 1}), app.ResponseSchema(schemaUser{}))
+
+	request := func(path string) int {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(path)
+		app.handleRequest(fastCtx)
+		if fastCtx.Response.StatusCode() != OKStatus && !json.Valid(fastCtx.Response.Body()) {
+			t.Errorf("%s: expected a JSON error response, got %q", path, fastCtx.Response.Body())
+		}
+		return fastCtx.Response.StatusCode()
+	}
+
+	tests := map[string]int{
+		"/valid":      OKStatus,
+		"/list":       InternalServerErrorStatus,
+		"/extra":      InternalServerErrorStatus,
+		"/missing":    InternalServerErrorStatus,
+		"/invalid":    InternalServerErrorStatus,
+		"/wrong-type": InternalServerErrorStatus,
+		"/middleware": InternalServerErrorStatus,
+	}
+	for path, status := range tests {
+		if got := request(path); got != status {
+			t.Errorf("%s: expected %d, got %d", path, status, got)
+		}
+	}
+
+	// Outside debug mode schemas are not checked
+	app.config.App.Debug = false
+	app.Get("/production", respond(Map{"unexpected": true}), app.ResponseSchema(schemaUser{}))
+	if got := request("/production"); got != OKStatus {
+		t.Errorf("expected no-op outside debug mode, got %d", got)
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := newTestApp(t)
+	app.Use(RecoveryMiddleware())
+	app.Get("/fail", func(ctx *Context) error {
+		ctx.String("partial")
+		return errors.New("database unavailable")
+	})
+	app.Get("/conflict", func(ctx *Context) error {
+		return NewHTTPError(409, "conflict", "Already exists")
+	})
+	app.Get("/panic", func(ctx *Context) error {
+		panic("boom")
+	})
+
+	request := func(path string) *fasthttp.RequestCtx {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(path)
+		app.handleRequest(fastCtx)
+		return fastCtx
+	}
+
+	if fastCtx := request("/fail"); fastCtx.Response.StatusCode() != 500 || string(fastCtx.Response.Body()) != "Internal Server Error" {
+		t.Errorf("expected default 500 without an error handler, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+	if fastCtx := request("/panic"); fastCtx.Response.StatusCode() != 500 || string(fastCtx.Response.Body()) != "Internal Server Error" {
+		t.Errorf("expected default 500 for a panic, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+	// A returned HTTPError keeps its status without an error handler
+	if fastCtx := request("/conflict"); fastCtx.Response.StatusCode() != 409 {
+		t.Errorf("expected the HTTPError status 409, got %d", fastCtx.Response.StatusCode())
+	} else {
+		var body ErrorResponse
+		if err := json.Unmarshal(fastCtx.Response.Body(), &body); err != nil || body.Code != "conflict" || body.Message != "Already exists" {
+			t.Errorf("expected an ErrorResponse with code conflict, got %q", fastCtx.Response.Body())
+		}
+	}
+
+	app.ErrorHandler(func(ctx *Context, err error) {
+		status, code := ctx.StatusCode(), "internal_error"
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) {
+			status, code = httpErr.Status, httpErr.Code
+		}
+		ctx.Header("X-Error-Code", code)
+		ctx.Status(status).JSON(Map{"error": Map{"code": code}})
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{"/fail", 500, "internal_error"},
+		{"/conflict", 409, "conflict"},
+		{"/panic", 500, "internal_error"},
+	}
+	for _, tt := range tests {
+		fastCtx := request(tt.path)
+		if fastCtx.Response.StatusCode() != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, fastCtx.Response.StatusCode())
+		}
+		if header := string(fastCtx.Response.Header.Peek("X-Error-Code")); header != tt.code {
+			t.Errorf("%s: expected X-Error-Code %q, got %q", tt.path, tt.code, header)
+		}
+		var body struct {
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(fastCtx.Response.Body(), &body); err != nil || body.Error.Code != tt.code {
+			t.Errorf("%s: expected JSON envelope with code %q, got %q", tt.path, tt.code, fastCtx.Response.Body())
+		}
+	}
+
+	app.ErrorHandler(func(ctx *Context, err error) {
+		panic("broken error handler")
+	})
+	if fastCtx := request("/fail"); fastCtx.Response.StatusCode() != 500 || string(fastCtx.Response.Body()) != "Internal Server Error" {
+		t.Errorf("expected default 500 when the error handler panics, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+}
+
+func TestErrorFormatProblemJSON(t *testing.T) {
+	app := newTestApp(t)
+	app.SetErrorFormat(ErrorFormatProblemJSON)
+	app.Use(RecoveryMiddleware())
+	app.Get("/fail", func(ctx *Context) error {
+		return errors.New("database unavailable")
+	})
+	app.Get("/conflict", func(ctx *Context) error {
+		return &HTTPError{Status: 409, Code: "conflict", Message: "Already exists", Type: "https://example.com/problems/conflict"}
+	})
+	app.Get("/panic", func(ctx *Context) error {
+		panic("boom")
+	})
+	app.Get("/invalid", func(ctx *Context) error {
+		return ctx.Error(422, "validation_failed", "Invalid request", map[string]interface{}{"email": "is required"})
+	})
+	app.Get("/credit", func(ctx *Context) error {
+		return ctx.Problem(ProblemDetails{
+			Type:       "https://example.com/problems/out-of-credit",
+			Status:     403,
+			Detail:     "Your current balance is 30",
+			Extensions: map[string]interface{}{"balance": 30, "status": 200},
+		})
+	})
+
+	tests := []struct {
+		path    string
+		status  int
+		problem map[string]interface{}
+	}{
+		{"/fail", 500, map[string]interface{}{"type": "about:blank", "title": "Internal Server Error", "status": 500.0, "detail": "Internal Server Error", "instance": "/fail", "code": "internal_error"}},
+		{"/conflict", 409, map[string]interface{}{"type": "https://example.com/problems/conflict", "title": "Conflict", "status": 409.0, "detail": "Already exists", "instance": "/conflict", "code": "conflict"}},
+		{"/panic", 500, map[string]interface{}{"type": "about:blank", "title": "Internal Server Error", "status": 500.0, "detail": "Internal Server Error", "instance": "/panic", "code": "internal_error"}},
+		{"/invalid", 422, map[string]interface{}{"type": "about:blank", "title": "Unprocessable Entity", "status": 422.0, "detail": "Invalid request", "instance": "/invalid", "code": "validation_failed", "email": "is required"}},
+		{"/credit", 403, map[string]interface{}{"type": "https://example.com/problems/out-of-credit", "title": "Forbidden", "status": 403.0, "detail": "Your current balance is 30", "instance": "/credit", "balance": 30.0}},
+	}
+	for _, tt := range tests {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(tt.path)
+		app.handleRequest(fastCtx)
+
+		if fastCtx.Response.StatusCode() != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, fastCtx.Response.StatusCode())
+		}
+		if contentType := string(fastCtx.Response.Header.ContentType()); contentType != ProblemContentType {
+			t.Errorf("%s: expected Content-Type %s, got %s", tt.path, ProblemContentType, contentType)
+		}
+		var problem map[string]interface{}
+		if err := json.Unmarshal(fastCtx.Response.Body(), &problem); err != nil {
+			t.Fatalf("%s: invalid problem document %q: %v", tt.path, fastCtx.Response.Body(), err)
+		}
+		if !reflect.DeepEqual(problem, tt.problem) {
+			t.Errorf("%s: expected %v, got %v", tt.path, tt.problem, problem)
+		}
+	}
+
+	// A custom error handler still takes precedence
+	app.ErrorHandler(func(ctx *Context, err error) {
+		ctx.Status(500).String("custom")
+	})
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.SetRequestURI("/fail")
+	app.handleRequest(fastCtx)
+	if string(fastCtx.Response.Body()) != "custom" {
+		t.Errorf("expected the error handler to take precedence, got %q", fastCtx.Response.Body())
+	}
+}
+
+func TestOnErrorHooks(t *testing.T) {
+	app := newTestApp(t)
+	app.Use(RecoveryMiddleware())
+	app.Get("/ok", func(ctx *Context) error { return ctx.String("ok") })
+	app.Get("/fail", func(ctx *Context) error { return errors.New("database unavailable") })
+	app.Get("/panic", func(ctx *Context) error { panic("boom") })
+	app.Post("/invalid", func(ctx *Context) error {
+		var v struct{}
+		ctx.BindJSONValidated(&v)
+		return nil
+	})
+
+	var reported []string
+	app.OnError(func(ctx *Context, err error) {
+		reported = append(reported, ctx.Path()+": "+err.Error())
+		ctx.Set("error_id", "e-1")
+	})
+	app.OnError(func(ctx *Context, err error) {
+		panic("broken reporter")
+	})
+	app.OnError(func(ctx *Context, err error) {
+		if id, ok := ctx.Get("error_id"); ok {
+			ctx.Header("X-Error-ID", id.(string))
+		}
+	})
+
+	request := func(method, path string) *fasthttp.RequestCtx {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.Header.SetMethod(method)
+		fastCtx.Request.SetRequestURI(path)
+		fastCtx.Request.SetBodyString("{")
+		app.handleRequest(fastCtx)
+		return fastCtx
+	}
+
+	request("GET", "/ok")
+	request("POST", "/invalid")
+	if len(reported) != 0 {
+		t.Fatalf("expected no hooks for successful requests and client errors, got %v", reported)
+	}
+
+	for _, path := range []string{"/fail", "/panic"} {
+		fastCtx := request("GET", path)
+		if fastCtx.Response.StatusCode() != 500 {
+			t.Errorf("%s: expected 500, got %d", path, fastCtx.Response.StatusCode())
+		}
+		if id := string(fastCtx.Response.Header.Peek("X-Error-ID")); id != "e-1" {
+			t.Errorf("%s: expected hooks to enrich the response, got X-Error-ID %q", path, id)
+		}
+	}
+	if len(reported) != 2 || reported[0] != "/fail: database unavailable" || !strings.Contains(reported[1], "panic: boom") {
+		t.Errorf("unexpected reported errors %v", reported)
+	}
+
+	app.ErrorHandler(func(ctx *Context, err error) {
+		id, _ := ctx.Get("error_id")
+		ctx.Status(500).JSON(Map{"error_id": id})
+	})
+	if body := string(request("GET", "/fail").Response.Body()); !strings.Contains(body, `"error_id":"e-1"`) {
+		t.Errorf("expected the error handler to see hook values, got %q", body)
+	}
+}
@@ -0,0 +1,192 @@
+package gorgo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// clientCertKey is the context key of the verified *ClientCert
+const clientCertKey = "client_cert"
+
+// ClientCert is the identity of a verified TLS client certificate
+type ClientCert struct {
+	Subject        string
+	CommonName     string
+	DNSNames       []string
+	EmailAddresses []string
+	URIs           []string
+	Certificate    *x509.Certificate
+}
+
+// Names returns the common name followed by all subject alternative names
+func (c *ClientCert) Names() []string {
+	names := []string{c.CommonName}
+	names = append(names, c.DNSNames...)
+	names = append(names, c.EmailAddresses...)
+	return append(names, c.URIs...)
+}
+
+// MTLSOptions configuration for MTLSMiddleware
+type MTLSOptions struct {
+	// CAPool verifies client certificates. When nil, the chains verified
+	// during the TLS handshake (server.tls_client_ca_file) are trusted.
+	CAPool *x509.CertPool
+	// AllowedNames restricts access to certificates whose common name or
+	// one of its SANs is listed. Empty allows every verified certificate.
+	AllowedNames []string
+	// Authorize makes a custom access decision after verification, e.g.
+	// from the SAN URI of a SPIFFE identity
+	Authorize func(ctx *Context, cert *ClientCert) bool
+}
+
+// MTLSMiddleware requires a client certificate. Requests without one get
+// 401, requests whose certificate does not verify or is not allowed get
+// 403. The server must run with TLS; set server.tls_client_ca_file so the
+// handshake asks clients for their certificate.
+func MTLSMiddleware(options MTLSOptions) MiddlewareFunc {
+	allowed := make(map[string]bool, len(options.AllowedNames))
+	for _, name := range options.AllowedNames {
+		allowed[name] = true
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			state := ctx.fastCtx.TLSConnectionState()
+			if state == nil || len(state.PeerCertificates) == 0 {
+				return ctx.writeError(NewHTTPError(UnauthorizedStatus, "client_certificate_required", "A client certificate is required"))
+			}
+
+			if err := verifyClientCert(state, options.CAPool); err != nil {
+				return ctx.writeError(&HTTPError{
+					Status:  ForbiddenStatus,
+					Code:    "invalid_client_certificate",
+					Message: "Client certificate is not valid",
+					Err:     err,
+				})
+			}
+
+			cert := newClientCert(state.PeerCertificates[0])
+			if !clientCertAllowed(cert, allowed) || (options.Authorize != nil && !options.Authorize(ctx, cert)) {
+				return ctx.writeError(NewHTTPError(ForbiddenStatus, "client_not_allowed", "Client certificate is not allowed"))
+			}
+
+			ctx.Set(clientCertKey, cert)
+			return next(ctx)
+		}
+	}
+}
+
+// ClientCert returns the client certificate verified by MTLSMiddleware,
+// nil when the request has not passed it
+func (c *Context) ClientCert() *ClientCert {
+	if value, ok := c.Get(clientCertKey); ok {
+		if cert, ok := value.(*ClientCert); ok {
+			return cert
+		}
+	}
+	return nil
+}
+
+// LoadCertPool reads PEM encoded CA certificates into a pool
+func LoadCertPool(files ...string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, file := range files {
+		pem, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", file)
+		}
+	}
+	return pool, nil
+}
+
+// tlsConfig builds the server TLS configuration from the config file, nil
+// when TLS is not configured
+func (a *Application) tlsConfig() (*tls.Config, error) {
+	server := a.config.Server
+	if server.TLSCertFile == "" && server.TLSKeyFile == "" {
+		if server.TLSClientCAFile != "" {
+			return nil, errors.New("tls_client_ca_file requires tls_cert_file and tls_key_file")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(server.TLSCertFile, server.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if server.TLSClientCAFile != "" {
+		pool, err := LoadCertPool(server.TLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		// Certificates are optional in the handshake so that routes without
+		// MTLSMiddleware stay reachable; the middleware enforces them
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+// verifyClientCert verifies the peer certificate chain against pool, or
+// checks that the handshake already verified it when pool is nil
+func verifyClientCert(state *tls.ConnectionState, pool *x509.CertPool) error {
+	if pool == nil {
+		if len(state.VerifiedChains) == 0 {
+			return errors.New("certificate was not verified during the handshake")
+		}
+		return nil
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+func newClientCert(cert *x509.Certificate) *ClientCert {
+	uris := make([]string, 0, len(cert.URIs))
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	return &ClientCert{
+		Subject:        cert.Subject.String(),
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		URIs:           uris,
+		Certificate:    cert,
+	}
+}
+
+func clientCertAllowed(cert *ClientCert, allowed map[string]bool) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, name := range cert.Names() {
+		if allowed[name] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package gorgo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NDJSONError reports the line of a newline-delimited JSON body that failed
+// to parse or was rejected by the record handler
+type NDJSONError struct {
+	Line int
+	Err  error
+}
+
+func (e *NDJSONError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *NDJSONError) Unwrap() error {
+	return e.Err
+}
+
+// errInvalidNDJSONRecord is reported for lines that are not a single JSON value
+var errInvalidNDJSONRecord = errors.New("invalid JSON record")
+
+// NDJSON calls fn for each record of a newline-delimited JSON body, reading
+// one line at a time. With server.stream_request_body the body is not
+// buffered, so large bulk uploads use constant memory. Blank lines are
+// skipped. gzip and deflate bodies are decompressed on the fly; the
+// (decompressed) body is limited to server.max_request_body_size.
+//
+// Processing stops at the first invalid line, at an error from fn, or when
+// the body is too large, returning an *NDJSONError with the line number.
+// Records before it have been processed.
+//
+//	err := ctx.NDJSON(func(raw json.RawMessage) error {
+//	    var event Event
+//	    if err := json.Unmarshal(raw, &event); err != nil {
+//	        return err
+//	    }
+//	    return store.Insert(event)
+//	})
+func (c *Context) NDJSON(fn func(raw json.RawMessage) error) error {
+	var body io.Reader = c.BodyStream()
+
+	switch encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding"))); encoding {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return fmt.Errorf("malformed gzip body: %w", err)
+		}
+		defer reader.Close()
+		body = reader
+	case "deflate":
+		reader := flate.NewReader(body)
+		defer reader.Close()
+		body = reader
+	default:
+		return fmt.Errorf("%w: content encoding %s", ErrUnsupportedMediaType, encoding)
+	}
+
+	reader := bufio.NewReader(&bodyLimitReader{reader: body, remaining: int64(c.maxBodySize)})
+	for line := 1; ; line++ {
+		record, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return &NDJSONError{Line: line, Err: err}
+		}
+
+		if trimmed := bytes.TrimSpace(record); len(trimmed) > 0 {
+			if !json.Valid(trimmed) {
+				return &NDJSONError{Line: line, Err: errInvalidNDJSONRecord}
+			}
+			if fnErr := fn(json.RawMessage(trimmed)); fnErr != nil {
+				return &NDJSONError{Line: line, Err: fnErr}
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// bodyLimitReader fails with ErrBodyTooLarge once more than remaining
+// bytes are read
+type bodyLimitReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *bodyLimitReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	// Read one byte past the limit to detect overflow
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, ErrBodyTooLarge
+	}
+	return n, err
+}
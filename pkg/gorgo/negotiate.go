@@ -0,0 +1,155 @@
+package gorgo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// renderOffers are the media types Render can produce, in order of preference
+var renderOffers = []string{"application/json", "application/xml", "text/xml", "text/plain"}
+
+// mediaRange is one entry of an Accept header
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// Negotiate returns the offer that best matches the Accept header. Each
+// offer gets the quality of the most specific matching media range, e.g.
+// "text/html" over "text/*" over "*/*"; the offer with the highest quality
+// wins and earlier offers win ties. Without an Accept header, or when no
+// offer is acceptable, the first offer is returned.
+//
+//	switch ctx.Negotiate("application/json", "text/html") {
+//	case "text/html":
+//	    return ctx.HTML(page)
+//	default:
+//	    return ctx.JSON(data)
+//	}
+func (c *Context) Negotiate(offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	ranges := parseAccept(c.GetHeader("Accept"))
+	best, bestQ := offers[0], 0.0
+	for _, offer := range offers {
+		if q := offerQuality(offer, ranges); q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// Render writes data as JSON, XML or plain text, whichever the client
+// prefers according to the Accept header, defaulting to JSON. Plain text
+// uses fmt.Sprint. Vary: Accept is set so caches keep the variants apart.
+func (c *Context) Render(data interface{}) error {
+	c.fastCtx.Response.Header.Add("Vary", "Accept")
+
+	switch offer := c.Negotiate(renderOffers...); offer {
+	case "application/xml", "text/xml":
+		if err := c.XML(data); err != nil {
+			return err
+		}
+		c.fastCtx.Response.Header.SetContentType(offer)
+		return nil
+	case "text/plain":
+		c.fastCtx.Response.Header.SetContentType("text/plain; charset=utf-8")
+		c.fastCtx.SetBodyString(fmt.Sprint(data))
+		return nil
+	default:
+		return c.writeJSON(data)
+	}
+}
+
+// parseAccept parses an Accept header, skipping malformed ranges. An empty
+// header accepts anything.
+func parseAccept(header string) []mediaRange {
+	if strings.TrimSpace(header) == "" {
+		return []mediaRange{{typ: "*", subtype: "*", q: 1}}
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok || typ == "" || subtype == "" {
+			continue
+		}
+
+		r := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.ToLower(strings.TrimSpace(key)) != "q" {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q >= 0 && q <= 1 {
+				r.q = q
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// offerQuality returns the quality of the most specific range matching offer
+func offerQuality(offer string, ranges []mediaRange) float64 {
+	mediaType, _, _ := strings.Cut(offer, ";")
+	typ, subtype, _ := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// xmlMap encodes a Map as elements named after its keys, in key order
+type xmlMap struct {
+	name string
+	data map[string]interface{}
+}
+
+func (m xmlMap) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Local: m.name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var value interface{} = m.data[key]
+		switch nested := value.(type) {
+		case Map:
+			value = xmlMap{name: key, data: nested}
+		case map[string]interface{}:
+			value = xmlMap{name: key, data: nested}
+		}
+		if err := e.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: key}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
@@ -0,0 +1,91 @@
+package gorgo
+
+import (
+	"math"
+	"strconv"
+)
+
+// PaginationOptions configures how pagination query parameters are read
+type PaginationOptions struct {
+	PageParam    string // Defaults to "page"
+	LimitParam   string // Defaults to "limit"
+	OffsetParam  string // Defaults to "offset"
+	DefaultLimit int    // Defaults to 20
+	MaxLimit     int    // Defaults to 100
+}
+
+// Pagination is the parsed pagination state of a request
+type Pagination struct {
+	Page   int `json:"page"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// DefaultPaginationOptions returns default pagination settings
+func DefaultPaginationOptions() PaginationOptions {
+	return PaginationOptions{
+		PageParam:    "page",
+		LimitParam:   "limit",
+		OffsetParam:  "offset",
+		DefaultLimit: 20,
+		MaxLimit:     100,
+	}
+}
+
+// Pagination reads page/limit or offset/limit query parameters. Invalid
+// values, including pages whose offset would overflow, fall back to the
+// defaults and the limit is clamped to MaxLimit.
+// When an offset is given it takes precedence over the page.
+func (c *Context) Pagination(options PaginationOptions) Pagination {
+	defaults := DefaultPaginationOptions()
+	if options.PageParam == "" {
+		options.PageParam = defaults.PageParam
+	}
+	if options.LimitParam == "" {
+		options.LimitParam = defaults.LimitParam
+	}
+	if options.OffsetParam == "" {
+		options.OffsetParam = defaults.OffsetParam
+	}
+	if options.MaxLimit <= 0 {
+		options.MaxLimit = defaults.MaxLimit
+	}
+	if options.DefaultLimit <= 0 || options.DefaultLimit > options.MaxLimit {
+		options.DefaultLimit = min(defaults.DefaultLimit, options.MaxLimit)
+	}
+
+	limit := c.positiveQueryInt(options.LimitParam, options.DefaultLimit)
+	if limit > options.MaxLimit {
+		limit = options.MaxLimit
+	}
+
+	if offset, err := strconv.Atoi(c.Query(options.OffsetParam)); err == nil && offset >= 0 {
+		page := offset / limit
+		if page < math.MaxInt {
+			page++
+		}
+		return Pagination{
+			Page:   page,
+			Limit:  limit,
+			Offset: offset,
+		}
+	}
+
+	page := c.positiveQueryInt(options.PageParam, 1)
+	if page-1 > math.MaxInt/limit {
+		page = 1
+	}
+	return Pagination{
+		Page:   page,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	}
+}
+
+func (c *Context) positiveQueryInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(c.Query(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,373 @@
+package gorgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ApplyMergePatch applies an RFC 7386 JSON Merge Patch to the original
+// document and returns the patched document
+func ApplyMergePatch(original, patch []byte) ([]byte, error) {
+	doc, err := decodeJSONDocument(original)
+	if err != nil {
+		return nil, fmt.Errorf("invalid original document: %w", err)
+	}
+
+	patchDoc, err := decodeJSONDocument(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	return json.Marshal(mergePatch(doc, patchDoc))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// JSONPatchOperation is a single RFC 6902 operation
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch to the original document
+// and returns the patched document. Operations are applied in order and
+// the whole patch fails if any operation fails.
+func ApplyJSONPatch(original, patch []byte) ([]byte, error) {
+	doc, err := decodeJSONDocument(original)
+	if err != nil {
+		return nil, fmt.Errorf("invalid original document: %w", err)
+	}
+
+	var operations []JSONPatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch: expected an array of operations: %w", err)
+	}
+
+	for i, op := range operations {
+		doc, err = applyPatchOperation(doc, op)
+		if err != nil {
+			return nil, fmt.Errorf("JSON patch operation %d (%s %q): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+func applyPatchOperation(doc interface{}, op JSONPatchOperation) (interface{}, error) {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("missing value")
+		}
+		value, err := decodeJSONDocument(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+
+		switch op.Op {
+		case "add":
+			return patchAdd(doc, path, value)
+		case "replace":
+			return patchReplace(doc, path, value)
+		default:
+			current, err := patchGet(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonEqual(current, value) {
+				return nil, errors.New("test failed: value does not match")
+			}
+			return doc, nil
+		}
+
+	case "remove":
+		return patchRemove(doc, path)
+
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+
+		value, err := patchGet(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+
+		if op.Op == "copy" {
+			if value, err = deepCopyJSON(value); err != nil {
+				return nil, err
+			}
+			return patchAdd(doc, path, value)
+		}
+
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+		if doc, err = patchRemove(doc, from); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, value)
+
+	case "":
+		return nil, errors.New("missing op")
+	default:
+		return nil, fmt.Errorf("unknown op %q", op.Op)
+	}
+}
+
+func patchGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		child, err := jsonChild(doc, token)
+		if err != nil {
+			return nil, err
+		}
+		doc = child
+	}
+	return doc, nil
+}
+
+func patchAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modifyJSON(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[key] = value
+			return node, nil
+		case []interface{}:
+			index := len(node)
+			if key != "-" {
+				var err error
+				if index, err = arrayIndex(key, len(node)+1); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("cannot add %q to a scalar value", key)
+		}
+	})
+}
+
+func patchRemove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the document root")
+	}
+
+	return modifyJSON(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[key]; !ok {
+				return nil, fmt.Errorf("path not found: member %q does not exist", key)
+			}
+			delete(node, key)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(node))
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:index], node[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove %q from a scalar value", key)
+		}
+	})
+}
+
+func patchReplace(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return modifyJSON(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[key]; !ok {
+				return nil, fmt.Errorf("path not found: member %q does not exist", key)
+			}
+			node[key] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(node))
+			if err != nil {
+				return nil, err
+			}
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("cannot replace %q in a scalar value", key)
+		}
+	})
+}
+
+// modifyJSON walks to the parent of the last path token and applies fn to it,
+// storing the (possibly reallocated) parent back into its own parent
+func modifyJSON(doc interface{}, path []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := jsonChild(doc, path[0])
+	if err != nil {
+		return nil, err
+	}
+
+	newChild, err := modifyJSON(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = newChild
+	case []interface{}:
+		index, _ := arrayIndex(path[0], len(node))
+		node[index] = newChild
+	}
+	return doc, nil
+}
+
+func jsonChild(doc interface{}, token string) (interface{}, error) {
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("path not found: member %q does not exist", token)
+		}
+		return child, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(node))
+		if err != nil {
+			return nil, err
+		}
+		return node[index], nil
+	default:
+		return nil, fmt.Errorf("path not found: cannot traverse %q in a scalar value", token)
+	}
+}
+
+// arrayIndex parses an array index token, which must be in [0, length)
+func arrayIndex(token string, length int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index >= length {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+// parseJSONPointer parses an RFC 6901 JSON Pointer into reference tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with '/'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func decodeJSONDocument(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return doc, nil
+}
+
+func deepCopyJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONDocument(data)
+}
+
+// jsonEqual compares decoded JSON values, treating numbers by value
+func jsonEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, errA := av.Float64()
+		bf, errB := bv.Float64()
+		return errA == nil && errB == nil && af == bf
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			other, ok := bv[k]
+			if !ok || !jsonEqual(v, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
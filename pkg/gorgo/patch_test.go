@@ -0,0 +1,78 @@
+package gorgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	original := []byte(`{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"]}`)
+	patch := []byte(`{"title":"Hello!","phoneNumber":"+01-123-456-7890","author":{"familyName":null},"tags":["example"]}`)
+
+	result, err := ApplyMergePatch(original, patch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"author":{"givenName":"John"},"phoneNumber":"+01-123-456-7890","tags":["example"],"title":"Hello!"}`
+	if string(result) != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+
+	if _, err := ApplyMergePatch(original, []byte(`{"title":`)); err == nil {
+		t.Error("Expected error for malformed merge patch")
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	original := []byte(`{"foo":["bar","baz"],"count":1,"nested":{"a":1}}`)
+	patch := []byte(`[
+		{"op":"test","path":"/count","value":1.0},
+		{"op":"add","path":"/foo/1","value":"qux"},
+		{"op":"add","path":"/foo/-","value":"end"},
+		{"op":"remove","path":"/foo/0"},
+		{"op":"replace","path":"/count","value":2},
+		{"op":"copy","from":"/nested","path":"/copied"},
+		{"op":"move","from":"/nested/a","path":"/moved"}
+	]`)
+
+	result, err := ApplyJSONPatch(original, patch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := `{"copied":{"a":1},"count":2,"foo":["qux","baz","end"],"moved":1,"nested":{}}`
+	if string(result) != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+}
+
+func TestApplyJSONPatch_Errors(t *testing.T) {
+	original := []byte(`{"foo":["bar"]}`)
+
+	tests := []struct {
+		patch    string
+		contains string
+	}{
+		{`{"op":"add"}`, "expected an array"},
+		{`[{"path":"/foo"}]`, "missing op"},
+		{`[{"op":"frobnicate","path":"/foo"}]`, "unknown op"},
+		{`[{"op":"add","path":"/bar"}]`, "missing value"},
+		{`[{"op":"add","path":"foo","value":1}]`, "must start with '/'"},
+		{`[{"op":"remove","path":"/missing"}]`, "path not found"},
+		{`[{"op":"replace","path":"/foo/5","value":1}]`, "out of bounds"},
+		{`[{"op":"test","path":"/foo/0","value":"baz"}]`, "test failed"},
+		{`[{"op":"move","from":"/foo","path":"/foo/0"}]`, "into one of its children"},
+	}
+
+	for _, tt := range tests {
+		_, err := ApplyJSONPatch(original, []byte(tt.patch))
+		if err == nil {
+			t.Errorf("Expected error for patch %s", tt.patch)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.contains) {
+			t.Errorf("Expected error containing %q for patch %s, got %v", tt.contains, tt.patch, err)
+		}
+	}
+}
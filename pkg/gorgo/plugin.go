@@ -2,9 +2,16 @@ package gorgo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"reflect"
+	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/GorgoFramework/gorgo/internal/container"
 )
@@ -32,13 +39,50 @@ const (
 	StateStopping
 	StateStopped
 	StateError
+	// StateDisabled plugins are registered but turned off with enabled = false
+	StateDisabled
 )
 
+func (s PluginState) String() string {
+	switch s {
+	case StateInitializing:
+		return "initializing"
+	case StateInitialized:
+		return "initialized"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateError:
+		return "error"
+	case StateDisabled:
+		return "disabled"
+	default:
+		return "uninitialized"
+	}
+}
+
 // Event represents an event in the system
 type Event struct {
 	Name string
 	Data map[string]interface{}
 	ctx  context.Context
+	// payload is the struct of events published with PublishTyped
+	payload interface{}
+}
+
+// Context returns the context the event was published with. During
+// shutdown it is canceled when the shutdown timeout expires, so handlers
+// doing slow work should stop when it is done.
+func (e *Event) Context() context.Context {
+	if e.ctx == nil {
+		return context.Background()
+	}
+	return e.ctx
 }
 
 // EventHandler event handler
@@ -53,6 +97,10 @@ type PluginMetadata struct {
 	Dependencies []string
 	Priority     PluginPriority
 	Tags         []string
+	// Critical plugins abort application startup when they fail to start.
+	// Failures of non-critical plugins are logged and the plugin is put
+	// into StateError.
+	Critical bool
 }
 
 // LifecycleHooks defines lifecycle hooks
@@ -70,11 +118,22 @@ type MiddlewareProvider interface {
 	GetMiddleware() []MiddlewareFunc
 }
 
+// RouteProvider allows a plugin to register routes, e.g. endpoints it serves
+type RouteProvider interface {
+	GetRoutes() []Route
+}
+
 // EventSubscriber allows a plugin to subscribe to events
 type EventSubscriber interface {
 	GetEventSubscriptions() map[string]EventHandler
 }
 
+// EventPublisher allows a plugin to publish its own events.
+// SetEventBus is called before Initialize.
+type EventPublisher interface {
+	SetEventBus(eventBus *EventBus)
+}
+
 // ConfigurablePlugin allows a plugin to validate and handle configuration
 type ConfigurablePlugin interface {
 	ValidateConfig(config map[string]interface{}) error
@@ -92,6 +151,11 @@ type ServiceProvider interface {
 	GetServices() map[string]interface{}
 }
 
+// stateSetter is implemented by plugins embedding BasePlugin
+type stateSetter interface {
+	SetState(state PluginState)
+}
+
 // Plugin extended plugin interface
 type Plugin interface {
 	GetMetadata() PluginMetadata
@@ -146,6 +210,14 @@ func (p *BasePlugin) GetState() PluginState {
 	return p.state
 }
 
+// SetState sets the plugin state. It is used by the PluginManager
+// to mark plugins that failed to start.
+func (p *BasePlugin) SetState(state PluginState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state = state
+}
+
 // Default hook implementations
 func (p *BasePlugin) OnBeforeInit(ctx context.Context) error  { return nil }
 func (p *BasePlugin) OnAfterInit(ctx context.Context) error   { return nil }
@@ -156,35 +228,167 @@ func (p *BasePlugin) OnAfterStop(ctx context.Context) error   { return nil }
 
 // EventBus event system
 type EventBus struct {
-	subscribers map[string][]EventHandler
+	subscribers map[string][]subscription
+	// patterns lists the subscribed "prefix*" patterns, most specific first
+	patterns    []string
+	nextID      SubscriptionID
+	asyncErrors func(eventName string, err error)
 	mu          sync.RWMutex
+
+	// dispatcher runs PublishAsync handlers while the application serves
+	dispatcher atomic.Pointer[eventDispatcher]
+}
+
+// SubscriptionID identifies a handler subscribed to the EventBus
+type SubscriptionID uint64
+
+type subscription struct {
+	id      SubscriptionID
+	handler EventHandler
 }
 
 func NewEventBus() *EventBus {
 	return &EventBus{
-		subscribers: make(map[string][]EventHandler),
+		subscribers: make(map[string][]subscription),
+	}
+}
+
+// Subscribe adds handler for eventName and returns an ID for Unsubscribe.
+// A trailing "*" makes eventName a pattern matching every event with that
+// prefix, e.g. "request.*" or "*" for all events. An event runs its exact
+// subscribers first, then the matching patterns from the longest to the
+// shortest, each in subscription order.
+func (eb *EventBus) Subscribe(eventName string, handler EventHandler) SubscriptionID {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.nextID++
+	if _, exists := eb.subscribers[eventName]; !exists && strings.HasSuffix(eventName, "*") {
+		eb.addPattern(eventName)
 	}
+	eb.subscribers[eventName] = append(eb.subscribers[eventName], subscription{id: eb.nextID, handler: handler})
+	return eb.nextID
 }
 
-func (eb *EventBus) Subscribe(eventName string, handler EventHandler) {
+// Unsubscribe removes a handler added with Subscribe. It reports whether
+// the handler was subscribed. Publishes already running may still call it.
+func (eb *EventBus) Unsubscribe(id SubscriptionID) bool {
 	eb.mu.Lock()
 	defer eb.mu.Unlock()
-	eb.subscribers[eventName] = append(eb.subscribers[eventName], handler)
+
+	for eventName, subs := range eb.subscribers {
+		for i, sub := range subs {
+			if sub.id != id {
+				continue
+			}
+			if len(subs) == 1 {
+				delete(eb.subscribers, eventName)
+				eb.removePattern(eventName)
+				return true
+			}
+			// Copy so that publishes iterating the old slice are unaffected
+			remaining := make([]subscription, 0, len(subs)-1)
+			eb.subscribers[eventName] = append(append(remaining, subs[:i]...), subs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// addPattern adds a pattern to the sorted pattern list
+func (eb *EventBus) addPattern(pattern string) {
+	patterns := append(append(make([]string, 0, len(eb.patterns)+1), eb.patterns...), pattern)
+	sort.SliceStable(patterns, func(i, j int) bool { return len(patterns[i]) > len(patterns[j]) })
+	eb.patterns = patterns
+}
+
+// removePattern removes a pattern without subscribers left
+func (eb *EventBus) removePattern(pattern string) {
+	for i, p := range eb.patterns {
+		if p == pattern {
+			eb.patterns = append(append(make([]string, 0, len(eb.patterns)-1), eb.patterns[:i]...), eb.patterns[i+1:]...)
+			return
+		}
+	}
+}
+
+// handlers returns the exact and pattern subscribers of an event, in the
+// order they run. The caller holds eb.mu.
+func (eb *EventBus) handlers(eventName string) []subscription {
+	handlers := eb.subscribers[eventName]
+	copied := false
+	for _, pattern := range eb.patterns {
+		if pattern == eventName || !strings.HasPrefix(eventName, pattern[:len(pattern)-1]) {
+			continue
+		}
+		if !copied {
+			// Don't append to the subscribed slice
+			handlers = append(make([]subscription, 0, len(handlers)+len(eb.subscribers[pattern])), handlers...)
+			copied = true
+		}
+		handlers = append(handlers, eb.subscribers[pattern]...)
+	}
+	return handlers
 }
 
 func (eb *EventBus) Publish(ctx context.Context, eventName string, data map[string]interface{}) error {
+	return eb.publish(ctx, eventName, data, nil)
+}
+
+// PublishAsync publishes an event without waiting for its handlers. While
+// the application serves, the handlers run on the event worker pool
+// (app.event_workers) and the event is dropped when the buffer is full;
+// otherwise they run in a new goroutine. Handler errors go to the
+// OnAsyncError handler. ctx is passed on as is, so handlers may see it
+// canceled when the caller returned.
+func (eb *EventBus) PublishAsync(ctx context.Context, eventName string, data map[string]interface{}) {
+	event := queuedEvent{ctx: ctx, name: eventName, data: data, async: true}
+	if d := eb.dispatcher.Load(); d != nil {
+		d.dispatch(event)
+		return
+	}
+	Go(func() { eb.publishQueued(event) })
+}
+
+// OnAsyncError sets the handler for errors returned by handlers of
+// asynchronously published events. By default they are logged.
+func (eb *EventBus) OnAsyncError(handler func(eventName string, err error)) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.asyncErrors = handler
+}
+
+// publishQueued publishes an event off the caller's goroutine and reports
+// handler errors, which have no caller to return to
+func (eb *EventBus) publishQueued(event queuedEvent) {
+	err := eb.publish(event.ctx, event.name, event.data, event.payload)
+	if err == nil {
+		return
+	}
+
 	eb.mu.RLock()
-	handlers := eb.subscribers[eventName]
+	handler := eb.asyncErrors
+	eb.mu.RUnlock()
+	if handler != nil {
+		handler(event.name, err)
+		return
+	}
+	log.Printf("Async %v", err)
+}
+
+func (eb *EventBus) publish(ctx context.Context, eventName string, data map[string]interface{}, payload interface{}) error {
+	eb.mu.RLock()
+	handlers := eb.handlers(eventName)
 	eb.mu.RUnlock()
 
 	event := &Event{
-		Name: eventName,
-		Data: data,
-		ctx:  ctx,
+		Name:    eventName,
+		Data:    data,
+		ctx:     ctx,
+		payload: payload,
 	}
 
-	for _, handler := range handlers {
-		if err := handler(event); err != nil {
+	for _, sub := range handlers {
+		if err := sub.handler(event); err != nil {
 			return fmt.Errorf("event handler error for %s: %w", eventName, err)
 		}
 	}
@@ -192,22 +396,109 @@ func (eb *EventBus) Publish(ctx context.Context, eventName string, data map[stri
 	return nil
 }
 
+// publishBounded publishes an event like Publish but stops waiting for the
+// handlers when ctx is done. A handler still running then is logged and
+// abandoned along with the handlers after it; handlers that honor
+// event.Context() see the cancellation and can return early.
+func (eb *EventBus) publishBounded(ctx context.Context, eventName string, data map[string]interface{}) error {
+	eb.mu.RLock()
+	handlers := eb.handlers(eventName)
+	eb.mu.RUnlock()
+
+	event := &Event{Name: eventName, Data: data, ctx: ctx}
+	for i, sub := range handlers {
+		done := make(chan error, 1)
+		handler := sub.handler
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					done <- fmt.Errorf("panic: %v", r)
+				}
+			}()
+			done <- handler(event)
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				return fmt.Errorf("event handler error for %s: %w", eventName, err)
+			}
+		case <-ctx.Done():
+			log.Printf("Event %s: handler %s did not finish in time: %v", eventName, handlerName(sub.handler), ctx.Err())
+			for _, skipped := range handlers[i+1:] {
+				log.Printf("Event %s: handler %s skipped", eventName, handlerName(skipped.handler))
+			}
+			return fmt.Errorf("event handlers for %s did not finish: %w", eventName, ctx.Err())
+		}
+	}
+	return nil
+}
+
+// handlerName returns the function name of an event handler for logs,
+// e.g. "github.com/acme/app/plugins/cache.(*Plugin).onAppStopping-fm"
+func handlerName(handler EventHandler) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "unknown"
+}
+
+// DefaultPluginTimeout bounds each plugin's start and stop unless the
+// plugin config sets start_timeout or stop_timeout
+const DefaultPluginTimeout = 30 * time.Second
+
+// ErrPluginTimeout is returned when a plugin does not start or stop in time
+var ErrPluginTimeout = errors.New("plugin lifecycle timeout")
+
+// pluginTimeouts are the lifecycle timeouts of a plugin
+type pluginTimeouts struct {
+	start time.Duration
+	stop  time.Duration
+}
+
 // PluginManager manages plugins
 type PluginManager struct {
-	plugins   map[string]Plugin
-	eventBus  *EventBus
+	plugins  map[string]Plugin
+	disabled map[string]bool
+	timeouts map[string]pluginTimeouts
+	eventBus *EventBus
+	// subscriptions holds the event handlers of each plugin while it is
+	// initialized or running
+	subscriptions map[string][]SubscriptionID
+	// serviceOwners maps container keys to the plugin that registered them
+	serviceOwners map[string]string
+	// started holds the plugins StartPlugins tried to start, which
+	// StopPlugins stops
+	started   map[string]bool
 	container *container.Container
 	mu        sync.RWMutex
+
+	defaultTimeout time.Duration
 }
 
 func NewPluginManager(container *container.Container) *PluginManager {
 	return &PluginManager{
 		plugins:   make(map[string]Plugin),
+		disabled:  make(map[string]bool),
+		timeouts:  make(map[string]pluginTimeouts),
 		eventBus:  NewEventBus(),
 		container: container,
+
+		subscriptions:  make(map[string][]SubscriptionID),
+		serviceOwners:  make(map[string]string),
+		started:        make(map[string]bool),
+		defaultTimeout: DefaultPluginTimeout,
 	}
 }
 
+// SetLifecycleTimeout changes the default start and stop timeout of
+// plugins that don't configure their own
+func (pm *PluginManager) SetLifecycleTimeout(timeout time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.defaultTimeout = timeout
+}
+
 func (pm *PluginManager) RegisterPlugin(plugin Plugin) error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
@@ -221,13 +512,49 @@ func (pm *PluginManager) RegisterPlugin(plugin Plugin) error {
 		}
 	}
 
+	// Replacing a registered plugin can close a dependency cycle
+	previous, replaced := pm.plugins[metadata.Name]
 	pm.plugins[metadata.Name] = plugin
+	if cycle := pm.cycleThrough(metadata.Name, metadata.Name, nil); cycle != nil {
+		if replaced {
+			pm.plugins[metadata.Name] = previous
+		} else {
+			delete(pm.plugins, metadata.Name)
+		}
+		return fmt.Errorf("circular dependency detected: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// cycleThrough returns a dependency path from name back to target, if
+// there is one. The caller holds pm.mu.
+func (pm *PluginManager) cycleThrough(name, target string, path []string) []string {
+	path = append(path, name)
+	plugin, exists := pm.plugins[name]
+	if !exists {
+		return nil
+	}
+	for _, dep := range plugin.GetMetadata().Dependencies {
+		if dep == target {
+			return append(path, dep)
+		}
+		if len(path) > len(pm.plugins) {
+			// Another cycle not through target
+			return nil
+		}
+		if cycle := pm.cycleThrough(dep, target, path); cycle != nil {
+			return cycle
+		}
+	}
 	return nil
 }
 
 func (pm *PluginManager) InitializePlugins(configs map[string]map[string]interface{}) error {
-	// Sort plugins by priority and dependencies
-	sortedPlugins := pm.getSortedPlugins()
+	// Sort plugins by dependencies and priority
+	sortedPlugins, err := pm.sortPlugins()
+	if err != nil {
+		return err
+	}
 
 	for _, plugin := range sortedPlugins {
 		metadata := plugin.GetMetadata()
@@ -236,6 +563,18 @@ func (pm *PluginManager) InitializePlugins(configs map[string]map[string]interfa
 			config = make(map[string]interface{})
 		}
 
+		// Plugins disabled in config stay registered but are skipped entirely
+		if !GetBoolConfig(config, "enabled", true) {
+			pm.disablePlugin(plugin)
+			continue
+		}
+		for _, dep := range metadata.Dependencies {
+			if pm.isDisabled(dep) {
+				return fmt.Errorf("plugin %s depends on disabled plugin %s", metadata.Name, dep)
+			}
+		}
+		pm.setTimeouts(metadata.Name, config)
+
 		// Configuration validation
 		if configurable, ok := plugin.(ConfigurablePlugin); ok {
 			if err := configurable.ValidateConfig(config); err != nil {
@@ -250,26 +589,26 @@ func (pm *PluginManager) InitializePlugins(configs map[string]map[string]interfa
 			}
 		}
 
+		// Event bus injection
+		if publisher, ok := plugin.(EventPublisher); ok {
+			publisher.SetEventBus(pm.eventBus)
+		}
+
 		// Initialization
+		pm.setState(plugin, StateInitializing)
 		if err := plugin.Initialize(pm.container, config); err != nil {
 			return fmt.Errorf("initialization failed for plugin %s: %w", metadata.Name, err)
 		}
 
 		// Service registration
 		if serviceProvider, ok := plugin.(ServiceProvider); ok {
-			services := serviceProvider.GetServices()
-			for name, service := range services {
-				pm.container.Register(name, service)
+			if err := pm.registerServices(metadata.Name, serviceProvider.GetServices()); err != nil {
+				return fmt.Errorf("service registration failed for plugin %s: %w", metadata.Name, err)
 			}
 		}
 
 		// Event subscription
-		if subscriber, ok := plugin.(EventSubscriber); ok {
-			subscriptions := subscriber.GetEventSubscriptions()
-			for eventName, handler := range subscriptions {
-				pm.eventBus.Subscribe(eventName, handler)
-			}
-		}
+		pm.subscribe(plugin)
 
 		// Post-initialization hooks
 		if hooks, ok := plugin.(LifecycleHooks); ok {
@@ -282,29 +621,37 @@ func (pm *PluginManager) InitializePlugins(configs map[string]map[string]interfa
 	return nil
 }
 
+// StartPlugins starts the enabled plugins in dependency order. Each start
+// runs with a deadline (start_timeout); its context is canceled once Start
+// returns, so background work must detach with context.WithoutCancel.
 func (pm *PluginManager) StartPlugins(ctx context.Context) error {
-	sortedPlugins := pm.getSortedPlugins()
+	sortedPlugins := pm.getEnabledPlugins()
 
 	for _, plugin := range sortedPlugins {
 		metadata := plugin.GetMetadata()
 
-		// Pre-start hooks
-		if hooks, ok := plugin.(LifecycleHooks); ok {
-			if err := hooks.OnBeforeStart(ctx); err != nil {
-				return fmt.Errorf("OnBeforeStart failed for plugin %s: %w", metadata.Name, err)
+		// A plugin that failed or timed out may still hold resources
+		pm.mu.Lock()
+		pm.started[metadata.Name] = true
+		pm.mu.Unlock()
+
+		err := pm.withTimeout(ctx, metadata.Name, "start", func(ctx context.Context) error {
+			return pm.startPlugin(ctx, plugin)
+		})
+		if err != nil {
+			if metadata.Critical {
+				return err
 			}
-		}
 
-		// Start
-		if err := plugin.Start(ctx); err != nil {
-			return fmt.Errorf("start failed for plugin %s: %w", metadata.Name, err)
-		}
+			// Non-critical plugins must not prevent the application from running
+			log.Printf("Non-critical plugin %s failed to start: %v", metadata.Name, err)
+			pm.setState(plugin, StateError)
 
-		// Post-start hooks
-		if hooks, ok := plugin.(LifecycleHooks); ok {
-			if err := hooks.OnAfterStart(ctx); err != nil {
-				return fmt.Errorf("OnAfterStart failed for plugin %s: %w", metadata.Name, err)
-			}
+			pm.eventBus.Publish(ctx, "plugin.start_failed", map[string]interface{}{
+				"plugin": metadata.Name,
+				"error":  err.Error(),
+			})
+			continue
 		}
 
 		// Publish plugin started event
@@ -316,30 +663,66 @@ func (pm *PluginManager) StartPlugins(ctx context.Context) error {
 	return nil
 }
 
+func (pm *PluginManager) startPlugin(ctx context.Context, plugin Plugin) error {
+	metadata := plugin.GetMetadata()
+	pm.setState(plugin, StateStarting)
+
+	// Resubscribe plugins started again after a stop
+	pm.subscribe(plugin)
+
+	// Pre-start hooks
+	if hooks, ok := plugin.(LifecycleHooks); ok {
+		if err := hooks.OnBeforeStart(ctx); err != nil {
+			return fmt.Errorf("OnBeforeStart failed for plugin %s: %w", metadata.Name, err)
+		}
+	}
+
+	// Start
+	if err := plugin.Start(ctx); err != nil {
+		return fmt.Errorf("start failed for plugin %s: %w", metadata.Name, err)
+	}
+
+	// Post-start hooks
+	if hooks, ok := plugin.(LifecycleHooks); ok {
+		if err := hooks.OnAfterStart(ctx); err != nil {
+			return fmt.Errorf("OnAfterStart failed for plugin %s: %w", metadata.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// StopPlugins stops the plugins StartPlugins tried to start, in reverse
+// order, so it also cleans up after a startup that failed part way. A plugin
+// that exceeds its stop_timeout is skipped so the others still stop; the
+// timeouts are returned together.
 func (pm *PluginManager) StopPlugins(ctx context.Context) error {
 	// Stop in reverse order
-	sortedPlugins := pm.getSortedPlugins()
+	var timedOut []error
+	sortedPlugins := pm.getEnabledPlugins()
 	for i := len(sortedPlugins) - 1; i >= 0; i-- {
 		plugin := sortedPlugins[i]
 		metadata := plugin.GetMetadata()
 
-		// Pre-stop hooks
-		if hooks, ok := plugin.(LifecycleHooks); ok {
-			if err := hooks.OnBeforeStop(ctx); err != nil {
-				return fmt.Errorf("OnBeforeStop failed for plugin %s: %w", metadata.Name, err)
-			}
+		pm.mu.Lock()
+		started := pm.started[metadata.Name]
+		delete(pm.started, metadata.Name)
+		pm.mu.Unlock()
+		if !started {
+			continue
 		}
 
-		// Stop
-		if err := plugin.Stop(ctx); err != nil {
-			return fmt.Errorf("stop failed for plugin %s: %w", metadata.Name, err)
+		err := pm.withTimeout(ctx, metadata.Name, "stop", func(ctx context.Context) error {
+			return pm.stopPlugin(ctx, plugin)
+		})
+		if errors.Is(err, ErrPluginTimeout) {
+			// Don't let one plugin keep the others from stopping
+			log.Printf("%v, continuing shutdown", err)
+			timedOut = append(timedOut, err)
+			continue
 		}
-
-		// Post-stop hooks
-		if hooks, ok := plugin.(LifecycleHooks); ok {
-			if err := hooks.OnAfterStop(ctx); err != nil {
-				return fmt.Errorf("OnAfterStop failed for plugin %s: %w", metadata.Name, err)
-			}
+		if err != nil {
+			return err
 		}
 
 		// Publish plugin stopped event
@@ -348,9 +731,197 @@ func (pm *PluginManager) StopPlugins(ctx context.Context) error {
 		})
 	}
 
+	return errors.Join(timedOut...)
+}
+
+func (pm *PluginManager) stopPlugin(ctx context.Context, plugin Plugin) error {
+	metadata := plugin.GetMetadata()
+	pm.setState(plugin, StateStopping)
+
+	// Pre-stop hooks
+	if hooks, ok := plugin.(LifecycleHooks); ok {
+		if err := hooks.OnBeforeStop(ctx); err != nil {
+			return fmt.Errorf("OnBeforeStop failed for plugin %s: %w", metadata.Name, err)
+		}
+	}
+
+	// Stop
+	if err := plugin.Stop(ctx); err != nil {
+		return fmt.Errorf("stop failed for plugin %s: %w", metadata.Name, err)
+	}
+	pm.unsubscribe(metadata.Name)
+
+	// Post-stop hooks
+	if hooks, ok := plugin.(LifecycleHooks); ok {
+		if err := hooks.OnAfterStop(ctx); err != nil {
+			return fmt.Errorf("OnAfterStop failed for plugin %s: %w", metadata.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// registerServices adds the services of a plugin to the container. Names
+// registered by the framework, or earlier by the same plugin, are replaced.
+// A name registered by another plugin is a collision: it is overwritten
+// with a warning, or an error when the container is strict.
+func (pm *PluginManager) registerServices(pluginName string, services map[string]interface{}) error {
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	for _, name := range names {
+		owner, owned := pm.serviceOwners[name]
+		if !owned || owner == pluginName {
+			pm.container.Replace(name, services[name])
+			pm.serviceOwners[name] = pluginName
+			continue
+		}
+
+		if err := pm.container.Register(name, services[name]); err != nil {
+			return fmt.Errorf("service %q is already registered by plugin %s: %w", name, owner, err)
+		}
+		log.Printf("Warning: plugin %s registers service %q, overwriting the one registered by plugin %s", pluginName, name, owner)
+		pm.serviceOwners[name] = pluginName
+	}
 	return nil
 }
 
+// ServiceOwner returns the name of the plugin that registered the service
+// under name, if a plugin did
+func (pm *PluginManager) ServiceOwner(name string) (string, bool) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	owner, exists := pm.serviceOwners[name]
+	return owner, exists
+}
+
+// FindPluginsByTag returns the enabled plugins whose metadata lists tag,
+// in load order
+func (pm *PluginManager) FindPluginsByTag(tag string) []Plugin {
+	var found []Plugin
+	for _, plugin := range pm.getEnabledPlugins() {
+		for _, t := range plugin.GetMetadata().Tags {
+			if t == tag {
+				found = append(found, plugin)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// subscribe adds the event handlers of an EventSubscriber plugin unless
+// they are subscribed already
+func (pm *PluginManager) subscribe(plugin Plugin) {
+	subscriber, ok := plugin.(EventSubscriber)
+	if !ok {
+		return
+	}
+	name := plugin.GetMetadata().Name
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if _, subscribed := pm.subscriptions[name]; subscribed {
+		return
+	}
+
+	ids := []SubscriptionID{}
+	for eventName, handler := range subscriber.GetEventSubscriptions() {
+		ids = append(ids, pm.eventBus.Subscribe(eventName, handler))
+	}
+	pm.subscriptions[name] = ids
+}
+
+// unsubscribe removes the event handlers of a plugin, so a stopped plugin
+// no longer receives events
+func (pm *PluginManager) unsubscribe(name string) {
+	pm.mu.Lock()
+	ids := pm.subscriptions[name]
+	delete(pm.subscriptions, name)
+	pm.mu.Unlock()
+
+	for _, id := range ids {
+		pm.eventBus.Unsubscribe(id)
+	}
+}
+
+// setTimeouts reads the start_timeout and stop_timeout of a plugin config
+func (pm *PluginManager) setTimeouts(name string, config map[string]interface{}) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.timeouts[name] = pluginTimeouts{
+		start: GetDurationConfig(config, "start_timeout", 0),
+		stop:  GetDurationConfig(config, "stop_timeout", 0),
+	}
+}
+
+// withTimeout runs a lifecycle phase of a plugin with a context deadline.
+// It returns ErrPluginTimeout when the phase has not returned by then; the
+// phase keeps running in the background, so it should honor the context.
+func (pm *PluginManager) withTimeout(ctx context.Context, name, phase string, fn func(ctx context.Context) error) error {
+	pm.mu.RLock()
+	timeouts := pm.timeouts[name]
+	timeout := pm.defaultTimeout
+	pm.mu.RUnlock()
+
+	if phase == "start" && timeouts.start > 0 {
+		timeout = timeouts.start
+	} else if phase == "stop" && timeouts.stop > 0 {
+		timeout = timeouts.stop
+	}
+	if timeout <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		// A panic off the caller's goroutine would crash the process
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("%s of plugin %s panicked: %v", phase, name, r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%s of plugin %s did not finish within %v: %w", phase, name, timeout, ErrPluginTimeout)
+		}
+		return ctx.Err()
+	}
+}
+
+// PluginStates returns the current state of every registered plugin
+func (pm *PluginManager) PluginStates() map[string]PluginState {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	states := make(map[string]PluginState, len(pm.plugins))
+	for name, plugin := range pm.plugins {
+		states[name] = plugin.GetState()
+	}
+	return states
+}
+
+// setState records a lifecycle transition of plugins embedding BasePlugin
+func (pm *PluginManager) setState(plugin Plugin, state PluginState) {
+	if setter, ok := plugin.(stateSetter); ok {
+		setter.SetState(state)
+	}
+}
+
 func (pm *PluginManager) GetPlugin(name string) (Plugin, bool) {
 	pm.mu.RLock()
 	defer pm.mu.RUnlock()
@@ -365,7 +936,7 @@ func (pm *PluginManager) GetEventBus() *EventBus {
 func (pm *PluginManager) GetMiddleware() []MiddlewareFunc {
 	var middleware []MiddlewareFunc
 
-	sortedPlugins := pm.getSortedPlugins()
+	sortedPlugins := pm.getEnabledPlugins()
 	for _, plugin := range sortedPlugins {
 		if provider, ok := plugin.(MiddlewareProvider); ok {
 			middleware = append(middleware, provider.GetMiddleware()...)
@@ -375,6 +946,19 @@ func (pm *PluginManager) GetMiddleware() []MiddlewareFunc {
 	return middleware
 }
 
+func (pm *PluginManager) GetRoutes() []Route {
+	var routes []Route
+
+	sortedPlugins := pm.getEnabledPlugins()
+	for _, plugin := range sortedPlugins {
+		if provider, ok := plugin.(RouteProvider); ok {
+			routes = append(routes, provider.GetRoutes()...)
+		}
+	}
+
+	return routes
+}
+
 func (pm *PluginManager) HotReloadPlugin(name string, newConfig map[string]interface{}) error {
 	pm.mu.RLock()
 	plugin, exists := pm.plugins[name]
@@ -391,32 +975,149 @@ func (pm *PluginManager) HotReloadPlugin(name string, newConfig map[string]inter
 	return fmt.Errorf("plugin %s does not support hot reload", name)
 }
 
-// getSortedPlugins returns plugins sorted by priority and dependencies
-func (pm *PluginManager) getSortedPlugins() []Plugin {
+// IsPluginEnabled reports whether the plugin is registered and not disabled in config
+func (pm *PluginManager) IsPluginEnabled(name string) bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	_, exists := pm.plugins[name]
+	return exists && !pm.disabled[name]
+}
+
+func (pm *PluginManager) disablePlugin(plugin Plugin) {
+	name := plugin.GetMetadata().Name
+
+	pm.mu.Lock()
+	pm.disabled[name] = true
+	pm.mu.Unlock()
+
+	pm.setState(plugin, StateDisabled)
+	log.Printf("Plugin %s is disabled", name)
+}
+
+func (pm *PluginManager) isDisabled(name string) bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.disabled[name]
+}
+
+// getEnabledPlugins returns sorted plugins that are not disabled
+func (pm *PluginManager) getEnabledPlugins() []Plugin {
 	var plugins []Plugin
-	for _, plugin := range pm.plugins {
-		plugins = append(plugins, plugin)
+	for _, plugin := range pm.getSortedPlugins() {
+		if !pm.isDisabled(plugin.GetMetadata().Name) {
+			plugins = append(plugins, plugin)
+		}
 	}
+	return plugins
+}
 
-	// Topological sort by dependencies + priority
-	sort.Slice(plugins, func(i, j int) bool {
-		metaI := plugins[i].GetMetadata()
-		metaJ := plugins[j].GetMetadata()
+// getSortedPlugins returns plugins sorted by dependencies and priority.
+// Plugins in a dependency cycle, which InitializePlugins rejects, come
+// last in priority order.
+func (pm *PluginManager) getSortedPlugins() []Plugin {
+	sorted, _ := pm.sortPlugins()
+	return sorted
+}
 
-		// First by priority
-		if metaI.Priority != metaJ.Priority {
-			return metaI.Priority > metaJ.Priority
+// sortPlugins orders plugins with Kahn's algorithm: a plugin comes after
+// its dependencies, and among plugins whose dependencies are all placed,
+// higher priority goes first, then the name. When plugins depend on each
+// other in a cycle, they are appended unsorted and an error describes the
+// cycle.
+func (pm *PluginManager) sortPlugins() ([]Plugin, error) {
+	pm.mu.RLock()
+	plugins := make(map[string]Plugin, len(pm.plugins))
+	for name, plugin := range pm.plugins {
+		plugins[name] = plugin
+	}
+	pm.mu.RUnlock()
+
+	// pending counts the unplaced dependencies of each plugin
+	pending := make(map[string]int, len(plugins))
+	dependents := make(map[string][]string)
+	var ready []Plugin
+	for name, plugin := range plugins {
+		for _, dep := range plugin.GetMetadata().Dependencies {
+			if _, exists := plugins[dep]; !exists {
+				return pm.appendByPriority(nil, plugins), fmt.Errorf("dependency %s not found for plugin %s", dep, name)
+			}
+			pending[name]++
+			dependents[dep] = append(dependents[dep], name)
+		}
+		if pending[name] == 0 {
+			ready = append(ready, plugin)
 		}
+	}
 
-		// Then by dependencies
-		for _, dep := range metaJ.Dependencies {
-			if dep == metaI.Name {
-				return true // i should be before j
+	sorted := make([]Plugin, 0, len(plugins))
+	for len(ready) > 0 {
+		sortByPriority(ready)
+		next := ready[0]
+		ready = ready[1:]
+		sorted = append(sorted, next)
+
+		name := next.GetMetadata().Name
+		delete(plugins, name)
+		for _, dependent := range dependents[name] {
+			if pending[dependent]--; pending[dependent] == 0 {
+				ready = append(ready, plugins[dependent])
 			}
 		}
+	}
+
+	if len(plugins) > 0 {
+		return pm.appendByPriority(sorted, plugins), fmt.Errorf("circular dependency detected: %s", dependencyCycle(plugins))
+	}
+	return sorted, nil
+}
+
+// appendByPriority appends the remaining plugins in priority order
+func (pm *PluginManager) appendByPriority(sorted []Plugin, remaining map[string]Plugin) []Plugin {
+	rest := make([]Plugin, 0, len(remaining))
+	for _, plugin := range remaining {
+		rest = append(rest, plugin)
+	}
+	sortByPriority(rest)
+	return append(sorted, rest...)
+}
 
-		return false
+// sortByPriority sorts plugins by descending priority, then by name
+func sortByPriority(plugins []Plugin) {
+	sort.Slice(plugins, func(i, j int) bool {
+		metaI, metaJ := plugins[i].GetMetadata(), plugins[j].GetMetadata()
+		if metaI.Priority != metaJ.Priority {
+			return metaI.Priority > metaJ.Priority
+		}
+		return metaI.Name < metaJ.Name
 	})
+}
 
-	return plugins
+// dependencyCycle describes a cycle among plugins that Kahn's algorithm
+// could not place, e.g. "A -> B -> A". Every such plugin has a dependency
+// among them, so following dependencies must revisit a plugin.
+func dependencyCycle(remaining map[string]Plugin) string {
+	names := make([]string, 0, len(remaining))
+	for name := range remaining {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var path []string
+	visited := make(map[string]int)
+	for name := names[0]; ; {
+		if start, seen := visited[name]; seen {
+			return strings.Join(append(path[start:], name), " -> ")
+		}
+		visited[name] = len(path)
+		path = append(path, name)
+
+		deps := append([]string(nil), remaining[name].GetMetadata().Dependencies...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if _, unplaced := remaining[dep]; unplaced {
+				name = dep
+				break
+			}
+		}
+	}
 }
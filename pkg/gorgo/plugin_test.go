@@ -1,12 +1,27 @@
 package gorgo
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
 )
 
 // Mock plugin implementations for testing
@@ -394,6 +409,223 @@ func TestEventBus(t *testing.T) {
 	}
 }
 
+func TestEventBus_Unsubscribe(t *testing.T) {
+	eventBus := NewEventBus()
+	ctx := context.Background()
+
+	var first, second int
+	firstID := eventBus.Subscribe("test.event", func(event *Event) error { first++; return nil })
+	eventBus.Subscribe("test.event", func(event *Event) error { second++; return nil })
+
+	eventBus.Publish(ctx, "test.event", nil)
+	if !eventBus.Unsubscribe(firstID) {
+		t.Fatal("expected Unsubscribe to find the handler")
+	}
+	if eventBus.Unsubscribe(firstID) {
+		t.Error("expected a second Unsubscribe to report false")
+	}
+	eventBus.Publish(ctx, "test.event", nil)
+
+	if first != 1 || second != 2 {
+		t.Errorf("expected calls 1 and 2, got %d and %d", first, second)
+	}
+}
+
+func TestPluginManager_UnsubscribesStoppedPlugins(t *testing.T) {
+	pm := NewPluginManager(container.NewContainer())
+	ctx := context.Background()
+
+	received := 0
+	plugin := NewMockEventSubscriber("subscriber")
+	plugin.subscriptions["test.event"] = func(event *Event) error {
+		received++
+		return nil
+	}
+	pm.RegisterPlugin(plugin)
+
+	if err := pm.InitializePlugins(map[string]map[string]interface{}{}); err != nil {
+		t.Fatalf("InitializePlugins failed: %v", err)
+	}
+	// Initializing again, e.g. on a second Run, must not subscribe twice
+	if err := pm.InitializePlugins(map[string]map[string]interface{}{}); err != nil {
+		t.Fatalf("InitializePlugins failed: %v", err)
+	}
+	if err := pm.StartPlugins(ctx); err != nil {
+		t.Fatalf("StartPlugins failed: %v", err)
+	}
+	pm.GetEventBus().Publish(ctx, "test.event", nil)
+
+	if err := pm.StopPlugins(ctx); err != nil {
+		t.Fatalf("StopPlugins failed: %v", err)
+	}
+	pm.GetEventBus().Publish(ctx, "test.event", nil)
+	if received != 1 {
+		t.Fatalf("expected 1 event before the stop, got %d", received)
+	}
+
+	// A restarted plugin receives events again
+	if err := pm.StartPlugins(ctx); err != nil {
+		t.Fatalf("StartPlugins failed: %v", err)
+	}
+	pm.GetEventBus().Publish(ctx, "test.event", nil)
+	if received != 2 {
+		t.Errorf("expected 2 events after the restart, got %d", received)
+	}
+}
+
+func TestPluginManager_FindPluginsByTag(t *testing.T) {
+	pm := NewPluginManager(container.NewContainer())
+
+	redis := NewMockPlugin("redis", PriorityHigh)
+	redis.metadata.Tags = []string{"cache", "session"}
+	memcache := NewMockPlugin("memcache", PriorityNormal)
+	memcache.metadata.Tags = []string{"cache"}
+	disabled := NewMockPlugin("disabled", PriorityNormal)
+	disabled.metadata.Tags = []string{"cache"}
+	for _, plugin := range []Plugin{memcache, redis, disabled, NewMockPlugin("sql", PriorityNormal)} {
+		pm.RegisterPlugin(plugin)
+	}
+	pm.disablePlugin(disabled)
+
+	var names []string
+	for _, plugin := range pm.FindPluginsByTag("cache") {
+		names = append(names, plugin.GetMetadata().Name)
+	}
+	if strings.Join(names, ",") != "redis,memcache" {
+		t.Errorf("expected redis,memcache, got %v", names)
+	}
+	if found := pm.FindPluginsByTag("unknown"); len(found) != 0 {
+		t.Errorf("expected no plugins, got %d", len(found))
+	}
+}
+
+func TestPluginManager_ServiceCollision(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	c := container.NewContainer()
+	pm := NewPluginManager(c)
+	pm.RegisterPlugin(NewMockServiceProvider("redis", map[string]interface{}{"cache": "redis", "redis": "redis"}))
+	second := NewMockServiceProvider("memcache", map[string]interface{}{"cache": "memcache"})
+	second.metadata.Priority = PriorityLow
+	pm.RegisterPlugin(second)
+
+	if err := pm.InitializePlugins(map[string]map[string]interface{}{}); err != nil {
+		t.Fatalf("InitializePlugins failed: %v", err)
+	}
+
+	if got := strings.Count(logs.String(), "Warning: plugin"); got != 1 {
+		t.Errorf("expected one warning, got %d in %q", got, logs.String())
+	}
+	if !strings.Contains(logs.String(), `plugin memcache registers service "cache", overwriting the one registered by plugin redis`) {
+		t.Errorf("expected the warning to name both plugins, got %q", logs.String())
+	}
+	if owner, _ := pm.ServiceOwner("cache"); owner != "memcache" {
+		t.Errorf("expected the later plugin to own cache, got %q", owner)
+	}
+	if cache, _ := c.Get("cache"); cache != "memcache" {
+		t.Errorf("expected the later service in the container, got %v", cache)
+	}
+}
+
+func TestEventBus_PatternSubscriptions(t *testing.T) {
+	eventBus := NewEventBus()
+	ctx := context.Background()
+
+	var calls []string
+	record := func(name string) EventHandler {
+		return func(event *Event) error {
+			calls = append(calls, name+":"+event.Name)
+			return nil
+		}
+	}
+	eventBus.Subscribe("*", record("all"))
+	eventBus.Subscribe("request.*", record("request"))
+	eventBus.Subscribe("request.completed", record("exact"))
+	requestID := eventBus.Subscribe("request.*", record("request2"))
+
+	eventBus.Publish(ctx, "request.completed", nil)
+	eventBus.Publish(ctx, "plugin.started", nil)
+	want := "exact:request.completed,request:request.completed,request2:request.completed,all:request.completed,all:plugin.started"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	calls = nil
+	eventBus.Unsubscribe(requestID)
+	eventBus.Publish(ctx, "request.error", nil)
+	if got := strings.Join(calls, ","); got != "request:request.error,all:request.error" {
+		t.Errorf("unexpected calls after Unsubscribe: %s", got)
+	}
+}
+
+func TestPluginManager_StrictServiceCollision(t *testing.T) {
+	c := container.NewContainer()
+	c.Register(MetricsService, NoopMetrics{})
+	c.SetStrict(true)
+
+	pm := NewPluginManager(c)
+	// Plugins may replace framework defaults in strict mode
+	pm.RegisterPlugin(NewMockServiceProvider("monitoring", map[string]interface{}{MetricsService: "registry"}))
+	pm.RegisterPlugin(NewMockServiceProvider("redis", map[string]interface{}{"cache": "redis"}))
+	second := NewMockServiceProvider("memcache", map[string]interface{}{"cache": "memcache"})
+	second.metadata.Priority = PriorityLow
+	pm.RegisterPlugin(second)
+
+	err := pm.InitializePlugins(map[string]map[string]interface{}{})
+	if !errors.Is(err, container.ErrServiceExists) || !strings.Contains(err.Error(), "plugin redis") {
+		t.Fatalf("expected a collision error naming redis, got %v", err)
+	}
+	if cache, _ := c.Get("cache"); cache != "redis" {
+		t.Errorf("expected the first service to be kept, got %v", cache)
+	}
+	if metrics, _ := c.Get(MetricsService); metrics != "registry" {
+		t.Errorf("expected the plugin to replace the default metrics, got %v", metrics)
+	}
+}
+
+func stuckStoppingHandler(event *Event) error {
+	select {} // ignores the context
+}
+
+func TestEventBus_PublishBounded(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	eventBus := NewEventBus()
+	hasDeadline := false
+	eventBus.Subscribe("app.stopping", func(event *Event) error {
+		_, hasDeadline = event.Context().Deadline()
+		return nil
+	})
+	eventBus.Subscribe("app.stopping", stuckStoppingHandler)
+	eventBus.Subscribe("app.stopping", func(event *Event) error {
+		t.Error("handlers after a stuck one must not run")
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := eventBus.publishBounded(ctx, "app.stopping", nil)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected publishing to stop at the deadline, took %v", elapsed)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline error, got %v", err)
+	}
+	if !hasDeadline {
+		t.Error("expected handlers to get the bounded context")
+	}
+	if !strings.Contains(logs.String(), "handler github.com/GorgoFramework/gorgo/pkg/gorgo.stuckStoppingHandler did not finish in time") ||
+		strings.Count(logs.String(), "skipped") != 1 {
+		t.Errorf("expected the stuck and the skipped handler to be logged, got %q", logs.String())
+	}
+}
+
 func TestEventBus_HandlerError(t *testing.T) {
 	eventBus := NewEventBus()
 
@@ -988,6 +1220,58 @@ func TestPluginManager_GetSortedPlugins_ByDependencies(t *testing.T) {
 	}
 }
 
+func TestPluginManager_GetSortedPlugins_DependencyBeforePriority(t *testing.T) {
+	pm := NewPluginManager(container.NewContainer())
+
+	pm.RegisterPlugin(NewMockPlugin("store", PriorityLow))
+	pm.RegisterPlugin(NewMockPlugin("logger", PriorityNormal))
+	cache := &MockPlugin{BasePlugin: NewBasePlugin(PluginMetadata{Name: "cache", Priority: PriorityHigh, Dependencies: []string{"store"}})}
+	if err := pm.RegisterPlugin(cache); err != nil {
+		t.Fatalf("RegisterPlugin failed: %v", err)
+	}
+
+	sorted, err := pm.sortPlugins()
+	if err != nil {
+		t.Fatalf("sortPlugins failed: %v", err)
+	}
+	var names []string
+	for _, plugin := range sorted {
+		names = append(names, plugin.GetMetadata().Name)
+	}
+	// store only goes first because cache depends on it
+	if got := strings.Join(names, ","); got != "logger,store,cache" {
+		t.Errorf("expected logger,store,cache, got %s", got)
+	}
+}
+
+func TestPluginManager_CircularDependency(t *testing.T) {
+	pm := NewPluginManager(container.NewContainer())
+
+	a := NewMockPlugin("a", PriorityNormal)
+	pm.RegisterPlugin(a)
+	pm.RegisterPlugin(&MockPlugin{BasePlugin: NewBasePlugin(PluginMetadata{Name: "b", Dependencies: []string{"a"}})})
+
+	// Re-registering a with a dependency on b would close a cycle
+	err := pm.RegisterPlugin(&MockPlugin{BasePlugin: NewBasePlugin(PluginMetadata{Name: "a", Dependencies: []string{"b"}})})
+	if err == nil || err.Error() != "circular dependency detected: a -> b -> a" {
+		t.Fatalf("expected a cycle error, got %v", err)
+	}
+	if plugin, _ := pm.GetPlugin("a"); plugin != a {
+		t.Error("expected the previous plugin to stay registered")
+	}
+
+	// Cycles that bypass RegisterPlugin fail initialization
+	pm.plugins["a"] = &MockPlugin{BasePlugin: NewBasePlugin(PluginMetadata{Name: "a", Dependencies: []string{"c"}})}
+	pm.plugins["c"] = &MockPlugin{BasePlugin: NewBasePlugin(PluginMetadata{Name: "c", Dependencies: []string{"b"}})}
+	err = pm.InitializePlugins(map[string]map[string]interface{}{})
+	if err == nil || err.Error() != "circular dependency detected: a -> c -> b -> a" {
+		t.Fatalf("expected InitializePlugins to report the cycle, got %v", err)
+	}
+	if sorted := pm.getSortedPlugins(); len(sorted) != 3 {
+		t.Errorf("expected all plugins in the fallback order, got %d", len(sorted))
+	}
+}
+
 // Test error scenarios
 
 func TestPluginManager_InitializePlugins_InitError(t *testing.T) {
@@ -1012,6 +1296,7 @@ func TestPluginManager_StartPlugins_StartError(t *testing.T) {
 	pm := NewPluginManager(c)
 
 	plugin := NewMockPlugin("error-plugin", PriorityNormal)
+	plugin.metadata.Critical = true
 	plugin.startError = errors.New("start failed")
 	err := pm.RegisterPlugin(plugin)
 	if err != nil {
@@ -1030,6 +1315,44 @@ func TestPluginManager_StartPlugins_StartError(t *testing.T) {
 	}
 }
 
+func TestPluginManager_StartPlugins_NonCriticalStartError(t *testing.T) {
+	c := container.NewContainer()
+	pm := NewPluginManager(c)
+
+	failing := NewMockPlugin("optional-plugin", PriorityHigh)
+	failing.startError = errors.New("start failed")
+	healthy := NewMockPlugin("healthy-plugin", PriorityNormal)
+
+	pm.RegisterPlugin(failing)
+	pm.RegisterPlugin(healthy)
+
+	var failedPlugin interface{}
+	pm.GetEventBus().Subscribe("plugin.start_failed", func(event *Event) error {
+		failedPlugin = event.Data["plugin"]
+		return nil
+	})
+
+	if err := pm.InitializePlugins(map[string]map[string]interface{}{}); err != nil {
+		t.Fatalf("InitializePlugins failed: %v", err)
+	}
+
+	if err := pm.StartPlugins(context.Background()); err != nil {
+		t.Fatalf("expected non-critical start error to be tolerated, got %v", err)
+	}
+
+	if failing.GetState() != StateError {
+		t.Errorf("expected failing plugin state %d, got %d", StateError, failing.GetState())
+	}
+
+	if healthy.GetState() != StateRunning {
+		t.Errorf("expected healthy plugin state %d, got %d", StateRunning, healthy.GetState())
+	}
+
+	if failedPlugin != "optional-plugin" {
+		t.Errorf("expected plugin.start_failed event for 'optional-plugin', got %v", failedPlugin)
+	}
+}
+
 func TestPluginManager_StopPlugins_StopError(t *testing.T) {
 	c := container.NewContainer()
 	pm := NewPluginManager(c)
@@ -1155,3 +1478,1127 @@ func TestPluginManager_ConcurrentAccess(b *testing.T) {
 		<-done
 	}
 }
+
+// MockEventPublisher - mock plugin that publishes events
+type MockEventPublisher struct {
+	*MockPlugin
+	eventBus *EventBus
+}
+
+func (mep *MockEventPublisher) SetEventBus(eventBus *EventBus) {
+	mep.eventBus = eventBus
+}
+
+func TestPluginManager_InitializePlugins_EventPublisher(t *testing.T) {
+	pm := NewPluginManager(container.NewContainer())
+	plugin := &MockEventPublisher{MockPlugin: NewMockPlugin("publisher", PriorityNormal)}
+
+	if err := pm.RegisterPlugin(plugin); err != nil {
+		t.Fatalf("RegisterPlugin failed: %v", err)
+	}
+
+	if err := pm.InitializePlugins(nil); err != nil {
+		t.Fatalf("InitializePlugins failed: %v", err)
+	}
+
+	if plugin.eventBus != pm.GetEventBus() {
+		t.Fatal("expected plugin to receive the manager's event bus")
+	}
+
+	received := false
+	pm.GetEventBus().Subscribe("custom.alert", func(event *Event) error {
+		received = true
+		return nil
+	})
+
+	if err := plugin.eventBus.Publish(context.Background(), "custom.alert", nil); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	if !received {
+		t.Error("expected event published by plugin to be delivered")
+	}
+}
+
+func TestGo_RecoversPanic(t *testing.T) {
+	eventBus := NewEventBus()
+	setGoroutineEventBus(eventBus)
+	defer setGoroutineEventBus(nil)
+
+	received := make(chan *Event, 1)
+	eventBus.Subscribe(GoroutinePanicEvent, func(event *Event) error {
+		received <- event
+		return nil
+	})
+
+	Go(func() {
+		panic("boom")
+	})
+
+	select {
+	case event := <-received:
+		if event.Data["panic"] != "boom" {
+			t.Errorf("expected panic value 'boom', got '%v'", event.Data["panic"])
+		}
+		if event.Data["stack"] == "" {
+			t.Error("expected stack trace in event data")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("goroutine.panic event was not published")
+	}
+}
+
+func TestPluginManager_DisabledPlugin(t *testing.T) {
+	c := container.NewContainer()
+	pm := NewPluginManager(c)
+
+	enabled := NewMockPlugin("enabled-plugin", PriorityNormal)
+	disabled := NewMockPlugin("disabled-plugin", PriorityNormal)
+	pm.RegisterPlugin(enabled)
+	pm.RegisterPlugin(disabled)
+
+	configs := map[string]map[string]interface{}{
+		"disabled-plugin": {
+			"enabled": false,
+		},
+	}
+
+	if err := pm.InitializePlugins(configs); err != nil {
+		t.Fatalf("InitializePlugins failed: %v", err)
+	}
+	if err := pm.StartPlugins(context.Background()); err != nil {
+		t.Fatalf("StartPlugins failed: %v", err)
+	}
+
+	if disabled.GetState() != StateDisabled {
+		t.Errorf("expected disabled plugin state %d, got %d", StateDisabled, disabled.GetState())
+	}
+	if enabled.GetState() != StateRunning {
+		t.Errorf("expected enabled plugin state %d, got %d", StateRunning, enabled.GetState())
+	}
+	if _, exists := pm.GetPlugin("disabled-plugin"); !exists {
+		t.Error("expected disabled plugin to stay registered")
+	}
+	if pm.IsPluginEnabled("disabled-plugin") {
+		t.Error("expected IsPluginEnabled to be false for disabled plugin")
+	}
+
+	if err := pm.StopPlugins(context.Background()); err != nil {
+		t.Fatalf("StopPlugins failed: %v", err)
+	}
+	if disabled.GetState() != StateDisabled {
+		t.Errorf("expected disabled plugin to stay disabled after stop, got %d", disabled.GetState())
+	}
+}
+
+func TestPluginManager_DisabledDependency(t *testing.T) {
+	c := container.NewContainer()
+	pm := NewPluginManager(c)
+
+	base := NewMockPlugin("base", PriorityHigh)
+	pm.RegisterPlugin(base)
+	dependent := NewMockPlugin("dependent", PriorityNormal)
+	dependent.metadata.Dependencies = []string{"base"}
+	pm.RegisterPlugin(dependent)
+
+	configs := map[string]map[string]interface{}{
+		"base": {"enabled": "false"},
+	}
+
+	if err := pm.InitializePlugins(configs); err == nil {
+		t.Error("expected error for plugin depending on a disabled plugin")
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	eventBus := NewEventBus()
+	var transitions []string
+	eventBus.Subscribe(CircuitBreakerStateEvent, func(event *Event) error {
+		transitions = append(transitions, event.Data["to"].(string))
+		return nil
+	})
+
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{
+		Name:             "db",
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		EventBus:         eventBus,
+	})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	failure := errors.New("connection refused")
+	fail := func() error { return failure }
+	succeed := func() error { return nil }
+
+	breaker.Execute(fail)
+	if breaker.State() != CircuitClosed {
+		t.Fatal("Expected circuit to stay closed below the threshold")
+	}
+	if err := breaker.Execute(fail); err != failure {
+		t.Errorf("Expected the call error, got %v", err)
+	}
+	if breaker.State() != CircuitOpen {
+		t.Fatal("Expected circuit to open at the threshold")
+	}
+
+	called := false
+	if err := breaker.Execute(func() error { called = true; return nil }); err != ErrCircuitOpen || called {
+		t.Error("Expected open circuit to reject calls without running them")
+	}
+
+	now = now.Add(time.Minute)
+	if breaker.State() != CircuitHalfOpen {
+		t.Fatal("Expected circuit to half-open after the timeout")
+	}
+	breaker.Execute(fail)
+	if breaker.State() != CircuitOpen {
+		t.Fatal("Expected failed probe to reopen the circuit")
+	}
+
+	now = now.Add(time.Minute)
+	if err := breaker.Execute(succeed); err != nil {
+		t.Errorf("Expected probe to run, got %v", err)
+	}
+	if breaker.State() != CircuitClosed {
+		t.Fatal("Expected successful probe to close the circuit")
+	}
+
+	expected := "open,half-open,open,half-open,closed"
+	if strings.Join(transitions, ",") != expected {
+		t.Errorf("Expected transitions %s, got %v", expected, transitions)
+	}
+}
+
+func TestCircuitBreakerPanicInProbe(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerOptions{
+		Name:             "db",
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+		HalfOpenRequests: 1,
+	})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	breaker.Execute(func() error { return errors.New("connection refused") })
+	now = now.Add(time.Minute)
+	if breaker.State() != CircuitHalfOpen {
+		t.Fatal("Expected circuit to half-open after the timeout")
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("Expected the panic to be passed on, got %v", r)
+			}
+		}()
+		breaker.Execute(func() error { panic("boom") })
+	}()
+	if breaker.State() != CircuitOpen {
+		t.Fatalf("Expected a panicking probe to reopen the circuit, got %s", breaker.State())
+	}
+
+	// The probe slot was released, so the next probe runs
+	now = now.Add(time.Minute)
+	if err := breaker.Execute(func() error { return nil }); err != nil {
+		t.Errorf("Expected the next probe to run, got %v", err)
+	}
+	if breaker.State() != CircuitClosed {
+		t.Errorf("Expected a successful probe to close the circuit, got %s", breaker.State())
+	}
+}
+
+// blockingPlugin blocks in Start and Stop until its context is done or it is released
+type blockingPlugin struct {
+	*MockPlugin
+	release chan struct{}
+}
+
+func (p *blockingPlugin) Start(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.release:
+		return p.MockPlugin.Start(ctx)
+	}
+}
+
+func (p *blockingPlugin) Stop(ctx context.Context) error {
+	<-p.release
+	return p.MockPlugin.Stop(ctx)
+}
+
+func TestPluginManager_LifecycleTimeout(t *testing.T) {
+	c := container.NewContainer()
+	pm := NewPluginManager(c)
+
+	hanging := &blockingPlugin{MockPlugin: NewMockPlugin("hanging", PriorityHigh), release: make(chan struct{})}
+	defer close(hanging.release)
+	healthy := NewMockPlugin("healthy", PriorityLow)
+	pm.RegisterPlugin(hanging)
+	pm.RegisterPlugin(healthy)
+
+	err := pm.InitializePlugins(map[string]map[string]interface{}{
+		"hanging": {"start_timeout": "20ms", "stop_timeout": "20ms"},
+	})
+	if err != nil {
+		t.Fatalf("InitializePlugins failed: %v", err)
+	}
+
+	started := time.Now()
+	if err := pm.StartPlugins(context.Background()); err != nil {
+		t.Fatalf("Expected non-critical start timeout to be tolerated, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Expected start to give up after the timeout, took %v", elapsed)
+	}
+	if hanging.GetState() != StateError {
+		t.Errorf("Expected timed out plugin in error state, got %d", hanging.GetState())
+	}
+
+	err = pm.StopPlugins(context.Background())
+	if !errors.Is(err, ErrPluginTimeout) {
+		t.Errorf("Expected ErrPluginTimeout, got %v", err)
+	}
+	if healthy.GetState() != StateStopped {
+		t.Errorf("Expected other plugins to stop after a stop timeout, got state %d", healthy.GetState())
+	}
+
+	// Critical plugins fail startup
+	pm = NewPluginManager(c)
+	critical := &blockingPlugin{MockPlugin: NewMockPlugin("critical", PriorityNormal), release: make(chan struct{})}
+	defer close(critical.release)
+	critical.metadata.Critical = true
+	pm.RegisterPlugin(critical)
+	pm.SetLifecycleTimeout(20 * time.Millisecond)
+	pm.InitializePlugins(map[string]map[string]interface{}{})
+
+	if err := pm.StartPlugins(context.Background()); !errors.Is(err, ErrPluginTimeout) {
+		t.Errorf("Expected critical start timeout to fail, got %v", err)
+	}
+}
+
+// panickingPlugin panics when it starts or stops
+type panickingPlugin struct {
+	*MockPlugin
+}
+
+func (p *panickingPlugin) Start(ctx context.Context) error {
+	panic("start exploded")
+}
+
+func (p *panickingPlugin) Stop(ctx context.Context) error {
+	panic("stop exploded")
+}
+
+func TestPluginManager_LifecyclePanic(t *testing.T) {
+	c := container.NewContainer()
+	pm := NewPluginManager(c)
+
+	panicking := &panickingPlugin{MockPlugin: NewMockPlugin("panicking", PriorityHigh)}
+	healthy := NewMockPlugin("healthy", PriorityLow)
+	pm.RegisterPlugin(panicking)
+	pm.RegisterPlugin(healthy)
+	if err := pm.InitializePlugins(map[string]map[string]interface{}{}); err != nil {
+		t.Fatalf("InitializePlugins failed: %v", err)
+	}
+
+	if err := pm.StartPlugins(context.Background()); err != nil {
+		t.Fatalf("Expected a non-critical panic to be tolerated, got %v", err)
+	}
+	if panicking.GetState() != StateError {
+		t.Errorf("Expected the panicking plugin in error state, got %d", panicking.GetState())
+	}
+
+	err := pm.StopPlugins(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "stop of plugin panicking panicked: stop exploded") {
+		t.Errorf("Expected the stop panic as an error, got %v", err)
+	}
+
+	// A critical plugin's panic fails startup
+	pm = NewPluginManager(c)
+	critical := &panickingPlugin{MockPlugin: NewMockPlugin("critical", PriorityNormal)}
+	critical.metadata.Critical = true
+	pm.RegisterPlugin(critical)
+	pm.InitializePlugins(map[string]map[string]interface{}{})
+	err = pm.StartPlugins(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "start of plugin critical panicked: start exploded") {
+		t.Errorf("Expected the start panic as an error, got %v", err)
+	}
+}
+
+type orderPlaced struct {
+	ID    int64   `event:"id"`
+	Total float64 `event:"total"`
+	Note  string
+}
+
+func (orderPlaced) EventName() string { return "order.placed" }
+
+func TestTypedEvents(t *testing.T) {
+	bus := NewEventBus()
+
+	var typed []orderPlaced
+	SubscribeTyped(bus, func(event orderPlaced) error {
+		typed = append(typed, event)
+		return nil
+	})
+
+	var untyped map[string]interface{}
+	bus.Subscribe("order.placed", func(event *Event) error {
+		untyped = event.Data
+		return nil
+	})
+
+	if err := PublishTyped(context.Background(), bus, orderPlaced{ID: 1 << 60, Total: 9.5, Note: "gift"}); err != nil {
+		t.Fatalf("PublishTyped failed: %v", err)
+	}
+	if len(typed) != 1 || typed[0].ID != 1<<60 || typed[0].Note != "gift" {
+		t.Errorf("Expected the published struct, got %+v", typed)
+	}
+	if untyped["id"] != int64(1<<60) || untyped["total"] != 9.5 || untyped["Note"] != "gift" {
+		t.Errorf("Expected fields in event data, got %v", untyped)
+	}
+
+	// Untyped publishers interoperate with typed subscribers
+	bus.Publish(context.Background(), "order.placed", map[string]interface{}{"id": 7, "total": 3})
+	if len(typed) != 2 || typed[1].ID != 7 || typed[1].Total != 3 {
+		t.Errorf("Expected event decoded from data, got %+v", typed)
+	}
+
+	err := bus.Publish(context.Background(), "order.placed", map[string]interface{}{"id": "seven"})
+	if err == nil || !strings.Contains(err.Error(), "cannot use string") {
+		t.Errorf("Expected decode error for mismatched types, got %v", err)
+	}
+
+	// Framework events are typed
+	var completed RequestCompleted
+	SubscribeTyped(bus, func(event RequestCompleted) error {
+		completed = event
+		return nil
+	})
+	bus.Publish(context.Background(), "request.completed", map[string]interface{}{"method": "GET", "path": "/", "status": 204})
+	if completed.Method != "GET" || completed.Status != 204 {
+		t.Errorf("Expected decoded RequestCompleted, got %+v", completed)
+	}
+}
+
+func TestEventDispatcher(t *testing.T) {
+	bus := NewEventBus()
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var received []string
+	bus.Subscribe("request.completed", func(event *Event) error {
+		<-release
+		mu.Lock()
+		received = append(received, event.Data["path"].(string))
+		mu.Unlock()
+		return nil
+	})
+
+	d := newEventDispatcher(bus, func() Metrics { return NoopMetrics{} }, 2, 1)
+
+	// The single worker blocks on the first event, two more fill the buffer
+	start := time.Now()
+	for _, path := range []string{"/a", "/b", "/c", "/d", "/e"} {
+		d.dispatchTyped(context.Background(), RequestCompleted{Path: path})
+		if path == "/a" {
+			time.Sleep(10 * time.Millisecond) // let the worker take it
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected dispatch not to block on subscribers, took %v", elapsed)
+	}
+	if dropped := d.dropped.Load(); dropped != 2 {
+		t.Errorf("Expected 2 dropped events, got %d", dropped)
+	}
+
+	close(release)
+	d.close(context.Background())
+
+	mu.Lock()
+	drained := strings.Join(received, ",")
+	mu.Unlock()
+	if drained != "/a,/b,/c" {
+		t.Errorf("Expected queued events to be drained on close, got %v", drained)
+	}
+
+	// After close events are published synchronously
+	d.dispatchTyped(context.Background(), RequestCompleted{Path: "/f"})
+	mu.Lock()
+	defer mu.Unlock()
+	if received[len(received)-1] != "/f" {
+		t.Errorf("Expected synchronous publish after close, got %v", received)
+	}
+}
+
+func TestEventBus_PublishAsync(t *testing.T) {
+	bus := NewEventBus()
+	release := make(chan struct{})
+	handled := make(chan string, 4)
+	bus.Subscribe("order.placed", func(event *Event) error {
+		<-release
+		handled <- event.Data["id"].(string)
+		if event.Data["id"] == "fail" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	errs := make(chan error, 4)
+	bus.OnAsyncError(func(eventName string, err error) { errs <- err })
+
+	// Without a dispatcher the handlers run in their own goroutine
+	bus.PublishAsync(context.Background(), "order.placed", map[string]interface{}{"id": "fail"})
+
+	// While serving they run on the dispatcher workers
+	d := newEventDispatcher(bus, func() Metrics { return NoopMetrics{} }, 4, 1)
+	bus.PublishAsync(context.Background(), "order.placed", map[string]interface{}{"id": "a"})
+
+	// Neither call waited for the blocked handler
+	close(release)
+	d.close(context.Background())
+
+	if bus.dispatcher.Load() != nil {
+		t.Error("expected the closed dispatcher to be detached from the bus")
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-handled:
+		case <-time.After(time.Second):
+			t.Fatal("async handler did not run")
+		}
+	}
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "boom") {
+			t.Errorf("unexpected async error %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the handler error to reach OnAsyncError")
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	app := newTestApp(t)
+	app.Readiness("/readyz")
+	app.Get("/users", func(ctx *Context) error { return ctx.String("users") })
+
+	slow := &blockingPlugin{MockPlugin: NewMockPlugin("sql", PriorityLow), release: make(chan struct{})}
+	app.AddPlugin(NewMockPlugin("redis", PriorityHigh))
+	app.AddPlugin(slow)
+	if err := app.pluginManager.InitializePlugins(map[string]map[string]interface{}{}); err != nil {
+		t.Fatalf("InitializePlugins failed: %v", err)
+	}
+
+	request := func(path string) (int, map[string]interface{}) {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(path)
+		app.handleRequest(fastCtx)
+
+		var body map[string]interface{}
+		json.Unmarshal(fastCtx.Response.Body(), &body)
+		return fastCtx.Response.StatusCode(), body
+	}
+
+	app.starting.Store(true)
+	done := make(chan error, 1)
+	go func() { done <- app.pluginManager.StartPlugins(context.Background()) }()
+
+	// Wait until the slow plugin is starting
+	deadline := time.Now().Add(time.Second)
+	for app.PluginStates()["sql"] != StateStarting && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	status, body := request("/readyz")
+	if status != ServiceUnavailableStatus || body["status"] != "starting" {
+		t.Errorf("Expected 503 starting, got %d %v", status, body)
+	}
+	if fmt.Sprint(body["started"]) != "[redis]" || fmt.Sprint(body["pending"]) != "[sql]" {
+		t.Errorf("Expected redis started and sql pending, got %v", body)
+	}
+	if plugins, _ := body["plugins"].(map[string]interface{}); plugins["sql"] != "starting" {
+		t.Errorf("Expected per-plugin states, got %v", body["plugins"])
+	}
+	if status, body := request("/users"); status != ServiceUnavailableStatus || body["code"] != "starting" {
+		t.Errorf("Expected other routes to be rejected while starting, got %d %v", status, body)
+	}
+
+	close(slow.release)
+	if err := <-done; err != nil {
+		t.Fatalf("StartPlugins failed: %v", err)
+	}
+	app.starting.Store(false)
+
+	if status, body := request("/readyz"); status != OKStatus || body["status"] != "ready" {
+		t.Errorf("Expected 200 ready, got %d %v", status, body)
+	}
+	if status, _ := request("/users"); status != OKStatus {
+		t.Errorf("Expected routes to be served after startup, got %d", status)
+	}
+}
+
+type injectedPool struct{ dsn string }
+
+func TestInject(t *testing.T) {
+	app := newTestApp(t)
+	c := app.container
+	c.Register(MetricsService, NoopMetrics{})
+
+	// Registered before the service exists, like handlers using plugin services
+	app.Get("/users", app.Inject(func(ctx *Context, pool *injectedPool, metrics Metrics) error {
+		return ctx.String(pool.dsn)
+	}))
+	if err := app.resolveInjected(); err == nil {
+		t.Fatal("Expected unresolved dependency to fail")
+	}
+
+	pool := &injectedPool{dsn: "postgres://localhost/app"}
+	c.Register("sql", pool)
+	c.Register("db", pool)
+	if err := app.resolveInjected(); err != nil {
+		t.Fatalf("Expected dependencies to resolve, got %v", err)
+	}
+
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.SetRequestURI("/users")
+	app.handleRequest(fastCtx)
+	if string(fastCtx.Response.Body()) != pool.dsn {
+		t.Errorf("Expected injected pool, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+
+	c.Register("replica", &injectedPool{dsn: "postgres://replica/app"})
+	if err := app.resolveInjected(); err == nil {
+		t.Error("Expected ambiguous dependency to fail")
+	}
+
+	for _, handler := range []interface{}{
+		"not a function",
+		func(pool *injectedPool) error { return nil },
+		func(ctx *Context, pool *injectedPool) {},
+	} {
+		app := newTestApp(t)
+		app.Inject(handler)
+		if err := app.resolveInjected(); err == nil {
+			t.Errorf("Expected invalid handler %T to fail", handler)
+		}
+	}
+}
+
+func TestDraining(t *testing.T) {
+	app := newTestApp(t)
+	app.Readiness("/readyz")
+	app.Get("/users", func(ctx *Context) error { return ctx.String("users") })
+
+	var events []bool
+	app.GetEventBus().Subscribe("app.draining", func(event *Event) error {
+		events = append(events, event.Data["draining"].(bool))
+		return nil
+	})
+
+	request := func(path string) *fasthttp.RequestCtx {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(path)
+		app.handleRequest(fastCtx)
+		return fastCtx
+	}
+
+	app.SetDraining(true)
+	app.SetDraining(true)
+	if !app.Draining() || app.Ready() {
+		t.Error("Expected draining application not to be ready")
+	}
+
+	fastCtx := request("/readyz")
+	var body map[string]interface{}
+	json.Unmarshal(fastCtx.Response.Body(), &body)
+	if fastCtx.Response.StatusCode() != ServiceUnavailableStatus || body["status"] != "draining" {
+		t.Errorf("Expected 503 draining, got %d %v", fastCtx.Response.StatusCode(), body)
+	}
+
+	fastCtx = request("/users")
+	if fastCtx.Response.StatusCode() != OKStatus {
+		t.Errorf("Expected requests to be served while draining, got %d", fastCtx.Response.StatusCode())
+	}
+	if !fastCtx.Response.ConnectionClose() {
+		t.Error("Expected keep-alive connections to be closed while draining")
+	}
+
+	app.SetDraining(false)
+	if fastCtx := request("/readyz"); fastCtx.Response.StatusCode() != OKStatus {
+		t.Errorf("Expected ready after draining stopped, got %d", fastCtx.Response.StatusCode())
+	}
+	if fmt.Sprint(events) != "[true false]" {
+		t.Errorf("Expected one event per transition, got %v", events)
+	}
+
+	for setting, valid := range map[string]bool{"": true, "none": true, "SIGUSR2": true, "hup": true, "SIGKILL": false} {
+		app.config.Server.DrainSignal = setting
+		if _, err := app.drainSignal(); (err == nil) != valid {
+			t.Errorf("drain_signal %q: expected valid=%v, got %v", setting, valid, err)
+		}
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	info := ReadBuildInfo("0.0.1")
+	if info.Version == "" || info.GoVersion != runtime.Version() {
+		t.Errorf("unexpected default build info %+v", info)
+	}
+
+	buildVersion, buildCommit = "1.4.2", "abc123"
+	defer func() { buildVersion, buildCommit = "", "" }()
+	if info := ReadBuildInfo("0.0.1"); info.Version != "1.4.2" || info.Commit != "abc123" {
+		t.Errorf("expected ldflags values to win, got %+v", info)
+	}
+
+	app := newTestApp(t)
+	app.SetBuildInfo(BuildInfo{Version: "2.0.0", Commit: "def456", BuildTime: "2026-10-16T09:30:00Z"})
+	app.BuildInfoEndpoint("/version")
+	app.Readiness("/readyz")
+
+	for path, extract := range map[string]func(map[string]interface{}) interface{}{
+		"/version": func(body map[string]interface{}) interface{} { return body },
+		"/readyz":  func(body map[string]interface{}) interface{} { return body["build"] },
+	} {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(path)
+		app.handleRequest(fastCtx)
+
+		var body map[string]interface{}
+		if err := json.Unmarshal(fastCtx.Response.Body(), &body); err != nil {
+			t.Fatalf("%s: invalid JSON %q", path, fastCtx.Response.Body())
+		}
+		build, _ := extract(body).(map[string]interface{})
+		if build["version"] != "2.0.0" || build["commit"] != "def456" ||
+			build["build_time"] != "2026-10-16T09:30:00Z" || build["go_version"] != runtime.Version() {
+			t.Errorf("%s: unexpected build info %v", path, build)
+		}
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	app := newTestApp(t)
+	if timeout := app.shutdownTimeout(); timeout != DefaultShutdownTimeout {
+		t.Errorf("expected the default shutdown timeout, got %v", timeout)
+	}
+	app.config.Server.ShutdownTimeout = 100 * time.Millisecond
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	app.Get("/hang", func(ctx *Context) error {
+		close(entered)
+		<-release
+		return nil
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	served := make(chan error, 1)
+	go func() { served <- app.Serve(context.Background(), ln) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for app.Addr() == nil || app.starting.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("application did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	conn, err := ln.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET /hang HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	<-entered
+
+	start := time.Now()
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected shutdown to give up after the timeout, took %v", elapsed)
+	}
+
+	// The hung connection was closed
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Error("expected the open connection to be closed")
+	}
+	<-served
+}
+
+// addrConn is a net.Conn with a fixed remote address
+type addrConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c addrConn) RemoteAddr() net.Addr { return c.remote }
+func (c addrConn) Close() error         { return nil }
+
+// gaugeRecorder records the gauges that were set
+type gaugeRecorder struct {
+	NoopMetrics
+	mu     sync.Mutex
+	gauges map[string]float64
+}
+
+func (r *gaugeRecorder) Gauge(name string, value float64, labels Labels) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name+fmt.Sprint(labels)] = value
+}
+
+func TestConnTracker(t *testing.T) {
+	metrics := &gaugeRecorder{gauges: make(map[string]float64)}
+	tracker := newConnTracker(metrics)
+	conn := func(ip string) net.Conn {
+		return addrConn{remote: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50000}}
+	}
+	a1, a2, b := conn("10.0.0.1"), conn("10.0.0.1"), conn("10.0.0.2")
+
+	for _, c := range []net.Conn{a1, a2, b} {
+		tracker.connState(c, fasthttp.StateNew)
+	}
+	tracker.connState(a1, fasthttp.StateActive)
+	if counts := tracker.snapshot(); counts["10.0.0.1"] != 2 || counts["10.0.0.2"] != 1 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+
+	tracker.connState(a1, fasthttp.StateClosed)
+	tracker.connState(b, fasthttp.StateHijacked)
+	if counts := tracker.snapshot(); len(counts) != 1 || counts["10.0.0.1"] != 1 {
+		t.Errorf("expected closed IPs to be dropped, got %v", counts)
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if len(metrics.gauges) != 1 || metrics.gauges["http_connections_active"+fmt.Sprint(Labels(nil))] != 1 {
+		t.Errorf("expected only the total gauge, got %v", metrics.gauges)
+	}
+}
+
+func TestConnectionsPerIPWhileServing(t *testing.T) {
+	app := newTestApp(t)
+	if counts := app.ConnectionsPerIP(); len(counts) != 0 {
+		t.Errorf("expected no counts before the server runs, got %v", counts)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			app.ConnectionsPerIP()
+		}
+	}()
+	tracker := newConnTracker(NoopMetrics{})
+	app.connTracker.Store(tracker)
+	tracker.connState(addrConn{remote: &net.TCPAddr{IP: net.ParseIP("10.0.0.1")}}, fasthttp.StateNew)
+	<-done
+
+	if counts := app.ConnectionsPerIP(); counts["10.0.0.1"] != 1 {
+		t.Errorf("expected the tracker counts, got %v", counts)
+	}
+}
+
+func TestServeStopsStartedPluginsOnStartFailure(t *testing.T) {
+	app := newTestApp(t)
+	healthy := NewMockPlugin("healthy", PriorityHigh)
+	critical := NewMockPlugin("critical", PriorityNormal)
+	critical.metadata.Critical = true
+	critical.startError = errors.New("start failed")
+	later := NewMockPlugin("later", PriorityLow)
+	for _, plugin := range []Plugin{healthy, critical, later} {
+		if err := app.pluginManager.RegisterPlugin(plugin); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ln := fasthttputil.NewInmemoryListener()
+	errCh := make(chan error, 1)
+	go func() { errCh <- app.Serve(context.Background(), ln) }()
+	select {
+	case err := <-errCh:
+		if err == nil || !strings.Contains(err.Error(), "start failed") {
+			t.Fatalf("expected the start error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after the critical plugin failed")
+	}
+
+	if healthy.GetState() != StateStopped {
+		t.Errorf("expected the started plugin to be stopped, got state %d", healthy.GetState())
+	}
+	if later.GetState() != StateInitialized {
+		t.Errorf("expected the plugin that never started to be left alone, got state %d", later.GetState())
+	}
+	if app.starting.Load() {
+		t.Error("expected starting to be reset")
+	}
+}
+
+func TestServerRequestSizeLimits(t *testing.T) {
+	app := newTestApp(t)
+	app.config.Server.MaxHeaderSize = 2048
+	app.config.Server.MaxURLLength = 64
+	app.Get("/items", func(ctx *Context) error {
+		return ctx.String("ok")
+	})
+
+	ln := fasthttputil.NewInmemoryListener()
+	served := make(chan error, 1)
+	go func() { served <- app.Serve(context.Background(), ln) }()
+	defer func() {
+		app.Shutdown(context.Background())
+		<-served
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for app.Addr() == nil || app.starting.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("application did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	client := &fasthttp.Client{Dial: func(addr string) (net.Conn, error) { return ln.Dial() }}
+	request := func(uri string, header string) int {
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		resp := fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseResponse(resp)
+
+		req.SetRequestURI("http://example.com" + uri)
+		if header != "" {
+			req.Header.Set("X-Padding", header)
+		}
+		if err := client.DoTimeout(req, resp, 5*time.Second); err != nil {
+			t.Fatalf("%s: request failed: %v", uri, err)
+		}
+		return resp.StatusCode()
+	}
+
+	if status := request("/items?q=short", ""); status != OKStatus {
+		t.Errorf("expected a short URI to pass, got %d", status)
+	}
+	if status := request("/items?q="+strings.Repeat("a", 100), ""); status != URITooLongStatus {
+		t.Errorf("expected 414 for a URI over max_url_length, got %d", status)
+	}
+	if status := request("/items", strings.Repeat("a", 1024)); status != OKStatus {
+		t.Errorf("expected headers below max_header_size to pass, got %d", status)
+	}
+	if status := request("/items", strings.Repeat("a", 4096)); status != RequestHeaderFieldsTooLargeStatus {
+		t.Errorf("expected 431 for headers over max_header_size, got %d", status)
+	}
+}
+
+// startStreamingServer serves app with server.stream_request_body enabled
+func startStreamingServer(t *testing.T, app *Application) *fasthttputil.InmemoryListener {
+	t.Helper()
+	app.config.Server.StreamRequestBody = true
+
+	ln := fasthttputil.NewInmemoryListener()
+	served := make(chan error, 1)
+	go func() { served <- app.Serve(context.Background(), ln) }()
+	t.Cleanup(func() {
+		app.Shutdown(context.Background())
+		<-served
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for app.Addr() == nil || app.starting.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("application did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return ln
+}
+
+// streamRequest sends req over a new connection and reads the response
+// while the body is still being written, since the server may answer
+// before reading the whole body
+func streamRequest(t *testing.T, ln *fasthttputil.InmemoryListener, req *fasthttp.Request) *fasthttp.Response {
+	t.Helper()
+	conn, err := ln.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	go func() {
+		writer := bufio.NewWriter(conn)
+		if err := req.Write(writer); err == nil {
+			writer.Flush()
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp := &fasthttp.Response{}
+	if err := resp.Read(bufio.NewReader(conn)); err != nil {
+		t.Fatalf("%s: reading the response failed: %v", req.URI(), err)
+	}
+	return resp
+}
+
+func TestServerStreamRequestBody(t *testing.T) {
+	app := newTestApp(t)
+	app.config.Server.MaxRequestBodySize = 1024
+	app.Post("/upload", func(ctx *Context) error {
+		if ctx.fastCtx.RequestBodyStream() == nil {
+			return ctx.Error(InternalServerErrorStatus, "not_streamed", "Body was buffered")
+		}
+		data, err := io.ReadAll(ctx.BodyStream())
+		if err != nil {
+			return bodyReadError(err)
+		}
+		return ctx.String(fmt.Sprintf("%d %x", len(data), sha256.Sum256(data)))
+	})
+	app.Post("/json", func(ctx *Context) error {
+		var payload struct{ Data string }
+		if err := ctx.BindAndValidate(&payload); err != nil {
+			return bodyReadError(err)
+		}
+		return ctx.String(fmt.Sprintf("%d %d", len(payload.Data), len(ctx.Body())))
+	})
+	app.Post("/raw", func(ctx *Context) error {
+		return ctx.String(fmt.Sprintf("%d", len(ctx.Body())))
+	})
+	ln := startStreamingServer(t, app)
+
+	jsonRequest := func(path string, size int, chunked bool) *fasthttp.Response {
+		req := &fasthttp.Request{}
+		req.SetRequestURI("http://example.com" + path)
+		req.Header.SetMethod("POST")
+		// BindAndValidate decodes JSON without a Content-Type
+		req.Header.SetNoDefaultContentType(true)
+		body := []byte(`{"data":"` + strings.Repeat("a", size) + `"}`)
+		if chunked {
+			req.SetBodyStream(bytes.NewReader(body), -1)
+		} else {
+			req.SetBody(body)
+		}
+		return streamRequest(t, ln, req)
+	}
+	for _, chunked := range []bool{false, true} {
+		if resp := jsonRequest("/json", 100, chunked); string(resp.Body()) != "100 111" {
+			t.Errorf("chunked %v: expected the JSON body to bind, got %d %q", chunked, resp.StatusCode(), resp.Body())
+		}
+		if resp := jsonRequest("/json", 100000, chunked); resp.StatusCode() != PayloadTooLargeStatus {
+			t.Errorf("chunked %v: expected 413 for an oversized JSON body, got %d %q", chunked, resp.StatusCode(), resp.Body())
+		}
+		if resp := jsonRequest("/raw", 100, chunked); string(resp.Body()) != "111" {
+			t.Errorf("chunked %v: expected Body to return the body, got %q", chunked, resp.Body())
+		}
+		if resp := jsonRequest("/raw", 100000, chunked); string(resp.Body()) != "0" {
+			t.Errorf("chunked %v: expected Body to return nil past the limit, got %q", chunked, resp.Body())
+		}
+	}
+
+	for _, chunked := range []bool{false, true} {
+		for _, size := range []int{0, 100, 1024, 1025, 100000} {
+			req := &fasthttp.Request{}
+			req.SetRequestURI("http://example.com/upload")
+			req.Header.SetMethod("POST")
+			body := bytes.Repeat([]byte("0123456789"), size/10+1)[:size]
+			if chunked {
+				req.SetBodyStream(bytes.NewReader(body), -1)
+			} else {
+				req.SetBody(body)
+			}
+			resp := streamRequest(t, ln, req)
+
+			if size > 1024 {
+				if resp.StatusCode() != PayloadTooLargeStatus {
+					t.Errorf("size %d chunked %v: expected 413, got %d %q", size, chunked, resp.StatusCode(), resp.Body())
+				}
+			} else if expected := fmt.Sprintf("%d %x", size, sha256.Sum256(body)); string(resp.Body()) != expected {
+				t.Errorf("size %d chunked %v: expected %q, got %d %q", size, chunked, expected, resp.StatusCode(), resp.Body())
+			}
+		}
+	}
+}
+
+func TestServerStreamMultipart(t *testing.T) {
+	app := newTestApp(t)
+	app.config.Server.MaxRequestBodySize = 4096
+	app.Post("/upload", func(ctx *Context) error {
+		reader, err := ctx.MultipartReader()
+		if err != nil {
+			return ctx.Error(BadRequestStatus, "invalid_multipart", err.Error())
+		}
+		var parts []string
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return bodyReadError(err)
+			}
+			data, err := io.ReadAll(part)
+			if err != nil {
+				return bodyReadError(err)
+			}
+			parts = append(parts, fmt.Sprintf("%s:%s:%d", part.FormName(), part.FileName(), len(data)))
+		}
+		return ctx.String(strings.Join(parts, ","))
+	})
+	app.Post("/form", func(ctx *Context) error {
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			return ctx.Error(BadRequestStatus, "invalid_form", err.Error())
+		}
+		return ctx.String(fmt.Sprintf("title::%d,file:%s:%d", len(ctx.FormValue("title")), file.Filename, file.Size))
+	})
+	ln := startStreamingServer(t, app)
+
+	upload := func(path string, fileSize int, chunked bool) *fasthttp.Response {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.WriteField("title", "report")
+		file, _ := writer.CreateFormFile("file", "report.csv")
+		file.Write(bytes.Repeat([]byte("a"), fileSize))
+		writer.Close()
+
+		req := &fasthttp.Request{}
+		req.SetRequestURI("http://example.com" + path)
+		req.Header.SetMethod("POST")
+		req.Header.SetContentType(writer.FormDataContentType())
+		if chunked {
+			req.SetBodyStream(bytes.NewReader(body.Bytes()), -1)
+		} else {
+			req.SetBody(body.Bytes())
+		}
+		return streamRequest(t, ln, req)
+	}
+
+	for _, chunked := range []bool{false, true} {
+		for _, path := range []string{"/upload", "/form"} {
+			resp := upload(path, 1000, chunked)
+			if expected := "title::6,file:report.csv:1000"; string(resp.Body()) != expected {
+				t.Errorf("%s chunked %v: expected %q, got %d %q", path, chunked, expected, resp.StatusCode(), resp.Body())
+			}
+		}
+
+		resp := upload("/upload", 100000, chunked)
+		if resp.StatusCode() != PayloadTooLargeStatus {
+			t.Errorf("chunked %v: expected 413 for a body over max_request_body_size, got %d %q", chunked, resp.StatusCode(), resp.Body())
+		}
+	}
+
+	for _, contentType := range []string{"", "application/json", "multipart/form-data", "multipart/form-data; boundary=\"x"} {
+		req := &fasthttp.Request{}
+		req.SetRequestURI("http://example.com/upload")
+		req.Header.SetMethod("POST")
+		if contentType != "" {
+			req.Header.SetContentType(contentType)
+		}
+		req.SetBodyString("payload")
+		if resp := streamRequest(t, ln, req); resp.StatusCode() != BadRequestStatus {
+			t.Errorf("content type %q: expected 400, got %d %q", contentType, resp.StatusCode(), resp.Body())
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package gorgo
+
+import (
+	"net/http"
+)
+
+// ErrorFormat selects how the framework serializes error responses
+type ErrorFormat int
+
+const (
+	// ErrorFormatJSON writes ErrorResponse bodies, the default
+	ErrorFormatJSON ErrorFormat = iota
+	// ErrorFormatProblemJSON writes RFC 7807 application/problem+json
+	// documents
+	ErrorFormatProblemJSON
+)
+
+// ProblemContentType is the media type of RFC 7807 problem documents
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem document. Extensions are written
+// as additional top-level members; they cannot replace the standard ones.
+type ProblemDetails struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON flattens the extension members into the document
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		doc[key] = value
+	}
+
+	doc["type"] = p.Type
+	if p.Type == "" {
+		doc["type"] = "about:blank"
+	}
+	doc["title"] = p.Title
+	if p.Status != 0 {
+		doc["status"] = p.Status
+	}
+	if p.Detail != "" {
+		doc["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		doc["instance"] = p.Instance
+	}
+	return GetJSONCodec().Marshal(doc)
+}
+
+// SetErrorFormat selects how HTTPErrors, ctx.Error responses and recovered
+// panics are serialized. With ErrorFormatProblemJSON they become
+// application/problem+json documents: the status text is the title, the
+// message the detail and the request path the instance, while the error
+// code and details are added as extension members. A custom ErrorHandler
+// still takes precedence.
+func (a *Application) SetErrorFormat(format ErrorFormat) *Application {
+	a.errorFormat = format
+	return a
+}
+
+// Problem writes an RFC 7807 problem document. A missing status defaults
+// to 500, a missing title to the status text and a missing instance to the
+// request path.
+//
+//	return ctx.Problem(gorgo.ProblemDetails{
+//	    Type:       "https://example.com/problems/out-of-credit",
+//	    Status:     gorgo.ForbiddenStatus,
+//	    Detail:     "Your current balance is 30, but that costs 50.",
+//	    Extensions: map[string]interface{}{"balance": 30},
+//	})
+func (c *Context) Problem(problem ProblemDetails) error {
+	if problem.Status == 0 {
+		problem.Status = InternalServerErrorStatus
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Instance == "" {
+		problem.Instance = c.Path()
+	}
+
+	data, err := problem.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	c.fastCtx.SetStatusCode(problem.Status)
+	c.fastCtx.Response.Header.SetContentType(ProblemContentType)
+	c.fastCtx.SetBody(data)
+	return nil
+}
+
+// errorProblem converts an error response to a problem document. The
+// code and details become extension members.
+func errorProblem(response ErrorResponse, status int) ProblemDetails {
+	extensions := make(map[string]interface{}, len(response.Details)+1)
+	for key, value := range response.Details {
+		extensions[key] = value
+	}
+	if response.Code != "" {
+		extensions["code"] = response.Code
+	}
+	return ProblemDetails{Status: status, Detail: response.Message, Extensions: extensions}
+}
+
+func (e *HTTPError) problem() ProblemDetails {
+	problem := errorProblem(ErrorResponse{Code: e.Code, Message: e.Message, Details: e.Details}, e.Status)
+	problem.Type = e.Type
+	return problem
+}
+
+// writeInternalError writes the default 500 response: plain text, or a
+// problem document in problem mode
+func (c *Context) writeInternalError() {
+	c.fastCtx.Response.ResetBody()
+	if c.errorFormat == ErrorFormatProblemJSON {
+		c.Problem(NewHTTPError(InternalServerErrorStatus, "internal_error", "Internal Server Error").problem())
+		return
+	}
+	c.fastCtx.SetStatusCode(InternalServerErrorStatus)
+	c.fastCtx.SetBodyString("Internal Server Error")
+}
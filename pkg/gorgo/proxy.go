@@ -0,0 +1,126 @@
+package gorgo
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// hopByHopHeaders apply to a single connection and are not forwarded (RFC 9110, 7.6.1)
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"TE",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// ProxyOptions configuration for the client used by ctx.Proxy
+type ProxyOptions struct {
+	// Timeout bounds writing the request and reading the response
+	Timeout time.Duration
+	// MaxConnsPerHost limits pooled connections to each upstream
+	MaxConnsPerHost int
+	// MaxIdleConnDuration closes pooled connections idle for longer
+	MaxIdleConnDuration time.Duration
+}
+
+// DefaultProxyOptions returns default proxy client settings
+func DefaultProxyOptions() ProxyOptions {
+	return ProxyOptions{
+		Timeout:             30 * time.Second,
+		MaxConnsPerHost:     512,
+		MaxIdleConnDuration: 90 * time.Second,
+	}
+}
+
+// NewProxyClient creates a pooling client for ctx.Proxy. Create it once
+// and share it between requests.
+func NewProxyClient(options ProxyOptions) *fasthttp.Client {
+	return &fasthttp.Client{
+		ReadTimeout:         options.Timeout,
+		WriteTimeout:        options.Timeout,
+		MaxConnsPerHost:     options.MaxConnsPerHost,
+		MaxIdleConnDuration: options.MaxIdleConnDuration,
+		// Forward paths and headers as received
+		DisablePathNormalizing:        true,
+		DisableHeaderNamesNormalizing: true,
+	}
+}
+
+// defaultProxyClient is used by ctx.Proxy when no client is given
+var defaultProxyClient = NewProxyClient(DefaultProxyOptions())
+
+// Proxy forwards the request (method, headers and body) to upstreamURL and
+// copies the upstream status, headers and body into the response.
+// upstreamURL is used as given, e.g. "http://users:8080" + ctx.Path().
+// Hop-by-hop headers are stripped in both directions and X-Forwarded-For,
+// X-Forwarded-Host and X-Forwarded-Proto are set. An unreachable upstream
+// results in 502, a timeout in 504. The optional client replaces the
+// shared default client.
+func (c *Context) Proxy(upstreamURL string, client ...*fasthttp.Client) error {
+	proxyClient := defaultProxyClient
+	if len(client) > 0 && client[0] != nil {
+		proxyClient = client[0]
+	}
+
+	body, err := c.readBody()
+	if err != nil {
+		return c.writeError(bodyReadError(err))
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	c.fastCtx.Request.Header.CopyTo(&req.Header)
+	req.SetBody(body)
+	req.SetRequestURI(upstreamURL)
+	req.Header.SetHostBytes(req.URI().Host())
+	stripHopByHop(&req.Header)
+
+	forwardedFor := c.ClientIP()
+	if prior := c.GetHeader("X-Forwarded-For"); prior != "" {
+		forwardedFor = prior + ", " + forwardedFor
+	}
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	req.Header.Set("X-Forwarded-Host", c.ForwardedHost())
+	req.Header.Set("X-Forwarded-Proto", c.ForwardedProto())
+
+	if err := proxyClient.Do(req, resp); err != nil {
+		if errors.Is(err, fasthttp.ErrTimeout) {
+			return c.writeError(&HTTPError{Status: GatewayTimeoutStatus, Code: "upstream_timeout", Message: "Upstream did not respond in time", Err: err})
+		}
+		return c.writeError(&HTTPError{Status: BadGatewayStatus, Code: "bad_gateway", Message: "Upstream is unavailable", Err: err})
+	}
+
+	resp.CopyTo(&c.fastCtx.Response)
+	stripHopByHop(&c.fastCtx.Response.Header)
+	return nil
+}
+
+// hopByHopHeaderSet is implemented by request and response headers
+type hopByHopHeaderSet interface {
+	Peek(key string) []byte
+	Del(key string)
+}
+
+// stripHopByHop removes hop-by-hop headers, including the headers named in
+// the Connection header
+func stripHopByHop(header hopByHopHeaderSet) {
+	for _, name := range strings.Split(string(header.Peek("Connection")), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			header.Del(name)
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
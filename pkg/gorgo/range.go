@@ -0,0 +1,171 @@
+package gorgo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidRange is returned by ParseRange for a malformed Range header.
+// Such a header should be ignored and the full content sent.
+var ErrInvalidRange = errors.New("invalid range")
+
+// ErrRangeNotSatisfiable is returned by ParseRange when none of the
+// requested ranges overlaps the content; respond with 416
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// HTTPRange is a byte range of the content, resolved against its size
+type HTTPRange struct {
+	Start  int64
+	Length int64
+}
+
+// ContentRange formats the range as a Content-Range header value
+func (r HTTPRange) ContentRange(totalSize int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.Start, r.Start+r.Length-1, totalSize)
+}
+
+// ParseRange parses the Range header against content of totalSize bytes.
+// It returns no ranges without a Range header, ErrInvalidRange for a
+// malformed one and ErrRangeNotSatisfiable when no range overlaps the
+// content. Suffix ranges ("bytes=-500") and open ends ("bytes=500-") are
+// resolved, and ends past the content are clamped to its last byte.
+//
+//	ranges, err := ctx.ParseRange(int64(len(data)))
+func (c *Context) ParseRange(totalSize int64) ([]HTTPRange, error) {
+	return parseRange(c.GetHeader("Range"), totalSize)
+}
+
+func parseRange(header string, totalSize int64) ([]HTTPRange, error) {
+	if header == "" {
+		return nil, nil
+	}
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return nil, ErrInvalidRange
+	}
+
+	var ranges []HTTPRange
+	noOverlap := false
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, ErrInvalidRange
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r HTTPRange
+		if first == "" {
+			// Suffix range: the last n bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, ErrInvalidRange
+			}
+			if n == 0 || totalSize == 0 {
+				noOverlap = true
+				continue
+			}
+			if n > totalSize {
+				n = totalSize
+			}
+			r = HTTPRange{Start: totalSize - n, Length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, ErrInvalidRange
+			}
+			end := totalSize - 1
+			if last != "" {
+				end, err = strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					return nil, ErrInvalidRange
+				}
+			}
+			if start >= totalSize {
+				noOverlap = true
+				continue
+			}
+			if end >= totalSize {
+				end = totalSize - 1
+			}
+			r = HTTPRange{Start: start, Length: end - start + 1}
+		}
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		if noOverlap {
+			return nil, ErrRangeNotSatisfiable
+		}
+		return nil, ErrInvalidRange
+	}
+	return ranges, nil
+}
+
+// PartialContent responds 206 Partial Content with the range r of content
+// that is totalSize bytes long. body must yield exactly r.Length bytes;
+// it is streamed and closed afterwards if it is an io.Closer.
+func (c *Context) PartialContent(r HTTPRange, totalSize int64, body io.Reader) error {
+	c.fastCtx.Response.Header.Set("Accept-Ranges", "bytes")
+	c.fastCtx.Response.Header.Set("Content-Range", r.ContentRange(totalSize))
+	c.fastCtx.SetStatusCode(PartialContentStatus)
+	c.fastCtx.Response.SetBodyStream(body, int(r.Length))
+	return nil
+}
+
+// SendRange sends content of the given size, such as a bytes.Reader over
+// generated data, with support for resumable downloads. A GET or HEAD
+// request for a single range gets 206 Partial Content and an
+// unsatisfiable range 416. Only single ranges are supported: without a
+// Range header, or for a malformed or multi-range one, the full content is
+// sent with 200, which RFC 9110 allows.
+//
+// If the handler has set ETag or Last-Modified, e.g. through
+// CheckPreconditions, an If-Range header that doesn't match them gets the
+// full content, so a client never resumes from a changed representation.
+// Set the content type before calling SendRange.
+//
+//	ctx.Header("Content-Type", "application/zip")
+//	return ctx.SendRange(bytes.NewReader(archive), int64(len(archive)))
+func (c *Context) SendRange(content io.ReaderAt, size int64) error {
+	c.fastCtx.Response.Header.Set("Accept-Ranges", "bytes")
+
+	var ranges []HTTPRange
+	if method := c.Method(); (method == "GET" || method == "HEAD") && c.ifRangeMatches() {
+		var err error
+		ranges, err = c.ParseRange(size)
+		if errors.Is(err, ErrRangeNotSatisfiable) {
+			c.fastCtx.Response.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			return c.writeError(&HTTPError{Status: RangeNotSatisfiableStatus, Code: "range_not_satisfiable", Message: "Requested range not satisfiable", Err: err})
+		}
+	}
+
+	if len(ranges) != 1 {
+		c.fastCtx.SetStatusCode(OKStatus)
+		c.fastCtx.Response.SetBodyStream(io.NewSectionReader(content, 0, size), int(size))
+		return nil
+	}
+	r := ranges[0]
+	return c.PartialContent(r, size, io.NewSectionReader(content, r.Start, r.Length))
+}
+
+// ifRangeMatches reports whether the If-Range header, if any, matches the
+// ETag or Last-Modified response header. A weak ETag never matches.
+func (c *Context) ifRangeMatches() bool {
+	ifRange := strings.TrimSpace(c.GetHeader("If-Range"))
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) || isWeakETag(ifRange) {
+		etag := string(c.fastCtx.Response.Header.Peek("ETag"))
+		return etag != "" && !isWeakETag(ifRange) && !isWeakETag(etag) && ifRange == etag
+	}
+	lastModified := string(c.fastCtx.Response.Header.Peek("Last-Modified"))
+	return lastModified != "" && ifRange == lastModified
+}
@@ -0,0 +1,100 @@
+package gorgo
+
+import "sort"
+
+// PluginStates returns the current state of every registered plugin,
+// including disabled ones
+func (a *Application) PluginStates() map[string]PluginState {
+	return a.pluginManager.PluginStates()
+}
+
+// Ready reports whether startup is complete, the application is not
+// draining and every enabled plugin is running. Non-critical plugins that
+// failed to start don't block readiness.
+func (a *Application) Ready() bool {
+	if a.starting.Load() || a.draining.Load() {
+		return false
+	}
+	for _, state := range a.pluginManager.PluginStates() {
+		if state != StateRunning && state != StateError && state != StateDisabled {
+			return false
+		}
+	}
+	return true
+}
+
+// Readiness registers a readiness endpoint. It responds 200 when the
+// application is Ready and 503 otherwise, listing the plugins by state:
+//
+//	{"status": "starting", "started": ["redis"], "pending": ["sql"], "failed": [],
+//	 "plugins": {"redis": "running", "sql": "starting"},
+//	 "build": {"version": "1.4.2", "commit": "9d726f8...", "go_version": "go1.23.4"}}
+//
+// While plugins start, the server already accepts connections but answers
+// every other path with 503, so probes can follow startup progress.
+func (a *Application) Readiness(path string) *Application {
+	if a.readinessPaths == nil {
+		a.readinessPaths = make(map[string]bool)
+	}
+	a.readinessPaths[path] = true
+	a.Get(path, a.readinessHandler)
+	return a
+}
+
+func (a *Application) readinessHandler(ctx *Context) error {
+	started := []string{}
+	pending := []string{}
+	failed := []string{}
+	plugins := make(Map)
+
+	for name, state := range a.pluginManager.PluginStates() {
+		plugins[name] = state.String()
+		switch state {
+		case StateRunning:
+			started = append(started, name)
+		case StateError:
+			failed = append(failed, name)
+		case StateDisabled:
+		default:
+			pending = append(pending, name)
+		}
+	}
+	sort.Strings(started)
+	sort.Strings(pending)
+	sort.Strings(failed)
+
+	status := "ready"
+	switch {
+	case a.starting.Load():
+		status = "starting"
+	case a.draining.Load():
+		status = "draining"
+	}
+	if !a.Ready() {
+		ctx.Status(ServiceUnavailableStatus)
+		if status == "ready" {
+			status = "not_ready"
+		}
+	}
+
+	return ctx.JSON(Map{
+		"status":  status,
+		"started": started,
+		"pending": pending,
+		"failed":  failed,
+		"plugins": plugins,
+		"build":   a.BuildInfo(),
+	})
+}
+
+// rejectWhileStarting answers requests other than readiness probes with
+// 503 while plugins are starting
+func (a *Application) rejectWhileStarting(ctx *Context) bool {
+	if !a.starting.Load() || a.readinessPaths[ctx.Path()] {
+		return false
+	}
+
+	ctx.Header("Retry-After", "1")
+	ctx.writeError(NewHTTPError(ServiceUnavailableStatus, "starting", "The service is starting, please try again shortly"))
+	return true
+}
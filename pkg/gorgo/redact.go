@@ -0,0 +1,130 @@
+package gorgo
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces secret config values in logs and event data
+const RedactedValue = "***"
+
+var secretKeys = struct {
+	mu   sync.RWMutex
+	keys map[string]bool
+}{
+	keys: map[string]bool{
+		"password":    true,
+		"passwd":      true,
+		"secret":      true,
+		"token":       true,
+		"api_key":     true,
+		"apikey":      true,
+		"private_key": true,
+		"credentials": true,
+	},
+}
+
+// urlConfigKeys hold connection strings whose password is redacted while
+// the rest stays readable
+var urlConfigKeys = map[string]bool{"dsn": true, "url": true}
+
+// MarkSecretConfigKey marks plugin config keys as secret, in addition to
+// the built-in ones such as password, secret and token. Keys ending in
+// "_password", "_secret" or "_token" are secret as well.
+func MarkSecretConfigKey(keys ...string) {
+	secretKeys.mu.Lock()
+	defer secretKeys.mu.Unlock()
+	for _, key := range keys {
+		secretKeys.keys[strings.ToLower(key)] = true
+	}
+}
+
+// IsSecretConfigKey reports whether values of the config key are redacted
+func IsSecretConfigKey(key string) bool {
+	key = strings.ToLower(key)
+
+	secretKeys.mu.RLock()
+	secret := secretKeys.keys[key]
+	secretKeys.mu.RUnlock()
+	if secret {
+		return true
+	}
+
+	for _, suffix := range []string{"_password", "_secret", "_token"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactConfig returns a copy of a plugin config with secret values
+// replaced by RedactedValue and passwords removed from dsn/url values.
+// Nested maps are redacted recursively.
+func RedactConfig(config map[string]interface{}) map[string]interface{} {
+	if config == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		switch {
+		case IsSecretConfigKey(key):
+			redacted[key] = RedactedValue
+		case urlConfigKeys[strings.ToLower(key)]:
+			if str, ok := value.(string); ok {
+				redacted[key] = RedactURL(str)
+			} else {
+				redacted[key] = value
+			}
+		default:
+			if nested, ok := value.(map[string]interface{}); ok {
+				redacted[key] = RedactConfig(nested)
+			} else {
+				redacted[key] = value
+			}
+		}
+	}
+	return redacted
+}
+
+// RedactURL replaces the password of a URL-style connection string with
+// RedactedValue. Strings that are not URLs with credentials are returned
+// unchanged, except key=value strings whose password is redacted.
+func RedactURL(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), RedactedValue)
+			// Keep the placeholder readable instead of percent-encoded
+			return strings.Replace(u.String(), "%2A%2A%2A", RedactedValue, 1)
+		}
+		return raw
+	}
+
+	fields := strings.Fields(raw)
+	redacted := false
+	for i, field := range fields {
+		if key, _, ok := strings.Cut(field, "="); ok && IsSecretConfigKey(key) {
+			fields[i] = key + "=" + RedactedValue
+			redacted = true
+		}
+	}
+	if !redacted {
+		return raw
+	}
+	return strings.Join(fields, " ")
+}
+
+// Redacted returns a copy of the config that is safe to log or publish,
+// with secret plugin config values redacted
+func (c Config) Redacted() Config {
+	redacted := c
+	if c.Plugins != nil {
+		redacted.Plugins = make(map[string]map[string]interface{}, len(c.Plugins))
+		for name, config := range c.Plugins {
+			redacted.Plugins[name] = RedactConfig(config)
+		}
+	}
+	return redacted
+}
@@ -1,59 +1,229 @@
 package gorgo
 
-import "strings"
+import (
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+)
 
+// Route describes a route for registration and introspection
+type Route struct {
+	Method     string
+	Path       string
+	Handler    HandlerFunc
+	Middleware []MiddlewareFunc
+	// Name optionally identifies the route
+	Name string
+	// ResponseSchema optionally declares the type of the JSON response,
+	// checked in debug mode only (see Application.ResponseSchema)
+	ResponseSchema interface{}
+}
+
+// MaxRouteSegments is the maximum number of path segments in a route,
+// deeper routes are rejected at registration
+const MaxRouteSegments = 32
+
+// Router matches requests against a tree of path segments per method, so
+// a lookup only visits the routes that share a prefix with the request
+// path. routes keeps the registered patterns for introspection.
 type Router struct {
 	routes map[string]map[string]HandlerFunc
+	trees  map[string]*routeNode
+	names  map[string]string // "METHOD path" -> route name
+	// preflight holds the automatic OPTIONS handler of each path that has
+	// no explicit OPTIONS route
+	preflight     map[string]HandlerFunc
+	preflightTree *routeNode
+	// rawPaths disables collapsing duplicate slashes in route and request paths
+	rawPaths bool
 }
 
 func NewRouter() *Router {
 	return &Router{
-		routes: make(map[string]map[string]HandlerFunc),
+		routes:        make(map[string]map[string]HandlerFunc),
+		trees:         make(map[string]*routeNode),
+		names:         make(map[string]string),
+		preflight:     make(map[string]HandlerFunc),
+		preflightTree: newRouteNode(),
+	}
+}
+
+// SetPathNormalization controls whether paths are cleaned before routing:
+// duplicate slashes are collapsed and "." and ".." segments resolved, so
+// "/users//1" matches "/users/:id" and "/admin/../public" routes to
+// "/public". A trailing slash is kept. Normalization is enabled by default;
+// disable it before adding routes when empty segments are meaningful, e.g.
+// URLs captured by a wildcard.
+func (r *Router) SetPathNormalization(enabled bool) {
+	r.rawPaths = !enabled
+}
+
+// normalizePath cleans p unless normalization is disabled
+func (r *Router) normalizePath(p string) string {
+	if r.rawPaths {
+		return p
 	}
+	return cleanPath(p)
 }
 
+// cleanPath collapses duplicate slashes and resolves "." and ".." segments
+// of an absolute path, keeping a trailing slash. ".." never leaves the root.
+func cleanPath(p string) string {
+	if !strings.HasPrefix(p, "/") || (!strings.Contains(p, "//") && !hasDotSegment(p)) {
+		return p
+	}
+
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// hasDotSegment reports whether p has a "." or ".." segment
+func hasDotSegment(p string) bool {
+	for rest, more := p, true; more; {
+		var segment string
+		segment, rest, more = nextSegment(rest)
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// AddRoute registers handler for method and path. Path segments starting
+// with ':' match a single segment, a final segment "*name" matches the rest
+// of the path including slashes, e.g. "/static/*filepath".
 func (r *Router) AddRoute(method, path string, handler HandlerFunc) {
+	path = r.normalizePath(path)
+	if err := validateRoutePath(path); err != nil {
+		log.Printf("Router: ignoring %s %s: %v", method, path, err)
+		return
+	}
 	if r.routes[method] == nil {
 		r.routes[method] = make(map[string]HandlerFunc)
+		r.trees[method] = newRouteNode()
 	}
 	r.routes[method][path] = handler
+	r.trees[method].insert(path, handler)
 }
 
-func (r *Router) FindHandler(method, path string) (HandlerFunc, map[string]string) {
-	if methodRoutes, exists := r.routes[method]; exists {
-		if handler, exists := methodRoutes[path]; exists {
-			return handler, nil
+// HasRoute reports whether a route is registered for exactly this method and path
+func (r *Router) HasRoute(method, path string) bool {
+	_, exists := r.routes[method][r.normalizePath(path)]
+	return exists
+}
+
+// setName names the route registered for method and path
+func (r *Router) setName(method, path, name string) {
+	r.names[method+" "+r.normalizePath(path)] = name
+}
+
+// setPreflight sets the automatic OPTIONS handler for path. Unless
+// replace is set, an existing handler is kept.
+func (r *Router) setPreflight(path string, handler HandlerFunc, replace bool) {
+	path = r.normalizePath(path)
+	if validateRoutePath(path) != nil {
+		return
+	}
+	if _, exists := r.preflight[path]; exists && !replace {
+		return
+	}
+	r.preflight[path] = handler
+	r.preflightTree.insert(path, handler)
+}
+
+// allowedMethods returns the methods registered for path, including OPTIONS
+func (r *Router) allowedMethods(path string) []string {
+	path = r.normalizePath(path)
+	methods := []string{"OPTIONS"}
+	for method, methodRoutes := range r.routes {
+		if _, exists := methodRoutes[path]; exists && method != "OPTIONS" {
+			methods = append(methods, method)
 		}
+	}
+	sort.Strings(methods)
+	return methods
+}
 
-		for routePath, handler := range methodRoutes {
-			if params := r.matchPath(routePath, path); params != nil {
-				return handler, params
-			}
+func (r *Router) hasName(name string) bool {
+	for _, existing := range r.names {
+		if existing == name {
+			return true
 		}
 	}
-	return nil, nil
+	return false
+}
+
+// Routes returns all registered routes sorted by path and method
+func (r *Router) Routes() []Route {
+	var routes []Route
+	for method, methodRoutes := range r.routes {
+		for path, handler := range methodRoutes {
+			routes = append(routes, Route{
+				Method:  method,
+				Path:    path,
+				Handler: handler,
+				Name:    r.names[method+" "+path],
+			})
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
 }
 
-func (r *Router) matchPath(routePath, requestPath string) map[string]string {
-	routeParts := strings.Split(routePath, "/")
-	requestParts := strings.Split(requestPath, "/")
+func (r *Router) FindHandler(method, path string) (HandlerFunc, map[string]string) {
+	handler, params, _ := r.findRoute(method, path)
+	return handler, params
+}
 
-	if len(routeParts) != len(requestParts) {
-		return nil
+// findRoute is FindHandler that also returns the matched route pattern,
+// e.g. "/users/:id"
+func (r *Router) findRoute(method, path string) (HandlerFunc, map[string]string, string) {
+	path = r.normalizePath(path)
+	if tree, exists := r.trees[method]; exists {
+		if handler, params, pattern := tree.lookup(path); handler != nil {
+			return handler, params, pattern
+		}
 	}
 
-	params := make(map[string]string)
+	if method == "OPTIONS" {
+		return r.preflightTree.lookup(path)
+	}
+	return nil, nil, ""
+}
 
-	for i, routePart := range routeParts {
-		if strings.HasPrefix(routePart, ":") {
-			paramName := routePart[1:] // Remove the ':' prefix
-			params[paramName] = requestParts[i]
+// isWildcardRoute reports whether the last segment of routePath is "*name"
+func isWildcardRoute(routePath string) bool {
+	return strings.HasPrefix(routePath[strings.LastIndex(routePath, "/")+1:], "*")
+}
+
+// validateRoutePath checks the route depth and that a wildcard is named and
+// the last segment
+func validateRoutePath(routePath string) error {
+	parts := strings.Split(routePath, "/")
+	if len(parts)-1 > MaxRouteSegments {
+		return fmt.Errorf("route has %d segments, the maximum is %d", len(parts)-1, MaxRouteSegments)
+	}
+	for i, part := range parts {
+		if !strings.HasPrefix(part, "*") {
 			continue
 		}
-		if routePart != requestParts[i] {
-			return nil
+		if i != len(parts)-1 {
+			return fmt.Errorf("wildcard %q must be the last path segment", part)
+		}
+		if len(part) == 1 {
+			return fmt.Errorf("wildcard must be named, e.g. \"*filepath\"")
 		}
 	}
-
-	return params
+	return nil
 }
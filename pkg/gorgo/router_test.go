@@ -1,7 +1,11 @@
 package gorgo
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/valyala/fasthttp"
 )
 
 func TestRouterParameterExtraction(t *testing.T) {
@@ -72,3 +76,427 @@ func TestRouterNoMatch(t *testing.T) {
 		t.Error("Expected no parameters, got some")
 	}
 }
+
+func TestVersioningResolve(t *testing.T) {
+	v := newVersioning()
+	v.options.Vendor = "myapi"
+	v.options.Default = "v1"
+	v.versions["v1"] = true
+	v.versions["v2"] = true
+
+	tests := []struct {
+		path, accept    string
+		version, routed string
+	}{
+		{"/v2/users", "", "v2", "/v2/users"},
+		{"/users", "application/vnd.myapi.v2+json", "v2", "/v2/users"},
+		{"/users", "text/html, application/vnd.myapi.v2+json; q=0.9", "v2", "/v2/users"},
+		{"/users", "application/json", "v1", "/v1/users"},
+		{"/users", "application/vnd.myapi.v9+json", "v1", "/v1/users"},
+		{"/v2/users", "application/vnd.myapi.v1+json", "v2", "/v2/users"},
+	}
+
+	for _, tt := range tests {
+		version, routed := v.resolve(tt.path, tt.accept)
+		if version != tt.version || routed != tt.routed {
+			t.Errorf("resolve(%q, %q): expected (%q, %q), got (%q, %q)",
+				tt.path, tt.accept, tt.version, tt.routed, version, routed)
+		}
+	}
+
+	v.options.Strategy = VersionByHeader
+	v.options.Default = ""
+	if version, routed := v.resolve("/users", ""); version != "" || routed != "/users" {
+		t.Errorf("Expected unversioned request to pass through, got (%q, %q)", version, routed)
+	}
+}
+
+func TestApplicationVersionedAndUnversionedRoutes(t *testing.T) {
+	app := newTestApp(t)
+	app.ConfigureVersioning(VersioningOptions{Strategy: VersionByPath | VersionByHeader, Vendor: "myapi", Default: "v1"})
+	respond := func(ctx *Context) error {
+		return ctx.String(ctx.APIVersion() + " " + ctx.Route())
+	}
+	app.Version("v1").Get("/users", respond)
+	app.Version("v2").Get("/users", respond)
+	app.Get("/health", respond)
+
+	tests := []struct {
+		path, accept string
+		status       int
+		body         string
+	}{
+		{"/users", "", OKStatus, "v1 /v1/users"},
+		{"/users", "application/vnd.myapi.v2+json", OKStatus, "v2 /v2/users"},
+		{"/v2/users", "", OKStatus, "v2 /v2/users"},
+		{"/health", "", OKStatus, " /health"},
+		{"/health", "application/vnd.myapi.v1+json", OKStatus, " /health"},
+		{"/missing", "application/vnd.myapi.v1+json", NotFoundStatus, ""},
+	}
+	for _, tt := range tests {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(tt.path)
+		if tt.accept != "" {
+			fastCtx.Request.Header.Set("Accept", tt.accept)
+		}
+		app.handleRequest(fastCtx)
+
+		if fastCtx.Response.StatusCode() != tt.status {
+			t.Errorf("%s (Accept %q): expected status %d, got %d", tt.path, tt.accept, tt.status, fastCtx.Response.StatusCode())
+			continue
+		}
+		if tt.status == OKStatus && string(fastCtx.Response.Body()) != tt.body {
+			t.Errorf("%s (Accept %q): expected %q, got %q", tt.path, tt.accept, tt.body, fastCtx.Response.Body())
+		}
+	}
+}
+
+func TestRouterRoutes(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+
+	router.AddRoute("POST", "/users", handler)
+	router.AddRoute("GET", "/users/:id", handler)
+	router.AddRoute("GET", "/users", handler)
+
+	routes := router.Routes()
+	expected := []string{"GET /users", "POST /users", "GET /users/:id"}
+	if len(routes) != len(expected) {
+		t.Fatalf("Expected %d routes, got %d", len(expected), len(routes))
+	}
+	for i, route := range routes {
+		if got := route.Method + " " + route.Path; got != expected[i] {
+			t.Errorf("Expected route %d to be %q, got %q", i, expected[i], got)
+		}
+	}
+}
+
+func TestApplicationRoutes(t *testing.T) {
+	app := newTestApp(t)
+	handler := func(ctx *Context) error { return nil }
+
+	err := app.Routes([]Route{
+		{Method: "GET", Path: "/users", Handler: handler, Name: "users.list"},
+		{Method: "post", Path: "/users", Handler: handler, Name: "users.create"},
+	})
+	if err != nil {
+		t.Fatalf("Routes failed: %v", err)
+	}
+
+	if h, _ := app.router.FindHandler("POST", "/users"); h == nil {
+		t.Error("Expected POST /users to be registered")
+	}
+	routes := app.router.Routes()
+	if len(routes) != 2 || routes[0].Name != "users.list" {
+		t.Errorf("Expected named routes, got %+v", routes)
+	}
+
+	err = app.Routes([]Route{
+		{Method: "GET", Path: "/users", Handler: handler},
+		{Method: "GET", Path: "/items", Handler: handler, Name: "users.list"},
+		{Method: "FETCH", Path: "/items", Handler: handler},
+		{Method: "GET", Path: "/orders"},
+		{Method: "GET", Path: "/orders", Handler: handler},
+	})
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+	for _, expected := range []string{"duplicate route", "duplicate route name", "unsupported method", "handler is nil"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got: %v", expected, err)
+		}
+	}
+
+	if h, _ := app.router.FindHandler("GET", "/items"); h != nil {
+		t.Error("Expected no routes to be registered when validation fails")
+	}
+}
+
+func TestRouterWildcard(t *testing.T) {
+	router := NewRouter()
+
+	router.AddRoute("GET", "/static/*filepath", func(ctx *Context) error { return nil })
+	router.AddRoute("GET", "/static/*rest/invalid", func(ctx *Context) error { return nil })
+	router.AddRoute("GET", "/files/:name", func(ctx *Context) error { return nil })
+	router.AddRoute("GET", "/files/*path", func(ctx *Context) error { return nil })
+	router.AddRoute("GET", "/files/readme", func(ctx *Context) error { return nil })
+	router.AddRoute("GET", "/files/docs/*path", func(ctx *Context) error { return nil })
+
+	if router.HasRoute("GET", "/static/*rest/invalid") {
+		t.Error("Expected route with a non-final wildcard to be rejected")
+	}
+
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/static/css/app.css", "/static/*filepath", map[string]string{"filepath": "css/app.css"}},
+		{"/static/", "/static/*filepath", map[string]string{"filepath": ""}},
+		{"/files/readme", "/files/readme", nil},
+		{"/files/report.pdf", "/files/:name", map[string]string{"name": "report.pdf"}},
+		{"/files/a/b/c", "/files/*path", map[string]string{"path": "a/b/c"}},
+		{"/files/docs/guide/intro.md", "/files/docs/*path", map[string]string{"path": "guide/intro.md"}},
+	}
+
+	for _, tt := range tests {
+		handler, params, pattern := router.findRoute("GET", tt.path)
+		if handler == nil {
+			t.Errorf("%s: expected a match", tt.path)
+			continue
+		}
+		if pattern != tt.pattern {
+			t.Errorf("%s: expected pattern %s, got %s", tt.path, tt.pattern, pattern)
+		}
+		for key, value := range tt.params {
+			if params[key] != value {
+				t.Errorf("%s: expected %s=%q, got %q", tt.path, key, value, params[key])
+			}
+		}
+	}
+
+	if handler, _ := router.FindHandler("GET", "/other/file"); handler != nil {
+		t.Error("Expected no match outside the wildcard prefix")
+	}
+}
+
+func TestRouterPathNormalization(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+	router.AddRoute("GET", "/", handler)
+	router.AddRoute("GET", "/users", handler)
+	router.AddRoute("GET", "/users/:id", handler)
+	router.AddRoute("GET", "/users/:id/posts", handler)
+	router.AddRoute("GET", "/api//items", handler)
+
+	tests := []struct {
+		path    string
+		pattern string
+		id      string
+	}{
+		{"/", "/", ""},
+		{"//", "/", ""},
+		{"/users", "/users", ""},
+		{"//users", "/users", ""},
+		{"/users//42", "/users/:id", "42"},
+		{"/users/42//posts", "/users/:id/posts", "42"},
+		{"/api/items", "/api/items", ""},
+		// Trailing slashes are significant and parameters never match empty segments
+		{"/users/", "", ""},
+		{"/users/42/", "", ""},
+	}
+
+	for _, tt := range tests {
+		handler, params, pattern := router.findRoute("GET", tt.path)
+		if pattern != tt.pattern || (handler != nil) != (tt.pattern != "") {
+			t.Errorf("%s: expected pattern %q, got %q", tt.path, tt.pattern, pattern)
+			continue
+		}
+		if params["id"] != tt.id {
+			t.Errorf("%s: expected id %q, got %q", tt.path, tt.id, params["id"])
+		}
+	}
+
+	if !router.HasRoute("GET", "/api/items") {
+		t.Error("expected duplicate slashes in the route path to be collapsed")
+	}
+
+	raw := NewRouter()
+	raw.SetPathNormalization(false)
+	raw.AddRoute("GET", "/users/:id", handler)
+	raw.AddRoute("GET", "/proxy/*url", handler)
+
+	if h, _ := raw.FindHandler("GET", "/users//42"); h != nil {
+		t.Error("expected no match for an empty segment without normalization")
+	}
+	if _, params := raw.FindHandler("GET", "/proxy/http://example.com"); params["url"] != "http://example.com" {
+		t.Errorf("expected wildcard to keep duplicate slashes, got %q", params["url"])
+	}
+}
+
+func TestRouterMaxSegments(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+
+	deepest := strings.Repeat("/:p", MaxRouteSegments)
+	router.AddRoute("GET", deepest, handler)
+	router.AddRoute("GET", deepest+"/extra", handler)
+
+	if !router.HasRoute("GET", deepest) {
+		t.Errorf("expected a route with %d segments to be accepted", MaxRouteSegments)
+	}
+	if router.HasRoute("GET", deepest+"/extra") {
+		t.Errorf("expected a route with more than %d segments to be rejected", MaxRouteSegments)
+	}
+	if h, _ := router.FindHandler("GET", strings.Repeat("/x", MaxRouteSegments)); h == nil {
+		t.Error("expected the deepest route to match")
+	}
+	if h, _ := router.FindHandler("GET", strings.Repeat("/x", MaxRouteSegments+1)); h != nil {
+		t.Error("expected no match for a deeper request path")
+	}
+}
+
+func TestApplicationDisablePathNormalizing(t *testing.T) {
+	app := newTestApp(t)
+	app.config.Server.DisablePathNormalizing = true
+	app.router.SetPathNormalization(false)
+	app.Get("/proxy/*url", func(ctx *Context) error {
+		return ctx.String(ctx.Param("url"))
+	})
+
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Request.SetRequestURI("/proxy/https://example.com/a%20b")
+	app.handleRequest(fastCtx)
+
+	if body := string(fastCtx.Response.Body()); body != "https://example.com/a b" {
+		t.Errorf("expected the original decoded path, got %q", body)
+	}
+}
+
+func TestRouterPrecedence(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+	router.AddRoute("GET", "/users/:id", handler)
+	router.AddRoute("GET", "/users/me", handler)
+	router.AddRoute("GET", "/users/:name/posts", handler)
+	router.AddRoute("GET", "/users/me/*rest", handler)
+	router.AddRoute("GET", "/teams/:team/members/:id", handler)
+	router.AddRoute("GET", "/teams/:team/*rest", handler)
+	router.AddRoute("GET", "/*any", handler)
+
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/users/me", "/users/me", nil},
+		{"/users/42", "/users/:id", map[string]string{"id": "42"}},
+		// A route without wildcard wins over a wildcard with a longer static prefix
+		{"/users/me/posts", "/users/:name/posts", map[string]string{"name": "me"}},
+		{"/users/me/settings/theme", "/users/me/*rest", map[string]string{"rest": "settings/theme"}},
+		{"/teams/red/members/7", "/teams/:team/members/:id", map[string]string{"team": "red", "id": "7"}},
+		{"/teams/red/members", "/teams/:team/*rest", map[string]string{"team": "red", "rest": "members"}},
+		{"/other/path", "/*any", map[string]string{"any": "other/path"}},
+		{"/", "/*any", map[string]string{"any": ""}},
+	}
+
+	for _, tt := range tests {
+		handler, params, pattern := router.findRoute("GET", tt.path)
+		if handler == nil || pattern != tt.pattern {
+			t.Errorf("%s: expected pattern %s, got %q", tt.path, tt.pattern, pattern)
+			continue
+		}
+		if len(params) != len(tt.params) {
+			t.Errorf("%s: expected params %v, got %v", tt.path, tt.params, params)
+		}
+		for key, value := range tt.params {
+			if params[key] != value {
+				t.Errorf("%s: expected %s=%q, got %q", tt.path, key, value, params[key])
+			}
+		}
+	}
+
+	if handler, _, _ := router.findRoute("POST", "/users/me"); handler != nil {
+		t.Error("expected no match for another method")
+	}
+}
+
+func BenchmarkRouterFindHandler(b *testing.B) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+	for i := 0; i < 200; i++ {
+		router.AddRoute("GET", fmt.Sprintf("/resource%d/:id/items/:item", i), handler)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		router.FindHandler("GET", "/resource199/42/items/7")
+	}
+}
+
+func TestRouterPathCleaning(t *testing.T) {
+	router := NewRouter()
+	handler := func(ctx *Context) error { return nil }
+	router.AddRoute("GET", "/public", handler)
+	router.AddRoute("GET", "/admin", handler)
+	router.AddRoute("GET", "/users/:id", handler)
+	router.AddRoute("GET", "/static/*filepath", handler)
+	router.AddRoute("GET", "/docs/./guide/", handler)
+
+	tests := []struct {
+		path    string
+		pattern string
+		params  map[string]string
+	}{
+		{"/admin/../public", "/public", nil},
+		{"/public/../admin", "/admin", nil},
+		{"/users/./1", "/users/:id", map[string]string{"id": "1"}},
+		{"/users/2/.", "/users/:id", map[string]string{"id": "2"}},
+		{"/../../public", "/public", nil},
+		{"/static/../../../etc/passwd", "", nil},
+		{"/static/css/../../static/app.js", "/static/*filepath", map[string]string{"filepath": "app.js"}},
+		{"/static/a/./b/../c", "/static/*filepath", map[string]string{"filepath": "a/c"}},
+		{"/docs/guide/", "/docs/guide/", nil},
+		{"/docs/x/../guide/", "/docs/guide/", nil},
+	}
+
+	for _, tt := range tests {
+		handler, params, pattern := router.findRoute("GET", tt.path)
+		if pattern != tt.pattern || (handler != nil) != (tt.pattern != "") {
+			t.Errorf("%s: expected pattern %q, got %q", tt.path, tt.pattern, pattern)
+			continue
+		}
+		for key, value := range tt.params {
+			if params[key] != value {
+				t.Errorf("%s: expected %s=%q, got %q", tt.path, key, value, params[key])
+			}
+		}
+	}
+}
+
+func TestApplicationPathTraversal(t *testing.T) {
+	newApp := func() *Application {
+		app := newTestApp(t)
+		// Prefix-based authorization must see the cleaned path
+		app.Use(func(next HandlerFunc) HandlerFunc {
+			return func(ctx *Context) error {
+				if strings.HasPrefix(ctx.Path(), "/admin") {
+					return ctx.Error(ForbiddenStatus, "forbidden", "Admins only")
+				}
+				return next(ctx)
+			}
+		})
+		app.Get("/admin/*path", func(ctx *Context) error { return ctx.String("admin") })
+		app.Get("/public/*path", func(ctx *Context) error { return ctx.String("public " + ctx.Param("path")) })
+		return app
+	}
+	request := func(app *Application, uri string) *fasthttp.RequestCtx {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI(uri)
+		app.handleRequest(fastCtx)
+		return fastCtx
+	}
+
+	app := newApp()
+	for _, uri := range []string{"/public/../admin/users", "/public/%2e%2e/admin/users", "/public/./../admin/users"} {
+		if status := request(app, uri).Response.StatusCode(); status != ForbiddenStatus {
+			t.Errorf("%s: expected 403, got %d", uri, status)
+		}
+	}
+	if body := string(request(app, "/admin/../public/file").Response.Body()); body != "public file" {
+		t.Errorf("expected /admin/../public/file to route to /public, got %q", body)
+	}
+
+	raw := newApp()
+	raw.config.Server.DisablePathNormalizing = true
+	raw.router.SetPathNormalization(false)
+	for _, uri := range []string{"/public/../admin/users", "/public/%2e%2e/admin/users", "/public/./file"} {
+		if status := request(raw, uri).Response.StatusCode(); status != BadRequestStatus {
+			t.Errorf("raw %s: expected 400, got %d", uri, status)
+		}
+	}
+	if body := string(request(raw, "/public/a//b").Response.Body()); body != "public a//b" {
+		t.Errorf("expected raw path to keep duplicate slashes, got %q", body)
+	}
+}
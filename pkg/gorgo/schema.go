@@ -0,0 +1,149 @@
+package gorgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+)
+
+// ResponseSchema returns route middleware that checks JSON responses of the
+// route against schema, a value of the type the response must decode into.
+// It is a development aid: outside debug mode (app.debug) it does nothing.
+//
+//	app.Get("/users/:id", getUser, app.ResponseSchema(UserResponse{}))
+//	app.Get("/users", listUsers, app.ResponseSchema([]UserResponse{}))
+//
+// A successful JSON response doesn't match when it has fields the type
+// lacks, misses fields without omitempty, has values of the wrong type, or
+// fails the type's Validate method. Mismatches are logged; with
+// app.strict_response_schema the response is replaced by a 500 error.
+func (a *Application) ResponseSchema(schema interface{}) MiddlewareFunc {
+	if !a.config.App.Debug || schema == nil {
+		return func(next HandlerFunc) HandlerFunc { return next }
+	}
+
+	schemaType := reflect.TypeOf(schema)
+	for schemaType.Kind() == reflect.Pointer {
+		schemaType = schemaType.Elem()
+	}
+	strict := a.config.App.StrictResponseSchema
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			if err := next(ctx); err != nil {
+				return err
+			}
+
+			response := &ctx.fastCtx.Response
+			status := response.StatusCode()
+			if status < 200 || status >= 300 || response.IsBodyStream() ||
+				!strings.Contains(string(response.Header.ContentType()), "json") {
+				return nil
+			}
+
+			if err := checkResponseSchema(response.Body(), schemaType); err != nil {
+				log.Printf("Response schema mismatch: %s %s: %v", ctx.Method(), ctx.Route(), err)
+				if strict {
+					response.ResetBody()
+					return ctx.writeError(&HTTPError{
+						Status:  InternalServerErrorStatus,
+						Code:    "response_schema_mismatch",
+						Message: fmt.Sprintf("Response does not match %s: %v", schemaType, err),
+						Err:     err,
+					})
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// checkResponseSchema decodes body into a new value of schemaType and
+// checks it as described in ResponseSchema
+func checkResponseSchema(body []byte, schemaType reflect.Type) error {
+	target := reflect.New(schemaType)
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target.Interface()); err != nil {
+		return err
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return err
+	}
+
+	value := target.Elem()
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		items, _ := raw.([]interface{})
+		for i := 0; i < value.Len() && i < len(items); i++ {
+			if err := checkSchemaValue(value.Index(i), items[i]); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+	return checkSchemaValue(value, raw)
+}
+
+// checkSchemaValue checks that raw, the decoded JSON of value, has every
+// field of a struct without omitempty and that value passes Validate
+func checkSchemaValue(value reflect.Value, raw interface{}) error {
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if object, ok := raw.(map[string]interface{}); ok && value.Kind() == reflect.Struct {
+		var missing []string
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			name, required := jsonFieldName(field)
+			if !required {
+				continue
+			}
+			if _, exists := object[name]; !exists {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("missing fields: %s", strings.Join(missing, ", "))
+		}
+	}
+
+	if value.CanAddr() {
+		if validatable, ok := value.Addr().Interface().(Validatable); ok {
+			return validatable.Validate()
+		}
+	}
+	if validatable, ok := value.Interface().(Validatable); ok {
+		return validatable.Validate()
+	}
+	return nil
+}
+
+// jsonFieldName returns the JSON name of an exported field and whether it
+// must be present, i.e. is not tagged omitempty or "-". Embedded structs
+// are not checked.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" || option == "omitzero" {
+			return name, false
+		}
+	}
+	return name, true
+}
@@ -0,0 +1,70 @@
+package gorgo
+
+import (
+	"log"
+	"time"
+)
+
+// SLAExceeded is published when a request takes longer than the budget of
+// its SLAMiddleware
+type SLAExceeded struct {
+	Method  string        `event:"method"`
+	Path    string        `event:"path"`
+	Route   string        `event:"route"`
+	Budget  time.Duration `event:"budget"`
+	Elapsed time.Duration `event:"elapsed"`
+}
+
+func (SLAExceeded) EventName() string { return "request.sla_exceeded" }
+
+// slaBudgetKey stores the budget owning the current request in the context
+const slaBudgetKey = "gorgo.sla_budget"
+
+// slaBudget identifies one SLAMiddleware invocation
+type slaBudget struct {
+	budget time.Duration
+}
+
+// SLAMiddleware monitors a soft response time budget. Requests that take
+// longer are not canceled; the overrun is logged with the route pattern
+// and published as an SLAExceeded event.
+//
+// Use it globally and override the budget per route; the innermost budget
+// applies, so each request is reported at most once:
+//
+//	app.Use(gorgo.SLAMiddleware(200 * time.Millisecond))
+//	app.Get("/reports", buildReport, gorgo.SLAMiddleware(2*time.Second))
+func SLAMiddleware(budget time.Duration) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx *Context) error {
+			own := &slaBudget{budget: budget}
+			ctx.Set(slaBudgetKey, own)
+
+			err := next(ctx)
+
+			// A route-specific SLAMiddleware replaced this budget
+			if current, _ := ctx.Get(slaBudgetKey); current != own {
+				return err
+			}
+
+			if elapsed := ctx.Elapsed(); elapsed > budget {
+				route := ctx.Route()
+				if route == "" {
+					route = ctx.Path()
+				}
+				log.Printf("SLA exceeded: %s %s took %v (budget %v)", ctx.Method(), route, elapsed, budget)
+
+				if eventBus := ctx.eventBus(); eventBus != nil {
+					PublishTyped(ctx.Context(), eventBus, SLAExceeded{
+						Method:  ctx.Method(),
+						Path:    ctx.Path(),
+						Route:   ctx.Route(),
+						Budget:  budget,
+						Elapsed: elapsed,
+					})
+				}
+			}
+			return err
+		}
+	}
+}
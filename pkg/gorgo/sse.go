@@ -0,0 +1,168 @@
+package gorgo
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+)
+
+// SSEKeepAliveInterval is how often SSEStream sends a keep-alive comment
+// while no events arrive, so proxies don't close idle connections
+var SSEKeepAliveInterval = 15 * time.Second
+
+// SSEvent is a Server-Sent Event. Data is written as is when it is a
+// string or []byte and JSON encoded otherwise; multi-line data is split
+// into several data lines. ID, Event and Retry are optional.
+type SSEvent struct {
+	ID    string
+	Event string
+	Data  interface{}
+	// Retry tells the client how long to wait before reconnecting
+	Retry time.Duration
+}
+
+// SSEStream sends the events received from ch as a text/event-stream
+// response. It returns cleanly when ch is closed, ctx is done or the
+// client disconnects. A keep-alive comment is sent every
+// SSEKeepAliveInterval without events, which also notices clients that
+// have gone away.
+//
+// Like Stream, the events are written after the handler has returned, so
+// pass a context that outlives it, e.g. ctx.Context(), which is canceled
+// when the server shuts down. Stopping the producer that feeds ch is up to
+// the caller: a client disconnect doesn't cancel ctx, so a producer that
+// should end with the stream belongs in SSEStreamFunc. A reconnecting
+// client sends the ID of the last event it received, see LastEventID.
+//
+//	events := feed.Subscribe(ctx.LastEventID())
+//	return ctx.SSEStream(ctx.Context(), events)
+func (c *Context) SSEStream(ctx context.Context, ch <-chan SSEvent) error {
+	c.setSSEHeaders()
+	c.fastCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := writeSSE(ctx, flushWriter{w}, ch); err != nil {
+			log.Printf("SSE: stream ended: %v", err)
+		}
+	})
+	return nil
+}
+
+// SSEStreamFunc is SSEStream with a producer tied to the stream: produce
+// runs in its own goroutine with a context derived from ctx that is
+// canceled when the stream ends, including when the client disconnects,
+// so it must select on ctx.Done() when sending. Returning from produce
+// ends the stream.
+//
+//	lastID := ctx.LastEventID()
+//	return ctx.SSEStreamFunc(ctx.Context(), func(ctx context.Context, events chan<- gorgo.SSEvent) {
+//	    feed.Follow(ctx, lastID, events)
+//	})
+func (c *Context) SSEStreamFunc(ctx context.Context, produce func(ctx context.Context, events chan<- SSEvent)) error {
+	c.setSSEHeaders()
+	c.fastCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		events := make(chan SSEvent)
+		Go(func() {
+			defer close(events)
+			produce(streamCtx, events)
+		})
+		if err := writeSSE(streamCtx, flushWriter{w}, events); err != nil {
+			log.Printf("SSE: stream ended: %v", err)
+		}
+	})
+	return nil
+}
+
+func (c *Context) setSSEHeaders() {
+	c.fastCtx.Response.Header.SetContentType("text/event-stream")
+	c.fastCtx.Response.Header.Set("Cache-Control", "no-cache")
+	// Disable response buffering in nginx
+	c.fastCtx.Response.Header.Set("X-Accel-Buffering", "no")
+}
+
+// LastEventID returns the ID of the last event a reconnecting SSE client
+// received, from the Last-Event-ID header, or "" on the first connection
+func (c *Context) LastEventID() string {
+	return c.GetHeader("Last-Event-ID")
+}
+
+// writeSSE writes events until ch is closed, ctx is done or the client
+// has gone away, which shows as a write error and is not reported. Only
+// events that cannot be encoded return an error.
+func writeSSE(ctx context.Context, w io.Writer, ch <-chan SSEvent) error {
+	keepAlive := time.NewTicker(SSEKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	// Send the headers right away, before the first event
+	if _, err := io.WriteString(w, ": connected\n\n"); err != nil {
+		return nil
+	}
+
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			data, err := formatSSE(event)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return nil
+			}
+			keepAlive.Reset(SSEKeepAliveInterval)
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// formatSSE encodes an event in the text/event-stream format
+func formatSSE(event SSEvent) ([]byte, error) {
+	var data string
+	switch value := event.Data.(type) {
+	case nil:
+	case string:
+		data = value
+	case []byte:
+		data = string(value)
+	default:
+		encoded, err := GetJSONCodec().Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encoding event data: %w", err)
+		}
+		data = string(encoded)
+	}
+
+	var b strings.Builder
+	// Line breaks in the ID or event name would start new fields
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", stripLineBreaks(event.ID))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&b, "event: %s\n", stripLineBreaks(event.Event))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", event.Retry.Milliseconds())
+	}
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	return []byte(b.String()), nil
+}
+
+func stripLineBreaks(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,137 @@
+package gorgo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// staticPathKey is the fasthttp user value holding the file path to serve
+const staticPathKey = "gorgo.static_path"
+
+// Static serves the files below rootDir at urlPrefix, e.g.
+// app.Static("/assets", "./public") serves ./public/css/app.css at
+// /assets/css/app.css. Files are streamed with a content type derived from
+// the extension, a Last-Modified header and support for If-Modified-Since
+// and Range requests. A directory serves its index.html; missing files get
+// the not-found handler. Paths with ".." segments never reach the
+// filesystem.
+func (a *Application) Static(urlPrefix, rootDir string) {
+	path := strings.TrimSuffix(urlPrefix, "/") + "/*filepath"
+	handler := a.staticHandler(rootDir)
+	a.addRoute(Route{Method: "GET", Path: path, Handler: handler})
+	a.addRoute(Route{Method: "HEAD", Path: path, Handler: handler})
+}
+
+func (a *Application) staticHandler(rootDir string) HandlerFunc {
+	fs := &fasthttp.FS{
+		Root:            rootDir,
+		IndexNames:      []string{"index.html"},
+		AcceptByteRange: true,
+		PathRewrite: func(ctx *fasthttp.RequestCtx) []byte {
+			return []byte(ctx.UserValue(staticPathKey).(string))
+		},
+	}
+	serve := fs.NewRequestHandler()
+
+	return func(ctx *Context) error {
+		file := "/" + ctx.Param("filepath")
+		if hasDotSegment(file) || strings.ContainsAny(file, "\\\x00") {
+			return ctx.Error(BadRequestStatus, "invalid_path", "Request path must not contain . or .. segments")
+		}
+
+		info, err := os.Stat(filepath.Join(rootDir, filepath.FromSlash(file)))
+		if err != nil {
+			return a.notFound(ctx)
+		}
+		if info.IsDir() && !strings.HasSuffix(ctx.Path(), "/") {
+			return ctx.Redirect(ctx.Path()+"/", MovedPermanentlyStatus)
+		}
+
+		ctx.fastCtx.SetUserValue(staticPathKey, file)
+		serve(ctx.fastCtx)
+		return nil
+	}
+}
+
+// notFound responds with the application's not-found handler
+func (a *Application) notFound(ctx *Context) error {
+	if a.notFoundHandler != nil {
+		return a.notFoundHandler(ctx)
+	}
+	return defaultNotFoundHandler(ctx)
+}
+
+// SendFile streams the file at path as the response body without reading
+// it into memory. The content type follows the extension, or the content
+// when the extension is unknown, and Last-Modified is set. A missing file
+// or a directory returns a 404 *HTTPError wrapping ErrNotFound. Returned
+// from the handler, it responds 404 with an ErrorResponse, or through the
+// error handler if one is set.
+func (c *Context) SendFile(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fileNotFound(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if info.IsDir() {
+		file.Close()
+		return fileNotFound(path)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		var head [512]byte
+		n, _ := io.ReadFull(file, head[:])
+		contentType = http.DetectContentType(head[:n])
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	c.fastCtx.Response.Header.SetContentType(contentType)
+	c.fastCtx.Response.Header.Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	// FastHTTP closes the file once the body is sent
+	c.fastCtx.Response.SetBodyStream(file, int(info.Size()))
+	return nil
+}
+
+// Attachment sends the file at path like SendFile with a
+// Content-Disposition header that makes browsers download it as filename,
+// or as the base name of path when filename is empty
+func (c *Context) Attachment(path, filename string) error {
+	if filename == "" {
+		filename = filepath.Base(path)
+	}
+	if err := c.SendFile(path); err != nil {
+		return err
+	}
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+	if disposition == "" {
+		// Names FormatMediaType rejects fall back to a plain attachment
+		disposition = "attachment"
+	}
+	c.fastCtx.Response.Header.Set("Content-Disposition", disposition)
+	return nil
+}
+
+func fileNotFound(path string) error {
+	return &HTTPError{Status: NotFoundStatus, Code: "not_found", Message: "File not found", Err: fmt.Errorf("%w: %s", ErrNotFound, filepath.Base(path))}
+}
@@ -0,0 +1,205 @@
+package gorgo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/valyala/fasthttp"
+)
+
+func TestStatic(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "public")
+	writeFile := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("secret.txt", "secret")
+	writeFile("public/css/app.css", "body{}")
+	writeFile("public/docs/index.html", "<h1>Docs</h1>")
+	writeFile("public/empty/.keep", "")
+
+	app := newTestApp(t)
+	app.Static("/assets/", root)
+
+	request := func(method, uri string, headers map[string]string) *fasthttp.RequestCtx {
+		var req fasthttp.Request
+		req.Header.SetMethod(method)
+		req.SetRequestURI(uri)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		// Init sets up the logger the file server needs
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Init(&req, nil, nil)
+		app.handleRequest(fastCtx)
+		return fastCtx
+	}
+
+	fastCtx := request("GET", "/assets/css/app.css", nil)
+	if fastCtx.Response.StatusCode() != OKStatus || string(fastCtx.Response.Body()) != "body{}" {
+		t.Fatalf("expected the file, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+	if contentType := string(fastCtx.Response.Header.ContentType()); contentType != "text/css; charset=utf-8" {
+		t.Errorf("expected text/css, got %q", contentType)
+	}
+	lastModified := string(fastCtx.Response.Header.Peek("Last-Modified"))
+	if lastModified == "" {
+		t.Error("expected a Last-Modified header")
+	}
+
+	fastCtx = request("GET", "/assets/css/app.css", map[string]string{"If-Modified-Since": lastModified})
+	if fastCtx.Response.StatusCode() != NotModifiedStatus {
+		t.Errorf("expected 304 for an unchanged file, got %d", fastCtx.Response.StatusCode())
+	}
+	past := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC1123)
+	fastCtx = request("GET", "/assets/css/app.css", map[string]string{"If-Modified-Since": past})
+	if fastCtx.Response.StatusCode() != OKStatus {
+		t.Errorf("expected 200 for a modified file, got %d", fastCtx.Response.StatusCode())
+	}
+
+	fastCtx = request("HEAD", "/assets/css/app.css", nil)
+	if fastCtx.Response.StatusCode() != OKStatus || fastCtx.Response.Header.ContentLength() != len("body{}") {
+		t.Errorf("expected HEAD to report the file, got %d with length %d", fastCtx.Response.StatusCode(), fastCtx.Response.Header.ContentLength())
+	}
+
+	if body := string(request("GET", "/assets/docs/", nil).Response.Body()); body != "<h1>Docs</h1>" {
+		t.Errorf("expected the directory index, got %q", body)
+	}
+	fastCtx = request("GET", "/assets/docs", nil)
+	if fastCtx.Response.StatusCode() != MovedPermanentlyStatus || !strings.HasSuffix(string(fastCtx.Response.Header.Peek("Location")), "/assets/docs/") {
+		t.Errorf("expected a redirect to the directory, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Header.Peek("Location"))
+	}
+	if status := request("GET", "/assets/empty/", nil).Response.StatusCode(); status != ForbiddenStatus {
+		t.Errorf("expected 403 for a directory without index, got %d", status)
+	}
+
+	for _, uri := range []string{"/assets/missing.js", "/assets/../secret.txt", "/assets/css/../../secret.txt", "/assets/%2e%2e/secret.txt"} {
+		fastCtx := request("GET", uri, nil)
+		if fastCtx.Response.StatusCode() != NotFoundStatus {
+			t.Errorf("%s: expected 404, got %d %q", uri, fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+		}
+	}
+
+	app.NotFound(func(ctx *Context) error {
+		return ctx.Error(NotFoundStatus, "not_found", "No such asset")
+	})
+	if body := string(request("GET", "/assets/missing.js", nil).Response.Body()); !strings.Contains(body, "No such asset") {
+		t.Errorf("expected the custom not-found handler for missing files, got %q", body)
+	}
+}
+
+func TestContextSendFile(t *testing.T) {
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.csv")
+	blob := filepath.Join(dir, "blob")
+	if err := os.WriteFile(report, []byte("id,name\n1,gorgo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(blob, []byte("<html><body>hi</body></html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	newCtx := func() (*Context, *fasthttp.RequestCtx) {
+		fastCtx := &fasthttp.RequestCtx{}
+		return NewContext(fastCtx, container.NewContainer(), nil), fastCtx
+	}
+
+	ctx, fastCtx := newCtx()
+	if err := ctx.SendFile(report); err != nil {
+		t.Fatalf("SendFile failed: %v", err)
+	}
+	if !fastCtx.Response.IsBodyStream() {
+		t.Error("expected the file to be streamed")
+	}
+	if contentType := string(fastCtx.Response.Header.ContentType()); contentType != "text/csv; charset=utf-8" {
+		t.Errorf("expected text/csv, got %q", contentType)
+	}
+	if fastCtx.Response.Header.ContentLength() != 16 || len(fastCtx.Response.Header.Peek("Last-Modified")) == 0 {
+		t.Errorf("expected Content-Length and Last-Modified, got %q", fastCtx.Response.Header.String())
+	}
+	if body := string(fastCtx.Response.Body()); body != "id,name\n1,gorgo\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	ctx, fastCtx = newCtx()
+	if err := ctx.SendFile(blob); err != nil {
+		t.Fatalf("SendFile failed: %v", err)
+	}
+	if contentType := string(fastCtx.Response.Header.ContentType()); contentType != "text/html; charset=utf-8" {
+		t.Errorf("expected the content type to be detected, got %q", contentType)
+	}
+	if body := string(fastCtx.Response.Body()); body != "<html><body>hi</body></html>" {
+		t.Errorf("expected the whole file after sniffing, got %q", body)
+	}
+
+	ctx, fastCtx = newCtx()
+	if err := ctx.Attachment(report, "Отчёт 2024.csv"); err != nil {
+		t.Fatalf("Attachment failed: %v", err)
+	}
+	if disposition := string(fastCtx.Response.Header.Peek("Content-Disposition")); disposition != "attachment; filename*=utf-8''%D0%9E%D1%82%D1%87%D1%91%D1%82%202024.csv" {
+		t.Errorf("unexpected Content-Disposition %q", disposition)
+	}
+	ctx, fastCtx = newCtx()
+	ctx.Attachment(report, "")
+	if disposition := string(fastCtx.Response.Header.Peek("Content-Disposition")); disposition != "attachment; filename=report.csv" {
+		t.Errorf("expected the file name by default, got %q", disposition)
+	}
+
+	for _, path := range []string{filepath.Join(dir, "missing.csv"), dir} {
+		ctx, fastCtx = newCtx()
+		err := ctx.Attachment(path, "")
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.Status != NotFoundStatus || !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: expected a 404 HTTPError wrapping ErrNotFound, got %v", path, err)
+		}
+		if len(fastCtx.Response.Header.Peek("Content-Disposition")) != 0 {
+			t.Errorf("%s: expected no Content-Disposition for a missing file", path)
+		}
+	}
+}
+
+func TestSendFileMissingThroughApplication(t *testing.T) {
+	app := newTestApp(t)
+	missing := filepath.Join(t.TempDir(), "missing.csv")
+	app.Get("/report", func(ctx *Context) error {
+		return ctx.SendFile(missing)
+	})
+
+	request := func() *fasthttp.RequestCtx {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI("/report")
+		app.handleRequest(fastCtx)
+		return fastCtx
+	}
+
+	fastCtx := request()
+	if fastCtx.Response.StatusCode() != NotFoundStatus || !strings.Contains(string(fastCtx.Response.Body()), `"not_found"`) {
+		t.Errorf("expected a 404 ErrorResponse by default, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+
+	app.SetErrorFormat(ErrorFormatProblemJSON)
+	if fastCtx := request(); fastCtx.Response.StatusCode() != NotFoundStatus {
+		t.Errorf("expected 404 with problem+json, got %d", fastCtx.Response.StatusCode())
+	}
+
+	app.ErrorHandler(func(ctx *Context, err error) {
+		if errors.Is(err, ErrNotFound) {
+			ctx.Status(NotFoundStatus).String("no such report")
+		}
+	})
+	if fastCtx := request(); fastCtx.Response.StatusCode() != NotFoundStatus || string(fastCtx.Response.Body()) != "no such report" {
+		t.Errorf("expected the error handler response, got %d %q", fastCtx.Response.StatusCode(), fastCtx.Response.Body())
+	}
+}
@@ -0,0 +1,57 @@
+package gorgo
+
+import (
+	"bufio"
+	"io"
+	"log"
+)
+
+// Stream sends the response body as it is written by fn, without
+// buffering it in memory, e.g. CSV or NDJSON rows read from a database
+// cursor. Each write is flushed to the client as a chunk. Set the status
+// and headers, including the content type, before calling Stream.
+//
+// fn runs after the handler has returned, so it must not use ctx; copy
+// the values it needs first. The status has been sent by then, so an
+// error from fn is logged and ends the response early. A write error means
+// the client has gone away and fn should return.
+//
+//	ctx.Header("Content-Type", "text/csv")
+//	return ctx.Stream(func(w io.Writer) error {
+//	    out := csv.NewWriter(w)
+//	    for rows.Next() {
+//	        ...
+//	    }
+//	    out.Flush()
+//	    return out.Error()
+//	})
+func (c *Context) Stream(fn func(w io.Writer) error) error {
+	c.fastCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := fn(flushWriter{w}); err != nil {
+			log.Printf("Stream: response ended early: %v", err)
+			return
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+// SetBodyStreamWriter sets a FastHTTP body stream writer for full control
+// over buffering. As with Stream, fn runs after the handler has returned.
+func (c *Context) SetBodyStreamWriter(fn func(w *bufio.Writer)) *Context {
+	c.fastCtx.SetBodyStreamWriter(fn)
+	return c
+}
+
+// flushWriter flushes after every write
+type flushWriter struct {
+	w *bufio.Writer
+}
+
+func (f flushWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, f.w.Flush()
+}
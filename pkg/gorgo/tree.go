@@ -0,0 +1,143 @@
+package gorgo
+
+import "strings"
+
+// routeNode is a node of the routing tree. Each node stands for one path
+// segment; a route is stored on the node of its last segment, a wildcard
+// route on the node of the segment before the wildcard.
+type routeNode struct {
+	static   map[string]*routeNode
+	param    *routeNode
+	route    *treeRoute
+	wildcard *treeRoute
+}
+
+// treeRoute is a route stored in the tree. params holds the parameter
+// names in path order, the wildcard name last.
+type treeRoute struct {
+	handler HandlerFunc
+	pattern string
+	params  []string
+}
+
+// wildcardMatch is the best wildcard route found while searching
+type wildcardMatch struct {
+	route  *treeRoute
+	values []string
+}
+
+func newRouteNode() *routeNode {
+	return &routeNode{static: make(map[string]*routeNode)}
+}
+
+// insert adds or replaces the route for pattern, which has been validated
+func (n *routeNode) insert(pattern string, handler HandlerFunc) {
+	route := &treeRoute{handler: handler, pattern: pattern}
+
+	current := n
+	rest, more := pattern, true
+	for more {
+		var segment string
+		segment, rest, more = nextSegment(rest)
+
+		switch {
+		case strings.HasPrefix(segment, "*"):
+			route.params = append(route.params, segment[1:])
+			current.wildcard = route
+			return
+		case strings.HasPrefix(segment, ":"):
+			route.params = append(route.params, segment[1:])
+			if current.param == nil {
+				current.param = newRouteNode()
+			}
+			current = current.param
+		default:
+			child, exists := current.static[segment]
+			if !exists {
+				child = newRouteNode()
+				current.static[segment] = child
+			}
+			current = child
+		}
+	}
+	current.route = route
+}
+
+// lookup finds the route for path. Static segments take precedence over
+// ":param" segments, and any route without a wildcard over wildcard routes;
+// among wildcards the one with the longest pattern wins.
+func (n *routeNode) lookup(path string) (HandlerFunc, map[string]string, string) {
+	var best wildcardMatch
+	if route, values := n.find(path, true, nil, &best); route != nil {
+		return route.handler, route.paramMap(values), route.pattern
+	}
+	if best.route != nil {
+		return best.route.handler, best.route.paramMap(best.values), best.route.pattern
+	}
+	return nil, nil, ""
+}
+
+// find matches the remaining path below n. more reports whether a segment
+// is left to match, since an empty path is itself an empty segment.
+func (n *routeNode) find(path string, more bool, values []string, best *wildcardMatch) (*treeRoute, []string) {
+	if n.wildcard != nil {
+		remainder := ""
+		if more {
+			remainder = path
+		}
+		best.consider(n.wildcard, values, remainder)
+	}
+
+	if !more {
+		if n.route != nil {
+			return n.route, values
+		}
+		return nil, nil
+	}
+
+	segment, rest, hasMore := nextSegment(path)
+	if child, exists := n.static[segment]; exists {
+		if route, found := child.find(rest, hasMore, values, best); route != nil {
+			return route, found
+		}
+	}
+	if n.param != nil && segment != "" {
+		if route, found := n.param.find(rest, hasMore, append(values, segment), best); route != nil {
+			return route, found
+		}
+	}
+	return nil, nil
+}
+
+// consider records route as the best wildcard match if its pattern is
+// longer than the current one
+func (m *wildcardMatch) consider(route *treeRoute, values []string, remainder string) {
+	if m.route != nil && (len(route.pattern) < len(m.route.pattern) ||
+		(len(route.pattern) == len(m.route.pattern) && route.pattern >= m.route.pattern)) {
+		return
+	}
+	m.route = route
+	m.values = append(append(make([]string, 0, len(values)+1), values...), remainder)
+}
+
+// paramMap pairs the parameter names with the matched values, nil for
+// routes without parameters
+func (r *treeRoute) paramMap(values []string) map[string]string {
+	if len(r.params) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(r.params))
+	for i, name := range r.params {
+		params[name] = values[i]
+	}
+	return params
+}
+
+// nextSegment splits off the first segment of path. "/users" gives the
+// segments "" and "users", matching strings.Split.
+func nextSegment(path string) (segment, rest string, more bool) {
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[:i], path[i+1:], true
+	}
+	return path, "", false
+}
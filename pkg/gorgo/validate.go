@@ -0,0 +1,332 @@
+package gorgo
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidateStruct checks the `validate` struct tags of v, a struct or a
+// pointer to one, and returns ValidationErrors keyed by the JSON field
+// name, or nil when every field is valid. Supported rules:
+//
+//	required   the field is not the zero value (pointers are not nil)
+//	min=N      strings of at least N characters, numbers >= N, at least N items
+//	max=N      strings of at most N characters, numbers <= N, at most N items
+//	email      a plain email address
+//
+// Rules are separated by commas, e.g. `validate:"required,email"`. Empty
+// optional strings, slices and maps skip the other rules, but numbers are
+// always checked, as 0 is a value like any other; use a pointer to make a
+// number optional. Nested structs are validated with keys such as
+// "address.city".
+//
+// The tags of the whole type are checked before any value, so an unknown
+// rule or a bad argument is reported as a *ValidationTagError even when
+// the field is empty.
+func ValidateStruct(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return errors.New("validate: nil value")
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: expected a struct, got %T", v)
+	}
+
+	fields, err := validationRules(value.Type())
+	if err != nil {
+		return err
+	}
+	violations := ValidationErrors{}
+	validateFields(value, fields, "", violations)
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+// ValidationTagError reports a malformed `validate` tag, such as an
+// unknown rule or a non-numeric min value. It is a bug in the struct, not
+// in the request, so handlers should answer 500 rather than 400.
+type ValidationTagError struct {
+	Type  reflect.Type
+	Field string
+	Err   error
+}
+
+func (e *ValidationTagError) Error() string {
+	return fmt.Sprintf("validate: %s.%s: %v", e.Type, e.Field, e.Err)
+}
+
+func (e *ValidationTagError) Unwrap() error {
+	return e.Err
+}
+
+// fieldRules are the parsed `validate` rules of a struct field
+type fieldRules struct {
+	index int
+	// name is the JSON name, empty for embedded structs
+	name     string
+	required bool
+	rules    []rule
+	// nested is set for struct fields whose own fields are validated
+	nested bool
+}
+
+type rule struct {
+	name  string
+	arg   string
+	limit float64
+}
+
+// ruleCache maps struct types to their parsed []fieldRules
+var ruleCache sync.Map
+
+// validationRules returns the parsed rules of a struct type. The type and
+// the structs it nests are cached together, once all of their tags parse.
+func validationRules(t reflect.Type) ([]fieldRules, error) {
+	if fields, ok := ruleCache.Load(t); ok {
+		return fields.([]fieldRules), nil
+	}
+	parsed := map[reflect.Type][]fieldRules{}
+	if err := parseRules(t, parsed); err != nil {
+		return nil, err
+	}
+	for structType, fields := range parsed {
+		ruleCache.Store(structType, fields)
+	}
+	return parsed[t], nil
+}
+
+// parseRules parses the tags of t and of the structs it nests into parsed
+func parseRules(t reflect.Type, parsed map[reflect.Type][]fieldRules) error {
+	if _, ok := parsed[t]; ok {
+		return nil
+	}
+	if fields, ok := ruleCache.Load(t); ok {
+		parsed[t] = fields.([]fieldRules)
+		return nil
+	}
+	fields := []fieldRules{}
+	parsed[t] = fields
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _ := jsonFieldName(field)
+		if field.Anonymous {
+			name = ""
+		} else if name == "" {
+			continue
+		}
+
+		rules := fieldRules{index: i, name: name}
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			if err := rules.parse(field.Type, tag); err != nil {
+				return &ValidationTagError{Type: t, Field: field.Name, Err: err}
+			}
+		}
+
+		nested := field.Type
+		if nested.Kind() == reflect.Pointer {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.PkgPath() != "time" {
+			rules.nested = true
+			if err := parseRules(nested, parsed); err != nil {
+				return err
+			}
+		}
+		fields = append(fields, rules)
+	}
+	parsed[t] = fields
+	return nil
+}
+
+// parse parses a `validate` tag for a field of type t
+func (f *fieldRules) parse(t reflect.Type, tag string) error {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for _, part := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "":
+		case "required":
+			f.required = true
+		case "email":
+			if t.Kind() != reflect.String {
+				return fmt.Errorf("email rule on %s", t)
+			}
+			f.rules = append(f.rules, rule{name: name})
+		case "min", "max":
+			limit, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s value %q", name, arg)
+			}
+			if !isNumber(t.Kind()) && !hasLength(t.Kind()) {
+				return fmt.Errorf("%s rule on %s", name, t)
+			}
+			f.rules = append(f.rules, rule{name: name, arg: arg, limit: limit})
+		default:
+			return fmt.Errorf("unknown validation rule %q", name)
+		}
+	}
+	return nil
+}
+
+func validateFields(value reflect.Value, fields []fieldRules, prefix string, violations ValidationErrors) {
+	for _, field := range fields {
+		key := field.name
+		if prefix != "" && field.name != "" {
+			key = prefix + "." + field.name
+		} else if field.name == "" {
+			key = prefix
+		}
+
+		fieldValue := value.Field(field.index)
+		if message := field.check(fieldValue); message != "" {
+			violations[key] = message
+			continue
+		}
+
+		if nested := reflect.Indirect(fieldValue); field.nested && nested.IsValid() {
+			nestedFields, _ := ruleCache.Load(nested.Type())
+			validateFields(nested, nestedFields.([]fieldRules), key, violations)
+		}
+	}
+}
+
+// check returns the message of the first failing rule
+func (f fieldRules) check(value reflect.Value) string {
+	if value.IsZero() {
+		if f.required {
+			return "is required"
+		}
+		if !isNumber(value.Kind()) {
+			return ""
+		}
+	}
+	value = reflect.Indirect(value)
+
+	for _, r := range f.rules {
+		switch r.name {
+		case "email":
+			if !isEmail(value.String()) {
+				return "must be a valid email address"
+			}
+		case "min", "max":
+			if message := checkBound(value, r); message != "" {
+				return message
+			}
+		}
+	}
+	return ""
+}
+
+// checkBound checks a min or max rule against the length or value
+func checkBound(value reflect.Value, r rule) string {
+	var actual float64
+	var unit string
+	switch value.Kind() {
+	case reflect.String:
+		actual, unit = float64(utf8.RuneCountInString(value.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		actual, unit = float64(value.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	}
+
+	switch {
+	case r.name == "min" && actual < r.limit && unit != "":
+		return fmt.Sprintf("must have at least %s%s", r.arg, unit)
+	case r.name == "min" && actual < r.limit:
+		return "must be at least " + r.arg
+	case r.name == "max" && actual > r.limit && unit != "":
+		return fmt.Sprintf("must have at most %s%s", r.arg, unit)
+	case r.name == "max" && actual > r.limit:
+		return "must be at most " + r.arg
+	}
+	return ""
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func hasLength(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// isEmail reports whether s is a plain address such as "ann@example.com"
+func isEmail(s string) bool {
+	address, err := mail.ParseAddress(s)
+	return err == nil && address.Address == s && address.Name == ""
+}
+
+// BindAndValidate decodes the body like Bind (JSON when no Content-Type is
+// sent), then checks the `validate` tags and, if v implements Validatable,
+// its Validate method. Validation failures are returned as ValidationErrors,
+// ready for a 422 response. A malformed tag is a *ValidationTagError:
+//
+//	var req CreateUserRequest
+//	if err := ctx.BindAndValidate(&req); err != nil {
+//	    var fields gorgo.ValidationErrors
+//	    var tagErr *gorgo.ValidationTagError
+//	    switch {
+//	    case errors.As(err, &fields):
+//	        return ctx.Status(422).JSON(gorgo.Map{"errors": fields})
+//	    case errors.As(err, &tagErr):
+//	        return err // 500
+//	    }
+//	    return ctx.Error(400, "invalid_body", err.Error())
+//	}
+func (c *Context) BindAndValidate(v interface{}) error {
+	var err error
+	if len(c.fastCtx.Request.Header.ContentType()) == 0 {
+		err = c.BindJSON(v)
+	} else {
+		err = c.Bind(v)
+	}
+	if err != nil {
+		return err
+	}
+	return validateValue(v)
+}
+
+// validateValue runs the tag rules and then Validatable
+func validateValue(v interface{}) error {
+	if reflect.Indirect(reflect.ValueOf(v)).Kind() == reflect.Struct {
+		if err := ValidateStruct(v); err != nil {
+			return err
+		}
+	}
+	if validatable, ok := v.(Validatable); ok {
+		return validatable.Validate()
+	}
+	return nil
+}
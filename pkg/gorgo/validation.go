@@ -0,0 +1,189 @@
+package gorgo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QueryParamType defines the expected type of a query parameter
+type QueryParamType int
+
+const (
+	QueryString QueryParamType = iota
+	QueryInt
+	QueryFloat
+	QueryBool
+)
+
+func (t QueryParamType) String() string {
+	switch t {
+	case QueryInt:
+		return "integer"
+	case QueryFloat:
+		return "number"
+	case QueryBool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// QueryParamRule describes a single query parameter
+type QueryParamRule struct {
+	Type     QueryParamType
+	Required bool
+	// Allowed restricts the parameter to a set of values
+	Allowed []string
+	// Default is applied to optional parameters that are absent
+	Default string
+}
+
+// QuerySchema maps query parameter names to their rules
+type QuerySchema map[string]QueryParamRule
+
+// Validate checks the query parameters of the request against the schema,
+// applying defaults for absent optional parameters. It returns a map of
+// parameter name to violation message, or nil if the query is valid.
+func (s QuerySchema) Validate(ctx *Context) map[string]interface{} {
+	var violations map[string]interface{}
+	args := ctx.fastCtx.QueryArgs()
+
+	for name, rule := range s {
+		if !args.Has(name) {
+			if rule.Required {
+				violations = addViolation(violations, name, "is required")
+			} else if rule.Default != "" {
+				args.Set(name, rule.Default)
+			}
+			continue
+		}
+
+		value := string(args.Peek(name))
+		if msg := rule.check(value); msg != "" {
+			violations = addViolation(violations, name, msg)
+		}
+	}
+
+	return violations
+}
+
+func (r QueryParamRule) check(value string) string {
+	var err error
+	switch r.Type {
+	case QueryInt:
+		_, err = strconv.Atoi(value)
+	case QueryFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case QueryBool:
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return fmt.Sprintf("must be a valid %s", r.Type)
+	}
+
+	if len(r.Allowed) > 0 {
+		for _, allowed := range r.Allowed {
+			if value == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of: %s", strings.Join(r.Allowed, ", "))
+	}
+
+	return ""
+}
+
+func addViolation(violations map[string]interface{}, name, message string) map[string]interface{} {
+	if violations == nil {
+		violations = make(map[string]interface{})
+	}
+	violations[name] = message
+	return violations
+}
+
+// Validatable is implemented by request types that can check themselves
+// after being bound
+type Validatable interface {
+	Validate() error
+}
+
+// ValidationErrors maps field names to validation messages. Returning it
+// from Validate reports the messages in the details of the error response,
+// keyed by field name like query violations.
+type ValidationErrors map[string]string
+
+func (e ValidationErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields {
+		messages = append(messages, field+": "+e[field])
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// BindJSONValidated decodes the JSON body into v and validates it with its
+// `validate` tags (see ValidateStruct) and, if v implements Validatable,
+// its Validate method. On failure it writes an error response (400 for a
+// malformed body, 413 for an oversized one, 422 for validation errors, 500
+// for a malformed `validate` tag) and returns false, so handlers can
+// simply return:
+//
+//	var req CreateUserRequest
+//	if !ctx.BindJSONValidated(&req) {
+//	    return nil
+//	}
+//
+// The response format can be changed with app.ErrorHandler.
+func (c *Context) BindJSONValidated(v interface{}) bool {
+	body, err := c.readBody()
+	if err != nil {
+		c.writeError(bodyReadError(err))
+		return false
+	}
+
+	if err := GetJSONCodec().Unmarshal(body, v); err != nil {
+		c.writeError(&HTTPError{Status: BadRequestStatus, Code: "invalid_json", Message: "Invalid JSON body", Err: err})
+		return false
+	}
+
+	if err := validateValue(v); err != nil {
+		var tagErr *ValidationTagError
+		if errors.As(err, &tagErr) {
+			c.writeError(err)
+			return false
+		}
+
+		httpErr := &HTTPError{Status: UnprocessableEntityStatus, Code: "validation_failed", Message: "Validation failed", Err: err}
+
+		var fields ValidationErrors
+		if errors.As(err, &fields) {
+			httpErr.Details = make(map[string]interface{}, len(fields))
+			for field, message := range fields {
+				httpErr.Details[field] = message
+			}
+		} else {
+			httpErr.Message = err.Error()
+		}
+
+		c.writeError(httpErr)
+		return false
+	}
+	return true
+}
+
+// bodyReadError converts a readBody error into 413 for an oversized body
+// and 400 otherwise
+func bodyReadError(err error) *HTTPError {
+	if errors.Is(err, ErrBodyTooLarge) {
+		return &HTTPError{Status: PayloadTooLargeStatus, Code: "body_too_large", Message: "Request body too large", Err: err}
+	}
+	return &HTTPError{Status: BadRequestStatus, Code: "invalid_body", Message: "Failed to read request body", Err: err}
+}
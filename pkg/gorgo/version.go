@@ -0,0 +1,130 @@
+package gorgo
+
+import (
+	"strings"
+)
+
+// VersionStrategy selects how the API version of a request is resolved
+type VersionStrategy int
+
+const (
+	// VersionByPath matches a version path prefix, e.g. /v1/users
+	VersionByPath VersionStrategy = 1 << iota
+	// VersionByHeader matches a vendor media type in the Accept header,
+	// e.g. Accept: application/vnd.myapi.v1+json
+	VersionByHeader
+)
+
+// VersioningOptions configures version groups created with Application.Version
+type VersioningOptions struct {
+	// Strategy is a combination of VersionByPath and VersionByHeader.
+	// The path prefix wins when both are present.
+	Strategy VersionStrategy
+	// Vendor is the media type vendor, "myapi" in application/vnd.myapi.v1+json
+	Vendor string
+	// Default is the version used when the client specifies none
+	Default string
+}
+
+func DefaultVersioningOptions() VersioningOptions {
+	return VersioningOptions{
+		Strategy: VersionByPath | VersionByHeader,
+	}
+}
+
+type versioning struct {
+	options  VersioningOptions
+	versions map[string]bool
+}
+
+func newVersioning() *versioning {
+	return &versioning{
+		options:  DefaultVersioningOptions(),
+		versions: make(map[string]bool),
+	}
+}
+
+// resolve returns the requested API version and the path used for route
+// lookup. Version groups are registered under /<version>, so header and
+// default negotiated requests are routed by prefixing the version. The
+// router falls back to the original path when no versioned route matches.
+func (v *versioning) resolve(path, accept string) (string, string) {
+	if len(v.versions) == 0 {
+		return "", path
+	}
+
+	if v.options.Strategy&VersionByPath != 0 {
+		segment := strings.TrimPrefix(path, "/")
+		if i := strings.IndexByte(segment, '/'); i >= 0 {
+			segment = segment[:i]
+		}
+		if v.versions[segment] {
+			return segment, path
+		}
+	}
+
+	version := ""
+	if v.options.Strategy&VersionByHeader != 0 {
+		version = v.fromAccept(accept)
+	}
+	if version == "" {
+		version = v.options.Default
+	}
+	if version == "" || !v.versions[version] {
+		return "", path
+	}
+
+	return version, "/" + version + path
+}
+
+// fromAccept extracts a known version from application/vnd.<vendor>.<version>+json
+func (v *versioning) fromAccept(accept string) string {
+	prefix := "application/vnd."
+	if v.options.Vendor != "" {
+		prefix += v.options.Vendor + "."
+	}
+
+	for _, mediaType := range strings.Split(accept, ",") {
+		mediaType = strings.TrimSpace(mediaType)
+		if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+			mediaType = strings.TrimSpace(mediaType[:i])
+		}
+		if !strings.HasPrefix(mediaType, prefix) {
+			continue
+		}
+
+		version := strings.TrimPrefix(mediaType, prefix)
+		if i := strings.IndexByte(version, '+'); i >= 0 {
+			version = version[:i]
+		}
+		// Without a configured vendor the version is the last dotted part
+		if i := strings.LastIndexByte(version, '.'); i >= 0 {
+			version = version[i+1:]
+		}
+		if v.versions[version] {
+			return version
+		}
+	}
+
+	return ""
+}
+
+// ConfigureVersioning sets how version groups are negotiated
+func (a *Application) ConfigureVersioning(options VersioningOptions) *Application {
+	a.versioning.options = options
+	return a
+}
+
+// Version returns a route group for an API version. Routes are reachable
+// through the /<version> path prefix or the Accept header, depending on
+// the configured VersionStrategy.
+func (a *Application) Version(version string, middleware ...MiddlewareFunc) *RouteGroup {
+	version = strings.Trim(version, "/")
+	a.versioning.versions[version] = true
+	return a.Group("/"+version, middleware...)
+}
+
+// APIVersion returns the API version resolved for the request, if any
+func (c *Context) APIVersion() string {
+	return c.apiVersion
+}
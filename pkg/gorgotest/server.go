@@ -0,0 +1,177 @@
+// Package gorgotest runs a Gorgo application on a real network port for
+// integration tests that need HTTP semantics an in-memory request can't
+// give: TLS, timeouts, keep-alive and connection handling.
+package gorgotest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+)
+
+// StartTimeout bounds how long NewServer waits for plugins to start
+var StartTimeout = 10 * time.Second
+
+// Server is an application serving on 127.0.0.1 with an ephemeral port
+type Server struct {
+	// URL is the base URL, e.g. "http://127.0.0.1:49152"
+	URL string
+	// Addr is the listen address, e.g. "127.0.0.1:49152"
+	Addr string
+	// Client is an HTTP client for the server. For TLS servers it trusts
+	// the test certificate.
+	Client *http.Client
+	// Certificate is the self-signed certificate of TLS servers
+	Certificate *x509.Certificate
+
+	app          *gorgo.Application
+	done         chan error
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+// NewServer starts app on an ephemeral port and returns once the plugins
+// have started. The server is shut down when the test ends, stopping the
+// plugins and closing the listener.
+//
+//	srv := gorgotest.NewServer(t, app)
+//	resp, err := srv.Client.Get(srv.URL + "/users")
+func NewServer(t testing.TB, app *gorgo.Application) *Server {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("gorgotest: failed to listen: %v", err)
+	}
+
+	s := &Server{
+		URL:    "http://" + ln.Addr().String(),
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+	s.start(t, app, ln)
+	return s
+}
+
+// NewTLSServer starts app like NewServer, serving HTTPS with a self-signed
+// certificate for 127.0.0.1
+func NewTLSServer(t testing.TB, app *gorgo.Application) *Server {
+	t.Helper()
+
+	cert, err := selfSignedCertificate()
+	if err != nil {
+		t.Fatalf("gorgotest: failed to create certificate: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("gorgotest: failed to listen: %v", err)
+	}
+	ln = tls.NewListener(ln, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.Leaf)
+
+	s := &Server{
+		URL: "https://" + ln.Addr().String(),
+		Client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}},
+		},
+		Certificate: cert.Leaf,
+	}
+	s.start(t, app, ln)
+	return s
+}
+
+func (s *Server) start(t testing.TB, app *gorgo.Application, ln net.Listener) {
+	t.Helper()
+
+	s.Addr = ln.Addr().String()
+	s.app = app
+	s.done = make(chan error, 1)
+
+	started := make(chan struct{})
+	var once sync.Once
+	app.GetEventBus().Subscribe("server.started", func(event *gorgo.Event) error {
+		once.Do(func() { close(started) })
+		return nil
+	})
+
+	go func() { s.done <- app.Serve(context.Background(), ln) }()
+
+	select {
+	case <-started:
+	case err := <-s.done:
+		t.Fatalf("gorgotest: application failed to start: %v", err)
+	case <-time.After(StartTimeout):
+		s.Shutdown()
+		t.Fatalf("gorgotest: application did not start within %v", StartTimeout)
+	}
+
+	t.Cleanup(func() {
+		if err := s.Shutdown(); err != nil {
+			t.Errorf("gorgotest: shutdown failed: %v", err)
+		}
+	})
+}
+
+// Shutdown stops the application and waits until it has stopped. It is
+// safe to call more than once.
+func (s *Server) Shutdown() error {
+	s.shutdownOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), StartTimeout)
+		defer cancel()
+
+		if err := s.app.Shutdown(ctx); err != nil {
+			s.shutdownErr = err
+			return
+		}
+		s.shutdownErr = <-s.done
+		if transport, ok := s.Client.Transport.(*http.Transport); ok {
+			transport.CloseIdleConnections()
+		}
+	})
+	return s.shutdownErr
+}
+
+// selfSignedCertificate creates a certificate for 127.0.0.1 and localhost
+func selfSignedCertificate() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gorgotest"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		DNSNames:     []string{"localhost"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
@@ -0,0 +1,100 @@
+package gorgotest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+)
+
+type lifecyclePlugin struct {
+	gorgo.BasePlugin
+	started atomic.Bool
+	stopped atomic.Bool
+}
+
+func (p *lifecyclePlugin) Start(ctx context.Context) error {
+	p.started.Store(true)
+	return p.BasePlugin.Start(ctx)
+}
+
+func (p *lifecyclePlugin) Stop(ctx context.Context) error {
+	p.stopped.Store(true)
+	return p.BasePlugin.Stop(ctx)
+}
+
+func newTestApp() (*gorgo.Application, *lifecyclePlugin) {
+	plugin := &lifecyclePlugin{BasePlugin: gorgo.NewBasePlugin(gorgo.PluginMetadata{Name: "lifecycle", Version: "1.0.0"})}
+
+	app := gorgo.New()
+	app.AddPlugin(plugin)
+	app.Get("/ping", func(ctx *gorgo.Context) error {
+		return ctx.String("pong")
+	})
+	return app, plugin
+}
+
+func get(t *testing.T, srv *Server, path string) string {
+	t.Helper()
+
+	resp, err := srv.Client.Get(srv.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: expected 200, got %d", path, resp.StatusCode)
+	}
+	return string(body)
+}
+
+func TestServer(t *testing.T) {
+	app, plugin := newTestApp()
+	srv := NewServer(t, app)
+
+	if !plugin.started.Load() {
+		t.Error("expected plugin to be started")
+	}
+	if addr := app.Addr(); addr == nil || addr.String() != srv.Addr {
+		t.Errorf("expected Addr %s, got %v", srv.Addr, addr)
+	}
+	if body := get(t, srv, "/ping"); body != "pong" {
+		t.Errorf("expected pong, got %q", body)
+	}
+
+	if err := srv.Shutdown(); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if err := srv.Shutdown(); err != nil {
+		t.Errorf("second Shutdown should be a no-op, got %v", err)
+	}
+	if !plugin.stopped.Load() {
+		t.Error("expected plugin to be stopped")
+	}
+	if app.Addr() != nil {
+		t.Errorf("expected nil Addr after shutdown, got %v", app.Addr())
+	}
+	if _, err := http.Get(srv.URL + "/ping"); err == nil {
+		t.Error("expected listener to be closed")
+	}
+	if err := app.Shutdown(context.Background()); err == nil {
+		t.Error("expected error shutting down a stopped application")
+	}
+}
+
+func TestTLSServer(t *testing.T) {
+	app, _ := newTestApp()
+	srv := NewTLSServer(t, app)
+
+	if body := get(t, srv, "/ping"); body != "pong" {
+		t.Errorf("expected pong, got %q", body)
+	}
+	if _, err := http.Get(srv.URL + "/ping"); err == nil {
+		t.Error("expected untrusted client to fail the handshake")
+	}
+}
@@ -0,0 +1,51 @@
+package monitoring
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+)
+
+// shardedCounter is a counter spread over cache-line padded shards. Add
+// touches one randomly chosen shard, so concurrent requests do not bounce
+// a single cache line between cores; Load sums the shards.
+type shardedCounter struct {
+	shards []counterShard
+	mask   uint64
+}
+
+type counterShard struct {
+	n atomic.Int64
+	// Keep shards on separate cache lines
+	_ [56]byte
+}
+
+func newShardedCounter() *shardedCounter {
+	shards := shardCount()
+	return &shardedCounter{shards: make([]counterShard, shards), mask: uint64(shards - 1)}
+}
+
+// Add adds delta to the counter
+func (c *shardedCounter) Add(delta int64) {
+	c.shards[rand.Uint64()&c.mask].n.Add(delta)
+}
+
+// Load returns the counter value. Adds running concurrently may or may not
+// be included.
+func (c *shardedCounter) Load() int64 {
+	var total int64
+	for i := range c.shards {
+		total += c.shards[i].n.Load()
+	}
+	return total
+}
+
+// shardCount returns the number of shards for per-request structures: the
+// next power of two at or above GOMAXPROCS, at most 64
+func shardCount() int {
+	shards := 1
+	for shards < runtime.GOMAXPROCS(0) && shards < 64 {
+		shards *= 2
+	}
+	return shards
+}
@@ -0,0 +1,99 @@
+package monitoring
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+// responseTimeWindow is the number of recent response times kept for the
+// average, spread over all shards
+const responseTimeWindow = 1000
+
+// latencyRecorder keeps the most recent response times in sharded ring
+// buffers. Each request locks one randomly chosen shard, so concurrent
+// requests rarely wait for each other.
+type latencyRecorder struct {
+	shards []latencyShard
+	mask   uint64
+}
+
+type latencyShard struct {
+	mu    sync.Mutex
+	ring  []time.Duration
+	next  int
+	count int
+	// Keep shards on separate cache lines
+	_ [64]byte
+}
+
+func newLatencyRecorder() *latencyRecorder {
+	shards := shardCount()
+	r := &latencyRecorder{shards: make([]latencyShard, shards), mask: uint64(shards - 1)}
+	size := (responseTimeWindow + shards - 1) / shards
+	for i := range r.shards {
+		r.shards[i].ring = make([]time.Duration, size)
+	}
+	return r
+}
+
+// Record adds a response time, replacing the oldest one of its shard once
+// the shard is full
+func (r *latencyRecorder) Record(d time.Duration) {
+	shard := &r.shards[rand.Uint64()&r.mask]
+	shard.mu.Lock()
+	shard.ring[shard.next] = d
+	shard.next = (shard.next + 1) % len(shard.ring)
+	if shard.count < len(shard.ring) {
+		shard.count++
+	}
+	shard.mu.Unlock()
+}
+
+// Samples returns a copy of the recorded response times in no particular order
+func (r *latencyRecorder) Samples() []time.Duration {
+	var samples []time.Duration
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mu.Lock()
+		samples = append(samples, shard.ring[:shard.count]...)
+		shard.mu.Unlock()
+	}
+	return samples
+}
+
+// Average returns the mean of the recorded response times
+func (r *latencyRecorder) Average() time.Duration {
+	var total time.Duration
+	var count int
+	for i := range r.shards {
+		shard := &r.shards[i]
+		shard.mu.Lock()
+		for _, d := range shard.ring[:shard.count] {
+			total += d
+		}
+		count += shard.count
+		shard.mu.Unlock()
+	}
+
+	if count == 0 {
+		return 0
+	}
+	return total / time.Duration(count)
+}
+
+// Percentile returns the response time below which the fraction q of the
+// recorded response times fall, e.g. 0.95 for the 95th percentile, using
+// the nearest-rank method
+func (r *latencyRecorder) Percentile(q float64) time.Duration {
+	samples := r.Samples()
+	if len(samples) == 0 {
+		return 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	rank := int(math.Ceil(q*float64(len(samples)))) - 1
+	return samples[min(max(rank, 0), len(samples)-1)]
+}
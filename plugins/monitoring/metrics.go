@@ -0,0 +1,223 @@
+package monitoring
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+)
+
+// DefaultBuckets are the Prometheus default histogram buckets
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry is an in-memory gorgo.Metrics implementation that can be
+// exported in the Prometheus text exposition format
+type Registry struct {
+	mu         sync.RWMutex
+	counters   map[string]*sample
+	gauges     map[string]*sample
+	histograms map[string]*histogram
+	buckets    []float64
+}
+
+type sample struct {
+	name   string
+	labels string
+	value  float64
+}
+
+type histogram struct {
+	name   string
+	labels gorgo.Labels
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		counters:   make(map[string]*sample),
+		gauges:     make(map[string]*sample),
+		histograms: make(map[string]*histogram),
+		buckets:    DefaultBuckets,
+	}
+}
+
+func (r *Registry) Counter(name string, value float64, labels gorgo.Labels) {
+	key, formatted := seriesKey(name, labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.counters[key]
+	if !ok {
+		s = &sample{name: name, labels: formatted}
+		r.counters[key] = s
+	}
+	s.value += value
+}
+
+func (r *Registry) Gauge(name string, value float64, labels gorgo.Labels) {
+	key, formatted := seriesKey(name, labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.gauges[key]
+	if !ok {
+		s = &sample{name: name, labels: formatted}
+		r.gauges[key] = s
+	}
+	s.value = value
+}
+
+func (r *Registry) Histogram(name string, value float64, labels gorgo.Labels) {
+	key, _ := seriesKey(name, labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.histograms[key]
+	if !ok {
+		// Copy the labels, the caller may reuse the map
+		h = &histogram{name: name, labels: copyLabels(labels), counts: make([]uint64, len(r.buckets))}
+		r.histograms[key] = h
+	}
+	for i, bound := range r.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+// WritePrometheus writes all metrics in the Prometheus text format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if err := writeSamples(w, "counter", r.counters); err != nil {
+		return err
+	}
+	if err := writeSamples(w, "gauge", r.gauges); err != nil {
+		return err
+	}
+
+	typed := make(map[string]bool)
+	for _, key := range sortedSeries(r.histograms, func(h *histogram) string { return h.name }) {
+		h := r.histograms[key]
+		if !typed[h.name] {
+			typed[h.name] = true
+			if _, err := fmt.Fprintf(w, "# TYPE %s histogram\n", h.name); err != nil {
+				return err
+			}
+		}
+
+		for i, bound := range r.buckets {
+			_, labels := seriesKey(h.name, withLabel(h.labels, "le", formatFloat(bound)))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, h.counts[i])
+		}
+		_, labels := seriesKey(h.name, withLabel(h.labels, "le", "+Inf"))
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, h.count)
+
+		_, labels = seriesKey(h.name, h.labels)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels, formatFloat(h.sum))
+		if _, err := fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels, h.count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeSamples(w io.Writer, metricType string, samples map[string]*sample) error {
+	typed := make(map[string]bool)
+	for _, key := range sortedSeries(samples, func(s *sample) string { return s.name }) {
+		s := samples[key]
+		if !typed[s.name] {
+			typed[s.name] = true
+			if _, err := fmt.Fprintf(w, "# TYPE %s %s\n", s.name, metricType); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", s.name, s.labels, formatFloat(s.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seriesKey returns a unique key for the series and its formatted label set
+func seriesKey(name string, labels gorgo.Labels) (string, string) {
+	if len(labels) == 0 {
+		return name, ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, k, labelEscaper.Replace(labels[k]))
+	}
+	b.WriteByte('}')
+
+	return name + b.String(), b.String()
+}
+
+// labelEscaper escapes label values as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func copyLabels(labels gorgo.Labels) gorgo.Labels {
+	if labels == nil {
+		return nil
+	}
+	result := make(gorgo.Labels, len(labels))
+	for k, v := range labels {
+		result[k] = v
+	}
+	return result
+}
+
+func withLabel(labels gorgo.Labels, key, value string) gorgo.Labels {
+	result := make(gorgo.Labels, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[key] = value
+	return result
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return fmt.Sprintf("%g", value)
+}
+
+// sortedSeries returns the series keys grouped by metric name, since
+// Prometheus requires the samples of a family to be contiguous. Sorting
+// the keys alone would interleave e.g. a, a_b and a{...}.
+func sortedSeries[V any](m map[string]V, name func(V) string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ni, nj := name(m[keys[i]]), name(m[keys[j]])
+		if ni != nj {
+			return ni < nj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
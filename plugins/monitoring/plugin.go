@@ -2,8 +2,11 @@ package monitoring
 
 import (
 	"context"
+	"io"
 	"log"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/GorgoFramework/gorgo/internal/container"
@@ -12,26 +15,98 @@ import (
 
 type MonitoringPlugin struct {
 	gorgo.BasePlugin
-	stats    *Stats
-	config   MonitoringConfig
-	stopChan chan struct{}
+	stats   *Stats
+	metrics *Registry
+	config  MonitoringConfig
+
+	// Periodic reporting lifecycle, nil while not running
+	reportMu   sync.Mutex
+	stopChan   chan struct{}
+	reportDone chan struct{}
 }
 
 type MonitoringConfig struct {
-	Enabled        bool `toml:"enabled"`
-	ReportInterval int  `toml:"report_interval"` // in seconds
-	LogRequests    bool `toml:"log_requests"`
+	Enabled bool `toml:"enabled"`
+	// ReportInterval is a Go duration string, e.g. "60s"; plain numbers are seconds
+	ReportInterval time.Duration `toml:"report_interval"`
+	LogRequests    bool          `toml:"log_requests"`
+	// RuntimeMetrics adds Go runtime metrics to the Prometheus endpoint.
+	// Disable it when another collector already exports them.
+	RuntimeMetrics bool `toml:"runtime_metrics"`
 }
 
+// Stats holds the request statistics. The counters are sharded and the
+// response times kept in sharded ring buffers, so recording a request takes
+// no lock shared by all requests.
 type Stats struct {
-	mu               sync.RWMutex
-	TotalRequests    int64
-	SuccessRequests  int64
-	ErrorRequests    int64
-	NotFoundRequests int64
-	StartTime        time.Time
-	LastRequestTime  time.Time
-	ResponseTimes    []time.Duration
+	StartTime time.Time
+
+	totalRequests    *shardedCounter
+	successRequests  *shardedCounter
+	errorRequests    *shardedCounter
+	notFoundRequests *shardedCounter
+	lastRequestTime  atomic.Int64 // Unix nanoseconds, 0 before the first request
+	responseTimes    *latencyRecorder
+
+	// mu guards slaOverruns only, overruns are rare enough for a plain lock
+	mu          sync.Mutex
+	slaOverruns map[string]int64
+}
+
+func newStats() *Stats {
+	return &Stats{
+		StartTime:        time.Now(),
+		totalRequests:    newShardedCounter(),
+		successRequests:  newShardedCounter(),
+		errorRequests:    newShardedCounter(),
+		notFoundRequests: newShardedCounter(),
+		responseTimes:    newLatencyRecorder(),
+	}
+}
+
+// TotalRequests returns the number of requests received
+func (s *Stats) TotalRequests() int64 { return s.totalRequests.Load() }
+
+// SuccessRequests returns the number of requests completed without error
+func (s *Stats) SuccessRequests() int64 { return s.successRequests.Load() }
+
+// ErrorRequests returns the number of requests whose handler failed
+func (s *Stats) ErrorRequests() int64 { return s.errorRequests.Load() }
+
+// NotFoundRequests returns the number of requests that matched no route
+func (s *Stats) NotFoundRequests() int64 { return s.notFoundRequests.Load() }
+
+// LastRequestTime returns when the last request was received, the zero
+// time before the first one
+func (s *Stats) LastRequestTime() time.Time {
+	if nanos := s.lastRequestTime.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+	return time.Time{}
+}
+
+// SLAOverruns returns a copy of the SLAMiddleware budget overruns per route
+func (s *Stats) SLAOverruns() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overruns := make(map[string]int64, len(s.slaOverruns))
+	for route, count := range s.slaOverruns {
+		overruns[route] = count
+	}
+	return overruns
+}
+
+// ResponseTimes returns the most recent response times, up to 1000, in no
+// particular order
+func (s *Stats) ResponseTimes() []time.Duration {
+	return s.responseTimes.Samples()
+}
+
+// ResponseTimePercentile returns the q-th quantile of the most recent
+// response times, e.g. ResponseTimePercentile(0.99) for the p99
+func (s *Stats) ResponseTimePercentile(q float64) time.Duration {
+	return s.responseTimes.Percentile(q)
 }
 
 func NewMonitoringPlugin() *MonitoringPlugin {
@@ -46,8 +121,8 @@ func NewMonitoringPlugin() *MonitoringPlugin {
 
 	return &MonitoringPlugin{
 		BasePlugin: gorgo.NewBasePlugin(metadata),
-		stats:      &Stats{StartTime: time.Now()},
-		stopChan:   make(chan struct{}),
+		stats:      newStats(),
+		metrics:    NewRegistry(),
 	}
 }
 
@@ -59,92 +134,101 @@ func (p *MonitoringPlugin) ValidateConfig(config map[string]interface{}) error {
 func (p *MonitoringPlugin) GetDefaultConfig() map[string]interface{} {
 	return map[string]interface{}{
 		"enabled":         true,
-		"report_interval": 60,
+		"report_interval": "60s",
 		"log_requests":    true,
+		"runtime_metrics": true,
 	}
 }
 
 // ServiceProvider implementation
 func (p *MonitoringPlugin) GetServices() map[string]interface{} {
 	return map[string]interface{}{
-		"monitoring": p,
-		"stats":      p.stats,
+		"monitoring":         p,
+		"stats":              p.stats,
+		gorgo.MetricsService: p.metrics,
 	}
 }
 
 // EventSubscriber implementation
 func (p *MonitoringPlugin) GetEventSubscriptions() map[string]gorgo.EventHandler {
 	return map[string]gorgo.EventHandler{
-		"request.incoming":  p.onRequestIncoming,
-		"request.completed": p.onRequestCompleted,
-		"request.error":     p.onRequestError,
-		"request.not_found": p.onRequestNotFound,
-		"app.starting":      p.onAppStarting,
-		"app.stopping":      p.onAppStopping,
-		"server.started":    p.onServerStarted,
-		"plugin.started":    p.onPluginStarted,
-		"plugin.stopped":    p.onPluginStopped,
+		gorgo.RequestIncoming{}.EventName():  gorgo.TypedHandler(p.onRequestIncoming),
+		gorgo.RequestCompleted{}.EventName(): gorgo.TypedHandler(p.onRequestCompleted),
+		gorgo.RequestError{}.EventName():     gorgo.TypedHandler(p.onRequestError),
+		gorgo.RequestNotFound{}.EventName():  gorgo.TypedHandler(p.onRequestNotFound),
+		gorgo.SLAExceeded{}.EventName():      gorgo.TypedHandler(p.onSLAExceeded),
+		"app.starting":                       p.onAppStarting,
+		"app.stopping":                       p.onAppStopping,
+		"server.started":                     p.onServerStarted,
+		"plugin.*":                           p.onPluginEvent,
 	}
 }
 
-func (p *MonitoringPlugin) onRequestIncoming(event *gorgo.Event) error {
+func (p *MonitoringPlugin) onRequestIncoming(event gorgo.RequestIncoming) error {
 	if !p.config.Enabled {
 		return nil
 	}
 
-	p.stats.mu.Lock()
-	p.stats.TotalRequests++
-	p.stats.LastRequestTime = time.Now()
-	p.stats.mu.Unlock()
+	p.stats.totalRequests.Add(1)
+	p.stats.lastRequestTime.Store(time.Now().UnixNano())
 
 	if p.config.LogRequests {
-		method := event.Data["method"]
-		path := event.Data["path"]
-		ip := event.Data["ip"]
-		log.Printf("Request: %s %s from %s", method, path, ip)
+		log.Printf("Request: %s %s from %s", event.Method, event.Path, event.IP)
 	}
 
 	return nil
 }
 
-func (p *MonitoringPlugin) onRequestCompleted(event *gorgo.Event) error {
+func (p *MonitoringPlugin) onRequestCompleted(event gorgo.RequestCompleted) error {
 	if !p.config.Enabled {
 		return nil
 	}
 
-	p.stats.mu.Lock()
-	p.stats.SuccessRequests++
-	p.stats.mu.Unlock()
+	p.stats.successRequests.Add(1)
 
 	return nil
 }
 
-func (p *MonitoringPlugin) onRequestError(event *gorgo.Event) error {
+func (p *MonitoringPlugin) onRequestError(event gorgo.RequestError) error {
 	if !p.config.Enabled {
 		return nil
 	}
 
-	p.stats.mu.Lock()
-	p.stats.ErrorRequests++
-	p.stats.mu.Unlock()
+	p.stats.errorRequests.Add(1)
 
-	method := event.Data["method"]
-	path := event.Data["path"]
-	errorMsg := event.Data["error"]
-	log.Printf("Error: %s %s - %s", method, path, errorMsg)
+	log.Printf("Error: %s %s - %s", event.Method, event.Path, event.Error)
 
 	return nil
 }
 
-func (p *MonitoringPlugin) onRequestNotFound(event *gorgo.Event) error {
+func (p *MonitoringPlugin) onRequestNotFound(event gorgo.RequestNotFound) error {
 	if !p.config.Enabled {
 		return nil
 	}
 
+	p.stats.notFoundRequests.Add(1)
+
+	return nil
+}
+
+func (p *MonitoringPlugin) onSLAExceeded(event gorgo.SLAExceeded) error {
+	if !p.config.Enabled {
+		return nil
+	}
+
+	route := event.Route
+	if route == "" {
+		route = event.Path
+	}
+
 	p.stats.mu.Lock()
-	p.stats.NotFoundRequests++
+	if p.stats.slaOverruns == nil {
+		p.stats.slaOverruns = make(map[string]int64)
+	}
+	p.stats.slaOverruns[event.Method+" "+route]++
 	p.stats.mu.Unlock()
 
+	p.metrics.Counter("gorgo_sla_overruns_total", 1, gorgo.Labels{"method": event.Method, "route": route})
 	return nil
 }
 
@@ -165,15 +249,18 @@ func (p *MonitoringPlugin) onServerStarted(event *gorgo.Event) error {
 	return nil
 }
 
-func (p *MonitoringPlugin) onPluginStarted(event *gorgo.Event) error {
+// onPluginEvent logs plugin lifecycle events: plugin.started,
+// plugin.stopped and plugin.start_failed
+func (p *MonitoringPlugin) onPluginEvent(event *gorgo.Event) error {
 	pluginName := event.Data["plugin"]
-	log.Printf("Monitoring: Plugin '%s' started", pluginName)
-	return nil
-}
-
-func (p *MonitoringPlugin) onPluginStopped(event *gorgo.Event) error {
-	pluginName := event.Data["plugin"]
-	log.Printf("Monitoring: Plugin '%s' stopped", pluginName)
+	switch event.Name {
+	case "plugin.started":
+		log.Printf("Monitoring: Plugin '%s' started", pluginName)
+	case "plugin.stopped":
+		log.Printf("Monitoring: Plugin '%s' stopped", pluginName)
+	case "plugin.start_failed":
+		log.Printf("Monitoring: Plugin '%s' failed to start: %v", pluginName, event.Data["error"])
+	}
 	return nil
 }
 
@@ -191,18 +278,16 @@ func (p *MonitoringPlugin) GetMiddleware() []gorgo.MiddlewareFunc {
 func (p *MonitoringPlugin) responseTimeMiddleware() gorgo.MiddlewareFunc {
 	return func(next gorgo.HandlerFunc) gorgo.HandlerFunc {
 		return func(ctx *gorgo.Context) error {
-			start := time.Now()
-
 			err := next(ctx)
 
-			duration := time.Since(start)
-			p.stats.mu.Lock()
-			p.stats.ResponseTimes = append(p.stats.ResponseTimes, duration)
-			// Limit response times array size
-			if len(p.stats.ResponseTimes) > 1000 {
-				p.stats.ResponseTimes = p.stats.ResponseTimes[1:]
+			duration := ctx.Elapsed()
+			p.stats.responseTimes.Record(duration)
+
+			status := strconv.Itoa(ctx.StatusCode())
+			p.metrics.Counter("gorgo_http_responses_total", 1, gorgo.Labels{"status": status})
+			if size := ctx.ResponseSize(); size >= 0 {
+				p.metrics.Counter("gorgo_http_response_bytes_total", float64(size), nil)
 			}
-			p.stats.mu.Unlock()
 
 			// Add response time header
 			ctx.Header("X-Response-Time", duration.String())
@@ -215,49 +300,78 @@ func (p *MonitoringPlugin) responseTimeMiddleware() gorgo.MiddlewareFunc {
 // Main plugin methods
 func (p *MonitoringPlugin) Initialize(container *container.Container, config map[string]interface{}) error {
 	p.config = MonitoringConfig{
-		Enabled:        getBoolConfig(config, "enabled", true),
-		ReportInterval: getIntConfig(config, "report_interval", 60),
-		LogRequests:    getBoolConfig(config, "log_requests", true),
+		Enabled:        gorgo.GetBoolConfig(config, "enabled", true),
+		ReportInterval: gorgo.GetDurationConfig(config, "report_interval", time.Minute),
+		LogRequests:    gorgo.GetBoolConfig(config, "log_requests", true),
+		RuntimeMetrics: gorgo.GetBoolConfig(config, "runtime_metrics", true),
 	}
 
-	log.Printf("Monitoring Plugin: Initialized with report interval %d seconds", p.config.ReportInterval)
+	log.Printf("Monitoring Plugin: Initialized with report interval %v", p.config.ReportInterval)
 	return p.BasePlugin.Initialize(container, config)
 }
 
 func (p *MonitoringPlugin) Start(ctx context.Context) error {
-	if p.config.Enabled {
-		// Start periodic reporting
-		go p.startPeriodicReporting()
-		log.Println("Monitoring Plugin: Started periodic reporting")
+	if p.config.Enabled && p.config.ReportInterval > 0 {
+		p.startPeriodicReporting()
 	}
 
 	return p.BasePlugin.Start(ctx)
 }
 
+// Stop is idempotent and safe to call when Start was never called
 func (p *MonitoringPlugin) Stop(ctx context.Context) error {
-	close(p.stopChan)
+	p.stopPeriodicReporting()
 	return p.BasePlugin.Stop(ctx)
 }
 
 // Additional methods
 func (p *MonitoringPlugin) startPeriodicReporting() {
-	ticker := time.NewTicker(time.Duration(p.config.ReportInterval) * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			p.printStats()
-		case <-p.stopChan:
-			return
+	p.reportMu.Lock()
+	defer p.reportMu.Unlock()
+
+	if p.stopChan != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	p.stopChan, p.reportDone = stop, done
+
+	interval := p.config.ReportInterval
+	gorgo.Go(func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.printStats()
+			case <-stop:
+				return
+			}
 		}
+	})
+	log.Println("Monitoring Plugin: Started periodic reporting")
+}
+
+// stopPeriodicReporting stops the reporting goroutine, if running, and
+// waits for it to exit
+func (p *MonitoringPlugin) stopPeriodicReporting() {
+	p.reportMu.Lock()
+	defer p.reportMu.Unlock()
+
+	if p.stopChan == nil {
+		return
 	}
+
+	close(p.stopChan)
+	<-p.reportDone
+	p.stopChan, p.reportDone = nil, nil
 }
 
 func (p *MonitoringPlugin) printStats() {
-	p.stats.mu.RLock()
-	defer p.stats.mu.RUnlock()
-
 	uptime := time.Since(p.stats.StartTime)
 	avgResponseTime := p.calculateAverageResponseTime()
 
@@ -272,19 +386,16 @@ Average Response Time: %v
 Last Request: %v ago
 ========================`,
 		uptime,
-		p.stats.TotalRequests,
-		p.stats.SuccessRequests,
-		p.stats.ErrorRequests,
-		p.stats.NotFoundRequests,
+		p.stats.TotalRequests(),
+		p.stats.SuccessRequests(),
+		p.stats.ErrorRequests(),
+		p.stats.NotFoundRequests(),
 		avgResponseTime,
-		time.Since(p.stats.LastRequestTime),
+		time.Since(p.stats.LastRequestTime()),
 	)
 }
 
 func (p *MonitoringPlugin) printFinalStats() {
-	p.stats.mu.RLock()
-	defer p.stats.mu.RUnlock()
-
 	uptime := time.Since(p.stats.StartTime)
 	avgResponseTime := p.calculateAverageResponseTime()
 
@@ -298,31 +409,27 @@ Not Found Rate: %.2f%%
 Average Response Time: %v
 ===============================`,
 		uptime,
-		p.stats.TotalRequests,
-		float64(p.stats.SuccessRequests)/float64(p.stats.TotalRequests)*100,
-		float64(p.stats.ErrorRequests)/float64(p.stats.TotalRequests)*100,
-		float64(p.stats.NotFoundRequests)/float64(p.stats.TotalRequests)*100,
+		p.stats.TotalRequests(),
+		float64(p.stats.SuccessRequests())/float64(p.stats.TotalRequests())*100,
+		float64(p.stats.ErrorRequests())/float64(p.stats.TotalRequests())*100,
+		float64(p.stats.NotFoundRequests())/float64(p.stats.TotalRequests())*100,
 		avgResponseTime,
 	)
 }
 
 func (p *MonitoringPlugin) calculateAverageResponseTime() time.Duration {
-	if len(p.stats.ResponseTimes) == 0 {
-		return 0
-	}
-
-	var total time.Duration
-	for _, rt := range p.stats.ResponseTimes {
-		total += rt
-	}
-
-	return total / time.Duration(len(p.stats.ResponseTimes))
+	return p.stats.responseTimes.Average()
 }
 
 func (p *MonitoringPlugin) GetStats() *Stats {
 	return p.stats
 }
 
+// GetMetrics returns the metrics registry backing gorgo.Metrics
+func (p *MonitoringPlugin) GetMetrics() *Registry {
+	return p.metrics
+}
+
 // Middleware for creating metrics endpoint
 func (p *MonitoringPlugin) MetricsEndpointMiddleware(path string) gorgo.MiddlewareFunc {
 	return func(next gorgo.HandlerFunc) gorgo.HandlerFunc {
@@ -336,38 +443,64 @@ func (p *MonitoringPlugin) MetricsEndpointMiddleware(path string) gorgo.Middlewa
 }
 
 func (p *MonitoringPlugin) handleMetricsEndpoint(ctx *gorgo.Context) error {
-	p.stats.mu.RLock()
-	defer p.stats.mu.RUnlock()
-
 	uptime := time.Since(p.stats.StartTime)
 	avgResponseTime := p.calculateAverageResponseTime()
 
 	metrics := gorgo.Map{
 		"uptime_seconds":           uptime.Seconds(),
-		"total_requests":           p.stats.TotalRequests,
-		"success_requests":         p.stats.SuccessRequests,
-		"error_requests":           p.stats.ErrorRequests,
-		"not_found_requests":       p.stats.NotFoundRequests,
+		"total_requests":           p.stats.TotalRequests(),
+		"success_requests":         p.stats.SuccessRequests(),
+		"error_requests":           p.stats.ErrorRequests(),
+		"not_found_requests":       p.stats.NotFoundRequests(),
 		"average_response_time_ms": avgResponseTime.Milliseconds(),
+		"p50_response_time_ms":     p.stats.ResponseTimePercentile(0.5).Milliseconds(),
+		"p95_response_time_ms":     p.stats.ResponseTimePercentile(0.95).Milliseconds(),
+		"p99_response_time_ms":     p.stats.ResponseTimePercentile(0.99).Milliseconds(),
 	}
 
+	if ctx.QueryBool("pretty") {
+		return ctx.JSONPretty(metrics, "  ")
+	}
 	return ctx.JSON(metrics)
 }
 
-// Helper functions
-func getBoolConfig(config map[string]interface{}, key string, defaultValue bool) bool {
-	if value, ok := config[key].(bool); ok {
-		return value
+// PrometheusEndpointMiddleware serves all metrics in the Prometheus text
+// format at path, including the built-in request statistics and, unless
+// runtime_metrics is disabled, the Go runtime metrics
+func (p *MonitoringPlugin) PrometheusEndpointMiddleware(path string) gorgo.MiddlewareFunc {
+	return func(next gorgo.HandlerFunc) gorgo.HandlerFunc {
+		return func(ctx *gorgo.Context) error {
+			if ctx.Path() != path {
+				return next(ctx)
+			}
+
+			ctx.FastHTTP().Response.Header.SetContentType("text/plain; version=0.0.4")
+			return p.writePrometheus(ctx.FastHTTP().Response.BodyWriter())
+		}
 	}
-	return defaultValue
 }
 
-func getIntConfig(config map[string]interface{}, key string, defaultValue int) int {
-	if value, ok := config[key].(int); ok {
-		return value
+func (p *MonitoringPlugin) writePrometheus(w io.Writer) error {
+	p.recordStatsGauges()
+
+	if err := p.metrics.WritePrometheus(w); err != nil {
+		return err
 	}
-	if value, ok := config[key].(float64); ok {
-		return int(value)
+	if p.config.RuntimeMetrics {
+		return WriteRuntimeMetrics(w)
+	}
+	return nil
+}
+
+func (p *MonitoringPlugin) recordStatsGauges() {
+	p.metrics.Gauge("gorgo_uptime_seconds", time.Since(p.stats.StartTime).Seconds(), nil)
+	p.metrics.Gauge("gorgo_requests_total", float64(p.stats.TotalRequests()), nil)
+	p.metrics.Gauge("gorgo_requests_success_total", float64(p.stats.SuccessRequests()), nil)
+	p.metrics.Gauge("gorgo_requests_error_total", float64(p.stats.ErrorRequests()), nil)
+	p.metrics.Gauge("gorgo_requests_not_found_total", float64(p.stats.NotFoundRequests()), nil)
+	p.metrics.Gauge("gorgo_response_time_average_seconds", p.calculateAverageResponseTime().Seconds(), nil)
+	for _, quantile := range []string{"0.5", "0.95", "0.99"} {
+		q, _ := strconv.ParseFloat(quantile, 64)
+		p.metrics.Gauge("gorgo_response_time_seconds", p.stats.ResponseTimePercentile(q).Seconds(), gorgo.Labels{"quantile": quantile})
 	}
-	return defaultValue
 }
@@ -0,0 +1,245 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+	"github.com/valyala/fasthttp"
+)
+
+func TestMonitoringPlugin_StopTwice(t *testing.T) {
+	plugin := NewMonitoringPlugin()
+	if err := plugin.Initialize(container.NewContainer(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := plugin.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if err := plugin.Stop(ctx); err != nil {
+		t.Errorf("first Stop failed: %v", err)
+	}
+	if err := plugin.Stop(ctx); err != nil {
+		t.Errorf("second Stop failed: %v", err)
+	}
+}
+
+func TestMonitoringPlugin_StopWithoutStart(t *testing.T) {
+	plugin := NewMonitoringPlugin()
+	if err := plugin.Initialize(container.NewContainer(), map[string]interface{}{"enabled": false}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	if err := plugin.Stop(context.Background()); err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+}
+
+func TestMonitoringPlugin_Restart(t *testing.T) {
+	plugin := NewMonitoringPlugin()
+	if err := plugin.Initialize(container.NewContainer(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := plugin.Start(ctx); err != nil {
+			t.Fatalf("Start %d failed: %v", i, err)
+		}
+		if err := plugin.Stop(ctx); err != nil {
+			t.Fatalf("Stop %d failed: %v", i, err)
+		}
+	}
+}
+
+func TestMonitoringPlugin_RuntimeMetrics(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		plugin := NewMonitoringPlugin()
+		config := map[string]interface{}{"runtime_metrics": enabled}
+		if err := plugin.Initialize(container.NewContainer(), config); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := plugin.writePrometheus(&buf); err != nil {
+			t.Fatalf("writePrometheus failed: %v", err)
+		}
+		out := buf.String()
+
+		if !strings.Contains(out, "gorgo_requests_total") {
+			t.Errorf("runtime_metrics=%v: request metrics missing:\n%s", enabled, out)
+		}
+		for _, name := range []string{"go_goroutines ", "go_gc_duration_seconds_count ", "go_memstats_heap_alloc_bytes "} {
+			if strings.Contains(out, name) != enabled {
+				t.Errorf("runtime_metrics=%v: presence of %q = %v", enabled, name, !enabled)
+			}
+		}
+	}
+}
+
+func BenchmarkResponseTimeMiddleware(b *testing.B) {
+	plugin := NewMonitoringPlugin()
+	if err := plugin.Initialize(container.NewContainer(), map[string]interface{}{"log_requests": false}); err != nil {
+		b.Fatalf("Initialize failed: %v", err)
+	}
+	handler := plugin.responseTimeMiddleware()(func(ctx *gorgo.Context) error { return nil })
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		ctx := gorgo.NewContext(&fasthttp.RequestCtx{}, container.NewContainer(), nil)
+		for pb.Next() {
+			handler(ctx)
+		}
+	})
+}
+
+func TestLatencyRecorder(t *testing.T) {
+	recorder := newLatencyRecorder()
+	if recorder.Average() != 0 || len(recorder.Samples()) != 0 {
+		t.Fatal("expected an empty recorder")
+	}
+
+	for i := 0; i < 5000; i++ {
+		recorder.Record(10 * time.Millisecond)
+	}
+	capacity := len(recorder.shards) * len(recorder.shards[0].ring)
+	if samples := recorder.Samples(); len(samples) == 0 || len(samples) > capacity || capacity < responseTimeWindow {
+		t.Errorf("expected at most %d recent samples, got %d", capacity, len(samples))
+	}
+	if avg := recorder.Average(); avg != 10*time.Millisecond {
+		t.Errorf("expected average 10ms, got %v", avg)
+	}
+
+	// Old samples are replaced by new ones
+	for i := 0; i < 100*capacity; i++ {
+		recorder.Record(20 * time.Millisecond)
+	}
+	if avg := recorder.Average(); avg != 20*time.Millisecond {
+		t.Errorf("expected average 20ms after the window moved, got %v", avg)
+	}
+}
+
+func BenchmarkLatencyRecorder(b *testing.B) {
+	recorder := newLatencyRecorder()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			recorder.Record(time.Millisecond)
+		}
+	})
+}
+
+func TestLatencyRecorderPercentile(t *testing.T) {
+	recorder := newLatencyRecorder()
+	if p := recorder.Percentile(0.99); p != 0 {
+		t.Errorf("expected 0 for an empty recorder, got %v", p)
+	}
+
+	for i := 1; i <= 100; i++ {
+		recorder.Record(time.Duration(i) * time.Millisecond)
+	}
+	tests := map[float64]time.Duration{0: time.Millisecond, 0.5: 50 * time.Millisecond, 0.95: 95 * time.Millisecond, 0.99: 99 * time.Millisecond, 1: 100 * time.Millisecond}
+	for q, want := range tests {
+		if got := recorder.Percentile(q); got != want {
+			t.Errorf("Percentile(%v) = %v, want %v", q, got, want)
+		}
+	}
+}
+
+func TestStatsConcurrentRequests(t *testing.T) {
+	plugin := NewMonitoringPlugin()
+	if err := plugin.Initialize(container.NewContainer(), map[string]interface{}{"log_requests": false}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				plugin.onRequestIncoming(gorgo.RequestIncoming{Method: "GET", Path: "/"})
+				plugin.onRequestCompleted(gorgo.RequestCompleted{Method: "GET", Path: "/"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := plugin.GetStats()
+	if stats.TotalRequests() != 8000 || stats.SuccessRequests() != 8000 {
+		t.Errorf("expected 8000 total and successful requests, got %d and %d", stats.TotalRequests(), stats.SuccessRequests())
+	}
+	if stats.LastRequestTime().IsZero() {
+		t.Error("expected the last request time to be set")
+	}
+}
+
+// BenchmarkRequestCounter compares the sharded request counter with the
+// single mutex it replaced, with all goroutines counting at once
+func BenchmarkRequestCounter(b *testing.B) {
+	b.Run("mutex", func(b *testing.B) {
+		var mu sync.Mutex
+		var count int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				mu.Lock()
+				count++
+				mu.Unlock()
+			}
+		})
+	})
+	b.Run("sharded", func(b *testing.B) {
+		counter := newShardedCounter()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				counter.Add(1)
+			}
+		})
+	})
+}
+
+func TestRegistryWritePrometheus(t *testing.T) {
+	registry := NewRegistry()
+	registry.buckets = []float64{0.1, 1}
+
+	// a, a_b and a{...} would interleave when sorted by series key alone
+	registry.Counter("a", 1, gorgo.Labels{"x": "1"})
+	registry.Counter("a_b", 2, nil)
+	registry.Counter("a", 3, nil)
+	registry.Gauge("g", 1, gorgo.Labels{"path": "say \"hi\"\n\\"})
+
+	labels := gorgo.Labels{"route": "/users"}
+	registry.Histogram("latency", 0.05, labels)
+	labels["route"] = "/changed"
+	registry.Histogram("latency", 0.5, gorgo.Labels{"route": "/users"})
+
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+
+	expected := `# TYPE a counter
+a 3
+a{x="1"} 1
+# TYPE a_b counter
+a_b 2
+# TYPE g gauge
+g{path="say \"hi\"\n\\"} 1
+# TYPE latency histogram
+latency_bucket{le="0.1",route="/users"} 1
+latency_bucket{le="1",route="/users"} 2
+latency_bucket{le="+Inf",route="/users"} 2
+latency_sum{route="/users"} 0.55
+latency_count{route="/users"} 2
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected exposition:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
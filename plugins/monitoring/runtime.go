@@ -0,0 +1,70 @@
+package monitoring
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"time"
+)
+
+// gcPauseQuantiles are the quantiles reported for go_gc_duration_seconds
+var gcPauseQuantiles = []float64{0, 0.25, 0.5, 0.75, 1}
+
+// memStatsMetric describes one runtime.MemStats field exported under the
+// name used by the standard Prometheus Go collector
+type memStatsMetric struct {
+	name       string
+	metricType string
+	value      func(ms *runtime.MemStats) float64
+}
+
+var memStatsMetrics = []memStatsMetric{
+	{"go_memstats_alloc_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.Alloc) }},
+	{"go_memstats_alloc_bytes_total", "counter", func(ms *runtime.MemStats) float64 { return float64(ms.TotalAlloc) }},
+	{"go_memstats_sys_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.Sys) }},
+	{"go_memstats_mallocs_total", "counter", func(ms *runtime.MemStats) float64 { return float64(ms.Mallocs) }},
+	{"go_memstats_frees_total", "counter", func(ms *runtime.MemStats) float64 { return float64(ms.Frees) }},
+	{"go_memstats_heap_alloc_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.HeapAlloc) }},
+	{"go_memstats_heap_sys_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.HeapSys) }},
+	{"go_memstats_heap_idle_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.HeapIdle) }},
+	{"go_memstats_heap_inuse_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.HeapInuse) }},
+	{"go_memstats_heap_released_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.HeapReleased) }},
+	{"go_memstats_heap_objects", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.HeapObjects) }},
+	{"go_memstats_stack_inuse_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.StackInuse) }},
+	{"go_memstats_gc_sys_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.GCSys) }},
+	{"go_memstats_next_gc_bytes", "gauge", func(ms *runtime.MemStats) float64 { return float64(ms.NextGC) }},
+	{"go_memstats_last_gc_time_seconds", "gauge", func(ms *runtime.MemStats) float64 {
+		return float64(ms.LastGC) / float64(time.Second)
+	}},
+}
+
+// WriteRuntimeMetrics writes goroutine, thread, GC and memory metrics in the
+// Prometheus text format, using the metric names of the standard Prometheus
+// Go collector so existing dashboards work unchanged
+func WriteRuntimeMetrics(w io.Writer) error {
+	fmt.Fprintf(w, "# TYPE go_goroutines gauge\ngo_goroutines %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(w, "# TYPE go_threads gauge\ngo_threads %d\n", pprof.Lookup("threadcreate").Count())
+	fmt.Fprintf(w, "# TYPE go_info gauge\ngo_info{version=%q} 1\n", runtime.Version())
+
+	gcStats := debug.GCStats{PauseQuantiles: make([]time.Duration, len(gcPauseQuantiles))}
+	debug.ReadGCStats(&gcStats)
+
+	fmt.Fprint(w, "# TYPE go_gc_duration_seconds summary\n")
+	for i, q := range gcPauseQuantiles {
+		fmt.Fprintf(w, "go_gc_duration_seconds{quantile=%q} %s\n", formatFloat(q), formatFloat(gcStats.PauseQuantiles[i].Seconds()))
+	}
+	fmt.Fprintf(w, "go_gc_duration_seconds_sum %s\n", formatFloat(gcStats.PauseTotal.Seconds()))
+	fmt.Fprintf(w, "go_gc_duration_seconds_count %d\n", gcStats.NumGC)
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	for _, m := range memStatsMetrics {
+		if _, err := fmt.Fprintf(w, "# TYPE %s %s\n%s %s\n", m.name, m.metricType, m.name, formatFloat(m.value(&ms))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,453 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+	"github.com/nats-io/nats.go"
+)
+
+// flushTimeout bounds how long Stop waits for buffered messages to be sent
+const flushTimeout = 5 * time.Second
+
+// messageBuffer is the number of received messages queued per subscription.
+// When a handler falls further behind, NATS reports a slow consumer and
+// drops messages (core NATS) or redelivers them (JetStream).
+const messageBuffer = 64
+
+type NatsPlugin struct {
+	gorgo.BasePlugin
+	conn   *nats.Conn
+	js     nats.JetStreamContext
+	config NatsConfig
+
+	mu            sync.Mutex
+	subscriptions []*subscription
+	stopChan      chan struct{}
+	wg            sync.WaitGroup
+}
+
+type NatsConfig struct {
+	URL      string `toml:"url"`
+	Name     string `toml:"name"`
+	User     string `toml:"user"`
+	Password string `toml:"password"`
+	Token    string `toml:"token"`
+	// MaxReconnects is the number of reconnect attempts, -1 retries forever
+	MaxReconnects int           `toml:"max_reconnects"`
+	ReconnectWait time.Duration `toml:"reconnect_wait"`
+	// JetStream enables persisted, acknowledged publishing and delivery
+	JetStream bool `toml:"jetstream"`
+	// QueueGroup is the default queue group of subscriptions
+	QueueGroup string `toml:"queue_group"`
+	// ForwardEvents are EventBus events published to SubjectPrefix + event name
+	ForwardEvents []string `toml:"forward_events"`
+	SubjectPrefix string   `toml:"subject_prefix"`
+}
+
+// MessageHandler processes a received message. With JetStream the message
+// is acknowledged when the handler returns nil and redelivered when it
+// returns an error or panics. The context is canceled when the plugin stops.
+type MessageHandler func(ctx context.Context, msg *nats.Msg) error
+
+// SubscribeOptions configuration for a subscription
+type SubscribeOptions struct {
+	// Queue distributes messages among subscribers of the same queue group,
+	// defaults to queue_group from the config
+	Queue string
+	// Durable names the JetStream consumer, so delivery resumes where it
+	// left off after a restart
+	Durable string
+}
+
+type subscription struct {
+	subject string
+	handler MessageHandler
+	options SubscribeOptions
+	sub     *nats.Subscription
+	// cancel cancels the context of running handlers
+	cancel context.CancelFunc
+}
+
+func NewNatsPlugin() *NatsPlugin {
+	metadata := gorgo.PluginMetadata{
+		Name:        "nats",
+		Version:     "1.0.0",
+		Description: "NATS messaging plugin with EventBus forwarding",
+		Author:      "Gorgo Framework",
+		Priority:    gorgo.PriorityNormal,
+		Tags:        []string{"messaging", "nats", "pubsub"},
+	}
+
+	return &NatsPlugin{
+		BasePlugin: gorgo.NewBasePlugin(metadata),
+	}
+}
+
+// ConfigurablePlugin implementation
+func (p *NatsPlugin) ValidateConfig(config map[string]interface{}) error {
+	url := gorgo.GetStringConfig(config, "url", "")
+	if url == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	for _, server := range strings.Split(url, ",") {
+		if !strings.Contains(server, "://") {
+			return fmt.Errorf("invalid url %q: missing scheme, e.g. nats://", gorgo.RedactURL(server))
+		}
+	}
+	return nil
+}
+
+func (p *NatsPlugin) GetDefaultConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"url":            nats.DefaultURL,
+		"name":           "gorgo",
+		"user":           "",
+		"password":       "",
+		"token":          "",
+		"max_reconnects": -1,
+		"reconnect_wait": "2s",
+		"jetstream":      false,
+		"queue_group":    "",
+		"forward_events": []string{},
+		"subject_prefix": "gorgo.events.",
+	}
+}
+
+// ServiceProvider implementation
+func (p *NatsPlugin) GetServices() map[string]interface{} {
+	return map[string]interface{}{
+		"nats":      p,
+		"messaging": p, // Alternative name
+	}
+}
+
+// EventSubscriber implementation
+func (p *NatsPlugin) GetEventSubscriptions() map[string]gorgo.EventHandler {
+	subscriptions := make(map[string]gorgo.EventHandler, len(p.config.ForwardEvents))
+	for _, name := range p.config.ForwardEvents {
+		subscriptions[name] = p.forwardEvent
+	}
+	return subscriptions
+}
+
+// forwardEvent publishes an EventBus event to the broker. Failures are
+// logged rather than returned so other event handlers still run.
+func (p *NatsPlugin) forwardEvent(event *gorgo.Event) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"name": event.Name,
+		"data": event.Data,
+	})
+	if err != nil {
+		log.Printf("NATS Plugin: Failed to encode event %s: %v", event.Name, err)
+		return nil
+	}
+
+	if err := p.Publish(p.config.SubjectPrefix+event.Name, data); err != nil {
+		log.Printf("NATS Plugin: Failed to forward event %s: %v", event.Name, err)
+	}
+	return nil
+}
+
+// Main plugin methods
+func (p *NatsPlugin) Initialize(container *container.Container, config map[string]interface{}) error {
+	p.config = NatsConfig{
+		URL:           gorgo.GetStringConfig(config, "url", nats.DefaultURL),
+		Name:          gorgo.GetStringConfig(config, "name", "gorgo"),
+		User:          gorgo.GetStringConfig(config, "user", ""),
+		Password:      gorgo.GetStringConfig(config, "password", ""),
+		Token:         gorgo.GetStringConfig(config, "token", ""),
+		MaxReconnects: gorgo.GetIntConfig(config, "max_reconnects", -1),
+		ReconnectWait: gorgo.GetDurationConfig(config, "reconnect_wait", 2*time.Second),
+		JetStream:     gorgo.GetBoolConfig(config, "jetstream", false),
+		QueueGroup:    gorgo.GetStringConfig(config, "queue_group", ""),
+		ForwardEvents: gorgo.GetStringSliceConfig(config, "forward_events"),
+		SubjectPrefix: gorgo.GetStringConfig(config, "subject_prefix", "gorgo.events."),
+	}
+
+	return p.BasePlugin.Initialize(container, config)
+}
+
+func (p *NatsPlugin) Start(ctx context.Context) error {
+	conn, err := nats.Connect(p.config.URL, p.connectOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", gorgo.RedactURL(p.config.URL), err)
+	}
+
+	var js nats.JetStreamContext
+	if p.config.JetStream {
+		if js, err = conn.JetStream(); err != nil {
+			conn.Close()
+			return fmt.Errorf("failed to enable JetStream: %w", err)
+		}
+	}
+
+	p.mu.Lock()
+	p.conn = conn
+	p.js = js
+	p.stopChan = make(chan struct{})
+	pending := p.subscriptions
+	p.mu.Unlock()
+
+	// Subscriptions registered before the connection existed
+	for _, sub := range pending {
+		if err := p.subscribe(sub); err != nil {
+			p.disconnect()
+			return err
+		}
+	}
+
+	log.Printf("NATS Plugin: Connected to %s", conn.ConnectedUrlRedacted())
+	return p.BasePlugin.Start(ctx)
+}
+
+// Stop lets running handlers finish, flushes buffered messages and closes
+// the connection. Durable JetStream consumers are kept, so unacknowledged
+// messages are redelivered after a restart.
+func (p *NatsPlugin) Stop(ctx context.Context) error {
+	p.disconnect()
+	return p.BasePlugin.Stop(ctx)
+}
+
+// Conn returns the underlying connection, nil while the plugin is stopped
+func (p *NatsPlugin) Conn() *nats.Conn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.conn
+}
+
+// Publish sends data to subject. With JetStream it waits for the server to
+// acknowledge that the message was persisted.
+func (p *NatsPlugin) Publish(subject string, data []byte) error {
+	p.mu.Lock()
+	conn, js := p.conn, p.js
+	p.mu.Unlock()
+
+	if conn == nil {
+		return fmt.Errorf("nats: not connected")
+	}
+	if js != nil {
+		_, err := js.Publish(subject, data)
+		return err
+	}
+	return conn.Publish(subject, data)
+}
+
+// PublishJSON encodes v as JSON and publishes it to subject
+func (p *NatsPlugin) PublishJSON(subject string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	return p.Publish(subject, data)
+}
+
+// Subscribe runs handler for every message on subject in a managed
+// goroutine that stops with the plugin. It may be called before the
+// application starts, the subscription is then created on Start.
+//
+//	plugin.Subscribe("orders.created", func(ctx context.Context, msg *nats.Msg) error {
+//	    return handleOrder(ctx, msg.Data)
+//	}, natsplugin.SubscribeOptions{Durable: "billing"})
+func (p *NatsPlugin) Subscribe(subject string, handler MessageHandler, options ...SubscribeOptions) error {
+	sub := &subscription{subject: subject, handler: handler}
+	if len(options) > 0 {
+		sub.options = options[0]
+	}
+	if sub.options.Queue == "" {
+		sub.options.Queue = p.config.QueueGroup
+	}
+
+	p.mu.Lock()
+	p.subscriptions = append(p.subscriptions, sub)
+	connected := p.conn != nil
+	p.mu.Unlock()
+
+	if !connected {
+		return nil
+	}
+	return p.subscribe(sub)
+}
+
+// Helper functions
+func (p *NatsPlugin) connectOptions() []nats.Option {
+	options := []nats.Option{
+		nats.Name(p.config.Name),
+		nats.MaxReconnects(p.config.MaxReconnects),
+		nats.ReconnectWait(p.config.ReconnectWait),
+		nats.DisconnectErrHandler(func(conn *nats.Conn, err error) {
+			if err != nil {
+				log.Printf("NATS Plugin: Disconnected: %v, reconnecting", err)
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			log.Printf("NATS Plugin: Reconnected to %s", conn.ConnectedUrlRedacted())
+		}),
+		nats.ErrorHandler(func(conn *nats.Conn, sub *nats.Subscription, err error) {
+			log.Printf("NATS Plugin: %v", err)
+		}),
+	}
+
+	if p.config.User != "" {
+		options = append(options, nats.UserInfo(p.config.User, p.config.Password))
+	}
+	if p.config.Token != "" {
+		options = append(options, nats.Token(p.config.Token))
+	}
+	return options
+}
+
+// disconnect stops the subscription goroutines and closes the connection.
+// Core NATS subscriptions are unsubscribed first so no further messages
+// arrive; those already received are still passed to their handlers, while
+// JetStream ones are Nak'd for redelivery. Running handlers see their
+// context canceled and are waited for.
+func (p *NatsPlugin) disconnect() {
+	p.mu.Lock()
+	conn := p.conn
+	stopChan := p.stopChan
+	jetStream := p.js != nil
+	p.conn = nil
+	p.js = nil
+	p.stopChan = nil
+	var cancels []context.CancelFunc
+	for _, sub := range p.subscriptions {
+		if sub.cancel != nil {
+			cancels = append(cancels, sub.cancel)
+		}
+		// Keep JetStream consumers, durable ones resume after a restart
+		if sub.sub != nil && !jetStream {
+			if err := sub.sub.Unsubscribe(); err != nil {
+				log.Printf("NATS Plugin: Failed to unsubscribe from %s: %v", sub.subject, err)
+			}
+		}
+		sub.sub = nil
+		sub.cancel = nil
+	}
+	p.mu.Unlock()
+
+	if stopChan != nil {
+		close(stopChan)
+	}
+	for _, cancel := range cancels {
+		cancel()
+	}
+	p.wg.Wait()
+
+	if conn != nil {
+		if err := conn.FlushTimeout(flushTimeout); err != nil {
+			log.Printf("NATS Plugin: Failed to flush pending messages: %v", err)
+		}
+		conn.Close()
+	}
+}
+
+func (p *NatsPlugin) subscribe(sub *subscription) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil || sub.sub != nil {
+		return nil
+	}
+
+	messages := make(chan *nats.Msg, messageBuffer)
+
+	var err error
+	switch {
+	case p.js != nil:
+		options := []nats.SubOpt{nats.ManualAck(), nats.AckExplicit()}
+		if sub.options.Durable != "" {
+			options = append(options, nats.Durable(sub.options.Durable))
+		}
+		if sub.options.Queue != "" {
+			sub.sub, err = p.js.ChanQueueSubscribe(sub.subject, sub.options.Queue, messages, options...)
+		} else {
+			sub.sub, err = p.js.ChanSubscribe(sub.subject, messages, options...)
+		}
+	case sub.options.Queue != "":
+		sub.sub, err = p.conn.ChanQueueSubscribe(sub.subject, sub.options.Queue, messages)
+	default:
+		sub.sub, err = p.conn.ChanSubscribe(sub.subject, messages)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", sub.subject, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub.cancel = cancel
+	stopChan := p.stopChan
+	acknowledge := p.js != nil
+
+	p.wg.Add(1)
+	gorgo.Go(func() {
+		defer p.wg.Done()
+		defer cancel()
+
+		for {
+			select {
+			case <-stopChan:
+				p.drain(ctx, sub, messages, acknowledge)
+				return
+			case msg := <-messages:
+				p.handle(ctx, sub, msg, acknowledge)
+			}
+		}
+	})
+	return nil
+}
+
+// drain empties the message buffer of a stopped subscription
+func (p *NatsPlugin) drain(ctx context.Context, sub *subscription, messages chan *nats.Msg, acknowledge bool) {
+	for {
+		select {
+		case msg := <-messages:
+			if !acknowledge {
+				p.handle(ctx, sub, msg, false)
+			} else if err := msg.Nak(); err != nil {
+				log.Printf("NATS Plugin: Failed to acknowledge message on %s: %v", sub.subject, err)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *NatsPlugin) handle(ctx context.Context, sub *subscription, msg *nats.Msg, acknowledge bool) {
+	err := callHandler(ctx, sub.handler, msg)
+	if err != nil {
+		log.Printf("NATS Plugin: Handler for %s failed: %v", sub.subject, err)
+	}
+
+	if !acknowledge {
+		return
+	}
+	if err != nil {
+		err = msg.Nak()
+	} else {
+		err = msg.Ack()
+	}
+	if err != nil {
+		log.Printf("NATS Plugin: Failed to acknowledge message on %s: %v", sub.subject, err)
+	}
+}
+
+// callHandler runs handler, turning a panic into an error so one bad
+// message doesn't stop the subscription
+func callHandler(ctx context.Context, handler MessageHandler, msg *nats.Msg) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("NATS Plugin: Handler for %s panicked: %v\n%s", msg.Subject, r, debug.Stack())
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
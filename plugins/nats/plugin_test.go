@@ -0,0 +1,353 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/nats-io/nats.go"
+)
+
+// stubServer speaks enough of the NATS client protocol for the plugin:
+// CONNECT, PING, SUB, UNSUB and PUB with exact subject matching
+type stubServer struct {
+	ln net.Listener
+
+	mu   sync.Mutex
+	subs map[string]map[*stubSubscriber]struct{}
+}
+
+type stubSubscriber struct {
+	conn    net.Conn
+	mu      *sync.Mutex
+	sid     string
+	subject string
+}
+
+func newStubServer(t *testing.T) *stubServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &stubServer{ln: ln, subs: make(map[string]map[*stubSubscriber]struct{})}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *stubServer) URL() string {
+	return "nats://" + s.ln.Addr().String()
+}
+
+func (s *stubServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *stubServer) handle(conn net.Conn) {
+	defer conn.Close()
+	var writeMu sync.Mutex
+	write := func(line string) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		io.WriteString(conn, line)
+	}
+
+	write(`INFO {"server_id":"stub","version":"2.10.0","proto":1,"max_payload":1048576}` + "\r\n")
+
+	own := make(map[string]*stubSubscriber)
+	defer func() {
+		for _, sub := range own {
+			s.unsubscribe(sub)
+		}
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			write("PONG\r\n")
+		case "SUB":
+			// SUB <subject> [queue] <sid>
+			sub := &stubSubscriber{conn: conn, mu: &writeMu, subject: fields[1], sid: fields[len(fields)-1]}
+			own[sub.sid] = sub
+			s.mu.Lock()
+			if s.subs[sub.subject] == nil {
+				s.subs[sub.subject] = make(map[*stubSubscriber]struct{})
+			}
+			s.subs[sub.subject][sub] = struct{}{}
+			s.mu.Unlock()
+		case "UNSUB":
+			if sub, ok := own[fields[1]]; ok {
+				s.unsubscribe(sub)
+				delete(own, fields[1])
+			}
+		case "PUB":
+			// PUB <subject> [reply] <size>
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				return
+			}
+			s.publish(fields[1], payload[:size])
+		}
+	}
+}
+
+func (s *stubServer) unsubscribe(sub *stubSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs[sub.subject], sub)
+}
+
+func (s *stubServer) publish(subject string, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs[subject] {
+		sub.mu.Lock()
+		fmt.Fprintf(sub.conn, "MSG %s %s %d\r\n%s\r\n", subject, sub.sid, len(payload), payload)
+		sub.mu.Unlock()
+	}
+}
+
+func newTestPlugin(t *testing.T, url string) *NatsPlugin {
+	t.Helper()
+	plugin := NewNatsPlugin()
+	config := map[string]interface{}{"url": url, "max_reconnects": 0}
+	if err := plugin.Initialize(container.NewContainer(), config); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return plugin
+}
+
+func TestNatsPlugin_ValidateConfig(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"", false},
+		{"localhost:4222", false},
+		{"nats://localhost:4222,localhost:4223", false},
+		{"nats://localhost:4222", true},
+		{"nats://a:4222,tls://b:4222", true},
+	}
+
+	plugin := NewNatsPlugin()
+	for _, tt := range tests {
+		err := plugin.ValidateConfig(map[string]interface{}{"url": tt.url})
+		if (err == nil) != tt.valid {
+			t.Errorf("url %q: expected valid=%v, got %v", tt.url, tt.valid, err)
+		}
+	}
+}
+
+func TestNatsPlugin_SubscribeBeforeStart(t *testing.T) {
+	server := newStubServer(t)
+	plugin := newTestPlugin(t, server.URL())
+
+	received := make(chan string, 1)
+	err := plugin.Subscribe("orders.created", func(ctx context.Context, msg *nats.Msg) error {
+		received <- string(msg.Data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe before Start failed: %v", err)
+	}
+	if plugin.Conn() != nil {
+		t.Fatal("expected no connection before Start")
+	}
+	if err := plugin.Publish("orders.created", []byte("early")); err == nil {
+		t.Error("expected Publish to fail before Start")
+	}
+
+	if err := plugin.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer plugin.Stop(context.Background())
+
+	if err := plugin.Publish("orders.created", []byte("order-1")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	select {
+	case data := <-received:
+		if data != "order-1" {
+			t.Errorf("expected order-1, got %q", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued subscription did not receive the message")
+	}
+}
+
+func TestNatsPlugin_StopWhileHandling(t *testing.T) {
+	server := newStubServer(t)
+	plugin := newTestPlugin(t, server.URL())
+	if err := plugin.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	handling := make(chan struct{})
+	canceled := make(chan struct{})
+	err := plugin.Subscribe("jobs", func(ctx context.Context, msg *nats.Msg) error {
+		close(handling)
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	// The subscription is registered once the server answers a flush
+	if err := plugin.Conn().Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := plugin.Publish("jobs", []byte("job-1")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	select {
+	case <-handling:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler did not receive the message")
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- plugin.Stop(context.Background()) }()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("Stop failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop hung on a handler waiting for its context")
+	}
+
+	select {
+	case <-canceled:
+	default:
+		t.Error("expected the handler context to be canceled")
+	}
+	if plugin.Conn() != nil {
+		t.Error("expected the connection to be closed after Stop")
+	}
+}
+
+func TestNatsPlugin_HandlerPanic(t *testing.T) {
+	server := newStubServer(t)
+	plugin := newTestPlugin(t, server.URL())
+	if err := plugin.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer plugin.Stop(context.Background())
+
+	received := make(chan string, 3)
+	err := plugin.Subscribe("jobs", func(ctx context.Context, msg *nats.Msg) error {
+		if string(msg.Data) == "bad" {
+			panic("malformed job")
+		}
+		received <- string(msg.Data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := plugin.Conn().Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range []string{"job-1", "bad", "job-2", "bad", "job-3"} {
+		if err := plugin.Publish("jobs", []byte(data)); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	for _, expected := range []string{"job-1", "job-2", "job-3"} {
+		select {
+		case data := <-received:
+			if data != expected {
+				t.Errorf("expected %s, got %s", expected, data)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("subscription stopped after a panicking handler, %s not received", expected)
+		}
+	}
+}
+
+func TestNatsPlugin_StopDrainsBufferedMessages(t *testing.T) {
+	server := newStubServer(t)
+	plugin := newTestPlugin(t, server.URL())
+	if err := plugin.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	handling := make(chan struct{})
+	var mu sync.Mutex
+	var handled []string
+	err := plugin.Subscribe("jobs", func(ctx context.Context, msg *nats.Msg) error {
+		if string(msg.Data) == "job-1" {
+			close(handling)
+			<-ctx.Done()
+		}
+		mu.Lock()
+		handled = append(handled, string(msg.Data))
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	conn := plugin.Conn()
+	if err := conn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := plugin.Publish("jobs", []byte("job-1")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	select {
+	case <-handling:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler did not receive the message")
+	}
+	// Buffered behind the blocked handler; the flush makes sure they arrived
+	for _, data := range []string{"job-2", "job-3"} {
+		if err := plugin.Publish("jobs", []byte(data)); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	if err := conn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := plugin.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(handled, ",") != "job-1,job-2,job-3" {
+		t.Errorf("expected buffered messages to be handled on Stop, got %v", handled)
+	}
+}
@@ -0,0 +1,291 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+)
+
+// OpenAPIPlugin generates an OpenAPI 3 document from the registered
+// routes, serves it as JSON and renders it with Swagger UI
+type OpenAPIPlugin struct {
+	gorgo.BasePlugin
+	config OpenAPIConfig
+	router *gorgo.Router
+
+	mu         sync.RWMutex
+	operations map[string]Operation
+}
+
+type OpenAPIConfig struct {
+	Title       string `toml:"title"`
+	Version     string `toml:"version"`
+	Description string `toml:"description"`
+	SpecPath    string `toml:"spec_path"`
+	DocsPath    string `toml:"docs_path"`
+}
+
+// Operation is optional metadata describing a route
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	// RequestBody is an example value, its type becomes the JSON request schema
+	RequestBody interface{}
+	// Responses by status code
+	Responses map[int]Response
+}
+
+// Response describes a response of an operation
+type Response struct {
+	Description string
+	// Body is an example value, its type becomes the JSON response schema
+	Body interface{}
+}
+
+func NewOpenAPIPlugin() *OpenAPIPlugin {
+	metadata := gorgo.PluginMetadata{
+		Name:        "openapi",
+		Version:     "1.0.0",
+		Description: "OpenAPI 3 document generation and Swagger UI",
+		Author:      "Gorgo Framework",
+		Priority:    gorgo.PriorityLow,
+		Tags:        []string{"openapi", "swagger", "docs"},
+	}
+
+	return &OpenAPIPlugin{
+		BasePlugin: gorgo.NewBasePlugin(metadata),
+		operations: make(map[string]Operation),
+	}
+}
+
+// ConfigurablePlugin implementation
+func (p *OpenAPIPlugin) ValidateConfig(config map[string]interface{}) error {
+	for _, key := range []string{"spec_path", "docs_path"} {
+		if path := gorgo.GetStringConfig(config, key, ""); path != "" && !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("%s must start with '/'", key)
+		}
+	}
+	return nil
+}
+
+func (p *OpenAPIPlugin) GetDefaultConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"title":     "API",
+		"version":   "1.0.0",
+		"spec_path": "/openapi.json",
+		"docs_path": "/docs",
+	}
+}
+
+// ServiceProvider implementation
+func (p *OpenAPIPlugin) GetServices() map[string]interface{} {
+	return map[string]interface{}{
+		"openapi": p,
+	}
+}
+
+// RouteProvider implementation
+func (p *OpenAPIPlugin) GetRoutes() []gorgo.Route {
+	return []gorgo.Route{
+		{Method: "GET", Path: p.config.SpecPath, Handler: p.specHandler},
+		{Method: "GET", Path: p.config.DocsPath, Handler: p.docsHandler},
+	}
+}
+
+// Main plugin methods
+func (p *OpenAPIPlugin) Initialize(container *container.Container, config map[string]interface{}) error {
+	p.config = OpenAPIConfig{
+		Title:       gorgo.GetStringConfig(config, "title", "API"),
+		Version:     gorgo.GetStringConfig(config, "version", "1.0.0"),
+		Description: gorgo.GetStringConfig(config, "description", ""),
+		SpecPath:    gorgo.GetStringConfig(config, "spec_path", "/openapi.json"),
+		DocsPath:    gorgo.GetStringConfig(config, "docs_path", "/docs"),
+	}
+
+	router, ok := container.Get(gorgo.RouterService)
+	if !ok {
+		return fmt.Errorf("router service not found")
+	}
+	p.router, _ = router.(*gorgo.Router)
+
+	log.Printf("OpenAPI Plugin: Serving spec at %s and docs at %s", p.config.SpecPath, p.config.DocsPath)
+	return p.BasePlugin.Initialize(container, config)
+}
+
+// Describe attaches metadata to the route with the given method and path,
+// using the same path syntax the route was registered with
+//
+//	docs.Describe("GET", "/users/:id", openapi.Operation{
+//	    Summary:   "Get a user",
+//	    Responses: map[int]openapi.Response{200: {Description: "The user", Body: User{}}},
+//	})
+func (p *OpenAPIPlugin) Describe(method, path string, operation Operation) *OpenAPIPlugin {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.operations[strings.ToUpper(method)+" "+path] = operation
+	return p
+}
+
+// Spec builds the OpenAPI document for the currently registered routes
+func (p *OpenAPIPlugin) Spec() map[string]interface{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	info := map[string]interface{}{
+		"title":   p.config.Title,
+		"version": p.config.Version,
+	}
+	if p.config.Description != "" {
+		info["description"] = p.config.Description
+	}
+
+	paths := make(map[string]interface{})
+	if p.router != nil {
+		for _, route := range p.router.Routes() {
+			if route.Path == p.config.SpecPath || route.Path == p.config.DocsPath {
+				continue
+			}
+
+			path, params := openAPIPath(route.Path)
+			item, ok := paths[path].(map[string]interface{})
+			if !ok {
+				item = make(map[string]interface{})
+				paths[path] = item
+			}
+			item[strings.ToLower(route.Method)] = p.operation(route, params)
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    info,
+		"paths":   paths,
+	}
+}
+
+func (p *OpenAPIPlugin) operation(route gorgo.Route, params []pathParam) map[string]interface{} {
+	described := p.operations[route.Method+" "+route.Path]
+	op := make(map[string]interface{})
+
+	if described.Summary != "" {
+		op["summary"] = described.Summary
+	}
+	if described.Description != "" {
+		op["description"] = described.Description
+	}
+	if len(described.Tags) > 0 {
+		op["tags"] = described.Tags
+	}
+
+	if len(params) > 0 {
+		parameters := make([]interface{}, 0, len(params))
+		for _, param := range params {
+			parameter := map[string]interface{}{
+				"name":     param.name,
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "string"},
+			}
+			if param.wildcard {
+				parameter["description"] = "The rest of the path, may contain slashes"
+			}
+			parameters = append(parameters, parameter)
+		}
+		op["parameters"] = parameters
+	}
+
+	if described.RequestBody != nil {
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  jsonContent(described.RequestBody),
+		}
+	}
+
+	responses := make(map[string]interface{})
+	for status, response := range described.Responses {
+		description := response.Description
+		if description == "" {
+			description = http.StatusText(status)
+		}
+		entry := map[string]interface{}{"description": description}
+		if response.Body != nil {
+			entry["content"] = jsonContent(response.Body)
+		}
+		responses[strconv.Itoa(status)] = entry
+	}
+	if len(responses) == 0 {
+		responses["default"] = map[string]interface{}{"description": "Response"}
+	}
+	op["responses"] = responses
+
+	return op
+}
+
+func jsonContent(example interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema": SchemaOf(example),
+		},
+	}
+}
+
+// pathParam is a parameter of a route path
+type pathParam struct {
+	name string
+	// wildcard is set for a trailing *name segment
+	wildcard bool
+}
+
+// openAPIPath converts /users/:id to /users/{id} and /static/*filepath to
+// /static/{filepath} and returns the parameters
+func openAPIPath(path string) (string, []pathParam) {
+	var params []pathParam
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") || strings.HasPrefix(part, "*") {
+			params = append(params, pathParam{name: part[1:], wildcard: part[0] == '*'})
+			parts[i] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/"), params
+}
+
+func (p *OpenAPIPlugin) specHandler(ctx *gorgo.Context) error {
+	data, err := json.Marshal(p.Spec())
+	if err != nil {
+		return fmt.Errorf("failed to encode OpenAPI document: %w", err)
+	}
+
+	ctx.FastHTTP().Response.Header.SetContentType("application/json")
+	ctx.FastHTTP().SetBody(data)
+	return nil
+}
+
+func (p *OpenAPIPlugin) docsHandler(ctx *gorgo.Context) error {
+	return ctx.HTML(fmt.Sprintf(swaggerUITemplate, html.EscapeString(p.config.Title), p.config.SpecPath))
+}
+
+const swaggerUITemplate = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: %q, dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>`
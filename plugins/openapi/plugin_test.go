@@ -0,0 +1,66 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+)
+
+func TestOpenAPIPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		params   []pathParam
+	}{
+		{"/users", "/users", nil},
+		{"/users/:id", "/users/{id}", []pathParam{{name: "id"}}},
+		{"/users/:id/posts/:postId", "/users/{id}/posts/{postId}", []pathParam{{name: "id"}, {name: "postId"}}},
+		{"/static/*filepath", "/static/{filepath}", []pathParam{{name: "filepath", wildcard: true}}},
+		{"/buckets/:bucket/*key", "/buckets/{bucket}/{key}", []pathParam{{name: "bucket"}, {name: "key", wildcard: true}}},
+	}
+	for _, tt := range tests {
+		path, params := openAPIPath(tt.path)
+		if path != tt.expected || !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("%s: expected %s %v, got %s %v", tt.path, tt.expected, tt.params, path, params)
+		}
+	}
+}
+
+func TestOpenAPIPlugin_Spec(t *testing.T) {
+	router := gorgo.NewRouter()
+	handler := func(ctx *gorgo.Context) error { return nil }
+	router.AddRoute("GET", "/users/:id", handler)
+	router.AddRoute("GET", "/static/*filepath", handler)
+
+	c := container.NewContainer()
+	c.Replace(gorgo.RouterService, router)
+	plugin := NewOpenAPIPlugin()
+	if err := plugin.Initialize(c, map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	paths := plugin.Spec()["paths"].(map[string]interface{})
+	if _, ok := paths["/static/*filepath"]; ok {
+		t.Error("expected the wildcard not to be published literally")
+	}
+	item, ok := paths["/static/{filepath}"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected /static/{filepath} in %v", paths)
+	}
+	parameters := item["get"].(map[string]interface{})["parameters"].([]interface{})
+	if len(parameters) != 1 {
+		t.Fatalf("expected one path parameter, got %v", parameters)
+	}
+	parameter := parameters[0].(map[string]interface{})
+	if parameter["name"] != "filepath" || parameter["in"] != "path" || parameter["required"] != true || parameter["description"] == nil {
+		t.Errorf("unexpected wildcard parameter %v", parameter)
+	}
+
+	item = paths["/users/{id}"].(map[string]interface{})
+	parameter = item["get"].(map[string]interface{})["parameters"].([]interface{})[0].(map[string]interface{})
+	if parameter["name"] != "id" || parameter["description"] != nil {
+		t.Errorf("unexpected parameter %v", parameter)
+	}
+}
@@ -2,8 +2,12 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/GorgoFramework/gorgo/internal/container"
@@ -14,8 +18,14 @@ import (
 
 type RedisPlugin struct {
 	gorgo.BasePlugin
-	client *redis.Client
-	config RedisConfig
+	client   *redis.Client
+	config   RedisConfig
+	eventBus *gorgo.EventBus
+	// breaker guards Get, Set and Delete when breaker_threshold is set
+	breaker *gorgo.CircuitBreaker
+	// cacheBreaker skips cache lookups while Redis is unreachable. It is
+	// breaker when configured, otherwise a breaker with default settings.
+	cacheBreaker *gorgo.CircuitBreaker
 }
 
 type RedisConfig struct {
@@ -24,6 +34,12 @@ type RedisConfig struct {
 	Password string `toml:"password"`
 	DB       int    `toml:"db"`
 	PoolSize int    `toml:"pool_size"`
+	// InvalidateOnNotify deletes cached responses named by sql.notification payloads
+	InvalidateOnNotify bool `toml:"invalidate_on_notify"`
+	// BreakerThreshold consecutive connection failures open the circuit
+	// breaker of Get, Set and Delete, zero disables it
+	BreakerThreshold int           `toml:"breaker_threshold"`
+	BreakerTimeout   time.Duration `toml:"breaker_timeout"`
 }
 
 func NewRedisPlugin() *RedisPlugin {
@@ -52,11 +68,14 @@ func (p *RedisPlugin) ValidateConfig(config map[string]interface{}) error {
 
 func (p *RedisPlugin) GetDefaultConfig() map[string]interface{} {
 	return map[string]interface{}{
-		"host":      "localhost",
-		"port":      6379,
-		"password":  "",
-		"db":        0,
-		"pool_size": 10,
+		"host":                 "localhost",
+		"port":                 6379,
+		"password":             "",
+		"db":                   0,
+		"pool_size":            10,
+		"invalidate_on_notify": true,
+		"breaker_threshold":    0,
+		"breaker_timeout":      "30s",
 	}
 }
 
@@ -69,19 +88,79 @@ func (p *RedisPlugin) GetServices() map[string]interface{} {
 	}
 }
 
+// SetEventBus implements gorgo.EventPublisher
+func (p *RedisPlugin) SetEventBus(eventBus *gorgo.EventBus) {
+	p.eventBus = eventBus
+}
+
 // EventSubscriber implementation
 func (p *RedisPlugin) GetEventSubscriptions() map[string]gorgo.EventHandler {
 	return map[string]gorgo.EventHandler{
 		"request.completed": p.onRequestCompleted,
 		"app.stopping":      p.onAppStopping,
+		"sql.notification":  p.onSqlNotification,
+
+		gorgo.CircuitBreakerStateEvent: p.onBreakerStateChanged,
 	}
 }
 
+// onBreakerStateChanged logs when the response cache is disabled and
+// enabled again
+func (p *RedisPlugin) onBreakerStateChanged(event *gorgo.Event) error {
+	if p.cacheBreaker == nil || event.Data["name"] != p.cacheBreaker.Name() {
+		return nil
+	}
+
+	switch event.Data["to"] {
+	case gorgo.CircuitOpen.String():
+		log.Printf("Redis Plugin: Redis unreachable, response cache disabled for %v", p.config.BreakerTimeout)
+	case gorgo.CircuitClosed.String():
+		log.Println("Redis Plugin: Redis recovered, response cache enabled")
+	}
+	return nil
+}
+
 func (p *RedisPlugin) onRequestCompleted(event *gorgo.Event) error {
 	// Can add logic for caching responses
 	return nil
 }
 
+// onSqlNotification invalidates cached responses when the SQL plugin relays
+// a PostgreSQL NOTIFY. The payload is the cached request path (e.g. "/users/42")
+// or a glob pattern (e.g. "/users/*"); matching "cache:<payload>" keys are deleted.
+func (p *RedisPlugin) onSqlNotification(event *gorgo.Event) error {
+	if !p.config.InvalidateOnNotify || p.client == nil {
+		return nil
+	}
+
+	payload, _ := event.Data["payload"].(string)
+	if payload == "" {
+		return nil
+	}
+
+	ctx := context.Background()
+	pattern := fmt.Sprintf("cache:%s", payload)
+
+	if !strings.ContainsAny(payload, "*?[") {
+		if err := p.client.Del(ctx, pattern).Err(); err != nil {
+			log.Printf("Redis Plugin: Failed to invalidate %s: %v", pattern, err)
+		}
+		return nil
+	}
+
+	iter := p.client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		if err := p.client.Del(ctx, iter.Val()).Err(); err != nil {
+			log.Printf("Redis Plugin: Failed to invalidate %s: %v", iter.Val(), err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		log.Printf("Redis Plugin: Failed to scan %s: %v", pattern, err)
+	}
+
+	return nil
+}
+
 func (p *RedisPlugin) onAppStopping(event *gorgo.Event) error {
 	log.Println("Redis Plugin: Application stopping, clearing temporary cache...")
 	return nil
@@ -101,8 +180,13 @@ func (p *RedisPlugin) cacheMiddleware() gorgo.MiddlewareFunc {
 			if ctx.Method() == "GET" {
 				cacheKey := fmt.Sprintf("cache:%s", ctx.Path())
 
-				// Check cache
-				cached, err := p.client.Get(context.Background(), cacheKey).Result()
+				// Check cache, unless Redis is down: while the breaker is
+				// open the lookup fails fast and the request goes to the handler
+				var cached string
+				err := p.cacheBreaker.Execute(func() (err error) {
+					cached, err = p.client.Get(ctx.Context(), cacheKey).Result()
+					return err
+				})
 				if err == nil {
 					ctx.Header("X-Cache", "HIT")
 					return ctx.String(cached)
@@ -129,11 +213,35 @@ func (p *RedisPlugin) OnHotReload(newConfig map[string]interface{}) error {
 // Main plugin methods
 func (p *RedisPlugin) Initialize(container *container.Container, config map[string]interface{}) error {
 	p.config = RedisConfig{
-		Host:     getStringConfig(config, "host", "localhost"),
-		Port:     getIntConfig(config, "port", 6379),
-		Password: getStringConfig(config, "password", ""),
-		DB:       getIntConfig(config, "db", 0),
-		PoolSize: getIntConfig(config, "pool_size", 10),
+		Host:     gorgo.GetStringConfig(config, "host", "localhost"),
+		Port:     gorgo.GetIntConfig(config, "port", 6379),
+		Password: gorgo.GetStringConfig(config, "password", ""),
+		DB:       gorgo.GetIntConfig(config, "db", 0),
+		PoolSize: gorgo.GetIntConfig(config, "pool_size", 10),
+
+		InvalidateOnNotify: gorgo.GetBoolConfig(config, "invalidate_on_notify", true),
+		BreakerThreshold:   gorgo.GetIntConfig(config, "breaker_threshold", 0),
+		BreakerTimeout:     gorgo.GetDurationConfig(config, "breaker_timeout", 30*time.Second),
+	}
+
+	if p.config.BreakerThreshold > 0 {
+		p.breaker = gorgo.NewCircuitBreaker(gorgo.CircuitBreakerOptions{
+			Name:             "redis",
+			FailureThreshold: p.config.BreakerThreshold,
+			OpenTimeout:      p.config.BreakerTimeout,
+			IsFailure:        isConnectionError,
+			EventBus:         p.eventBus,
+		})
+	}
+
+	p.cacheBreaker = p.breaker
+	if p.cacheBreaker == nil {
+		p.cacheBreaker = gorgo.NewCircuitBreaker(gorgo.CircuitBreakerOptions{
+			Name:        "redis-cache",
+			OpenTimeout: p.config.BreakerTimeout,
+			IsFailure:   isConnectionError,
+			EventBus:    p.eventBus,
+		})
 	}
 
 	// Create Redis client
@@ -172,16 +280,31 @@ func (p *RedisPlugin) GetClient() *redis.Client {
 	return p.client
 }
 
+// Breaker returns the circuit breaker of Get, Set and Delete, nil unless
+// breaker_threshold is set
+func (p *RedisPlugin) Breaker() *gorgo.CircuitBreaker {
+	return p.breaker
+}
+
 func (p *RedisPlugin) Set(key string, value interface{}, expiration time.Duration) error {
-	return p.client.Set(context.Background(), key, value, expiration).Err()
+	return p.guard(func() error {
+		return p.client.Set(context.Background(), key, value, expiration).Err()
+	})
 }
 
 func (p *RedisPlugin) Get(key string) (string, error) {
-	return p.client.Get(context.Background(), key).Result()
+	var value string
+	err := p.guard(func() (err error) {
+		value, err = p.client.Get(context.Background(), key).Result()
+		return err
+	})
+	return value, err
 }
 
 func (p *RedisPlugin) Delete(key string) error {
-	return p.client.Del(context.Background(), key).Err()
+	return p.guard(func() error {
+		return p.client.Del(context.Background(), key).Err()
+	})
 }
 
 // Session middleware
@@ -212,21 +335,28 @@ func (p *RedisPlugin) SessionMiddleware(sessionName string) gorgo.MiddlewareFunc
 }
 
 // Helper functions
-func getStringConfig(config map[string]interface{}, key, defaultValue string) string {
-	if value, ok := config[key].(string); ok {
-		return value
+
+// guard runs fn through the circuit breaker, if enabled
+func (p *RedisPlugin) guard(fn func() error) error {
+	if p.breaker == nil {
+		return fn()
 	}
-	return defaultValue
+	return p.breaker.Execute(fn)
 }
 
-func getIntConfig(config map[string]interface{}, key string, defaultValue int) int {
-	if value, ok := config[key].(int); ok {
-		return value
-	}
-	if value, ok := config[key].(float64); ok {
-		return int(value)
+// isConnectionError reports whether err means Redis could not be reached.
+// Cache misses, errors replied by the server and the caller's own
+// cancellation or deadline don't count.
+func isConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
 	}
-	return defaultValue
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, redis.ErrClosed) ||
+		errors.Is(err, redis.ErrPoolTimeout)
 }
 
 func generateSessionID() string {
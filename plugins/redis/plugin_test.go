@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+	"github.com/redis/go-redis/v9"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCacheMiddleware_RedisDown(t *testing.T) {
+	plugin := NewRedisPlugin()
+	// Nothing listens on port 1, so every lookup fails to connect
+	config := map[string]interface{}{"host": "127.0.0.1", "port": 1, "breaker_timeout": "1h"}
+	if err := plugin.Initialize(container.NewContainer(), config); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	defer plugin.GetClient().Close()
+
+	calls := 0
+	handler := plugin.cacheMiddleware()(func(ctx *gorgo.Context) error {
+		calls++
+		return ctx.String("fresh")
+	})
+
+	threshold := gorgo.DefaultCircuitBreakerOptions().FailureThreshold
+	for i := 0; i < threshold+3; i++ {
+		fastCtx := &fasthttp.RequestCtx{}
+		fastCtx.Request.SetRequestURI("/users")
+		if err := handler(gorgo.NewContext(fastCtx, container.NewContainer(), nil)); err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		if string(fastCtx.Response.Body()) != "fresh" {
+			t.Errorf("request %d: expected handler response, got %q", i, fastCtx.Response.Body())
+		}
+	}
+
+	if calls != threshold+3 {
+		t.Errorf("expected every request to reach the handler, got %d of %d", calls, threshold+3)
+	}
+	if state := plugin.cacheBreaker.State(); state != gorgo.CircuitOpen {
+		t.Errorf("expected cache breaker to be open, got %s", state)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		err        error
+		connection bool
+	}{
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{io.EOF, true},
+		{redis.ErrClosed, true},
+		{redis.ErrPoolTimeout, true},
+		{redis.Nil, false},
+		{errors.New("redis: can't marshal map[string]string (implement encoding.BinaryMarshaler)"), false},
+		{context.Canceled, false},
+		{fmt.Errorf("get: %w", context.DeadlineExceeded), false},
+	}
+	for _, tt := range tests {
+		if got := isConnectionError(tt.err); got != tt.connection {
+			t.Errorf("%v: expected %v, got %v", tt.err, tt.connection, got)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+	// Day fields that exclude some days, used for the standard cron day rule
+	domRestricted bool
+	dowRestricted bool
+}
+
+type fieldBounds struct {
+	name     string
+	min, max int
+}
+
+var (
+	minuteBounds     = fieldBounds{"minute", 0, 59}
+	hourBounds       = fieldBounds{"hour", 0, 23}
+	dayOfMonthBounds = fieldBounds{"day of month", 1, 31}
+	monthBounds      = fieldBounds{"month", 1, 12}
+	dayOfWeekBounds  = fieldBounds{"day of week", 0, 7}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard five-field cron expression
+// (minute hour day-of-month month day-of-week). Fields support "*",
+// single values, ranges ("1-5"), lists ("1,15") and steps ("*/10", "0-30/5").
+// The day fields also accept "?" for any day. The descriptors @yearly,
+// @monthly, @weekly, @daily and @hourly are also accepted.
+//
+// When both day fields exclude some days, a day matches if either does,
+// as in standard cron: "0 0 1 * 1" runs on the 1st and on every Monday.
+func ParseCron(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if spec, ok := descriptors[expr]; ok {
+		expr = spec
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	// "?" means any day, as in Quartz
+	for _, i := range []int{2, 4} {
+		if fields[i] == "?" {
+			fields[i] = "*"
+		}
+	}
+
+	schedule := &Schedule{}
+	var err error
+
+	if schedule.minute, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfMonth, err = parseField(fields[2], dayOfMonthBounds); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if schedule.dayOfWeek, err = parseField(fields[4], dayOfWeekBounds); err != nil {
+		return nil, err
+	}
+	// 7 is an alias for Sunday
+	if schedule.dayOfWeek&(1<<7) != 0 {
+		schedule.dayOfWeek = schedule.dayOfWeek&^(1<<7) | 1
+	}
+
+	// A field covering every day, such as "*/1" or "0-6", restricts nothing
+	schedule.domRestricted = schedule.dayOfMonth != bitRange(1, 31)
+	schedule.dowRestricted = schedule.dayOfWeek != bitRange(0, 6)
+
+	return schedule, nil
+}
+
+func parseField(field string, bounds fieldBounds) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			rangePart = part[:idx]
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %q", bounds.name, part)
+			}
+			step = s
+		}
+
+		start, end := bounds.min, bounds.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			ends := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(ends[0]); err != nil {
+				return 0, fmt.Errorf("invalid range start in %q", part)
+			}
+			if end, err = strconv.Atoi(ends[1]); err != nil {
+				return 0, fmt.Errorf("invalid range end in %q", part)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in %s field: %q", bounds.name, part)
+			}
+			start = value
+			if step == 1 {
+				end = value
+			}
+		}
+
+		if start < bounds.min || end > bounds.max || start > end {
+			return 0, fmt.Errorf("%s field out of range [%d-%d]: %q", bounds.name, bounds.min, bounds.max, part)
+		}
+
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+
+	return bits, nil
+}
+
+// bitRange returns the bits min through max
+func bitRange(min, max int) uint64 {
+	var bits uint64
+	for i := min; i <= max; i++ {
+		bits |= 1 << uint(i)
+	}
+	return bits
+}
+
+// Next returns the first activation time strictly after t, in t's location.
+// A zero time is returned if no activation exists within five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dayOfMonth&(1<<uint(t.Day())) != 0
+	dowMatch := s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+
+	// Standard cron: when both day fields are restricted, either may match
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		field  string
+		bounds fieldBounds
+		bits   uint64
+	}{
+		{"*", hourBounds, bitRange(0, 23)},
+		{"5", minuteBounds, 1 << 5},
+		{"1-3", dayOfMonthBounds, 1<<1 | 1<<2 | 1<<3},
+		{"1,15", dayOfMonthBounds, 1<<1 | 1<<15},
+		{"*/20", minuteBounds, 1<<0 | 1<<20 | 1<<40},
+		{"0-30/10", minuteBounds, 1<<0 | 1<<10 | 1<<20 | 1<<30},
+		{"50/5", minuteBounds, 1<<50 | 1<<55},
+		{"1-2,10-11", monthBounds, 1<<1 | 1<<2 | 1<<10 | 1<<11},
+	}
+	for _, tt := range tests {
+		bits, err := parseField(tt.field, tt.bounds)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.field, err)
+			continue
+		}
+		if bits != tt.bits {
+			t.Errorf("%q: expected bits %b, got %b", tt.field, tt.bits, bits)
+		}
+	}
+
+	for _, field := range []string{"60", "5-1", "0-70", "*/0", "*/x", "a", "1-b", "", "0"} {
+		bounds := minuteBounds
+		if field == "0" {
+			bounds = dayOfMonthBounds
+		}
+		if _, err := parseField(field, bounds); err == nil {
+			t.Errorf("%q: expected an error", field)
+		}
+	}
+}
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{"* * * *", "* * * * * *", "61 * * * *", "* * 32 * *", "* * * 13 *", "* * * * 8"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+
+	tests := []struct {
+		expr                         string
+		domRestricted, dowRestricted bool
+	}{
+		{"0 0 * * *", false, false},
+		{"0 0 */1 * *", false, false},
+		{"0 0 ? * 1", false, true},
+		{"0 0 1 * ?", true, false},
+		{"0 0 1-31 * 0-6", false, false},
+		{"0 0 * * 1-7", false, false},
+		{"0 0 */2 * *", true, false},
+		{"0 0 1 * 1", true, true},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.expr, err)
+			continue
+		}
+		if schedule.domRestricted != tt.domRestricted || schedule.dowRestricted != tt.dowRestricted {
+			t.Errorf("%q: expected restricted (%v, %v), got (%v, %v)", tt.expr,
+				tt.domRestricted, tt.dowRestricted, schedule.domRestricted, schedule.dowRestricted)
+		}
+	}
+
+	schedule, err := ParseCron("0 0 * * 7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.dayOfWeek != 1 {
+		t.Errorf("expected 7 to mean Sunday, got %b", schedule.dayOfWeek)
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		expr, from, next string
+	}{
+		{"*/15 * * * *", "2024-03-10 10:07", "2024-03-10 10:15"},
+		{"*/15 * * * *", "2024-03-10 10:15", "2024-03-10 10:30"},
+		{"30 9 * * *", "2024-03-10 10:00", "2024-03-11 09:30"},
+		{"0 9-17/4 * * *", "2024-03-10 13:00", "2024-03-10 17:00"},
+		{"@hourly", "2024-03-10 10:59", "2024-03-10 11:00"},
+		// Month and year rollover
+		{"0 0 1 * *", "2024-01-31 12:00", "2024-02-01 00:00"},
+		{"@yearly", "2024-12-31 23:59", "2025-01-01 00:00"},
+		{"59 23 31 12 *", "2024-12-31 23:59", "2025-12-31 23:59"},
+		{"0 0 31 * *", "2024-04-01 00:00", "2024-05-31 00:00"},
+		{"0 0 29 2 *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		// Both day fields restricted: the 1st or a Monday (2024-03-11)
+		{"0 0 1 * 1", "2024-03-02 00:00", "2024-03-04 00:00"},
+		{"0 0 1 * 1", "2024-03-25 00:00", "2024-04-01 00:00"},
+		// Only one day field restricted: both must match
+		{"0 0 * * 1", "2024-03-05 00:00", "2024-03-11 00:00"},
+		{"0 0 */1 * 1", "2024-03-05 00:00", "2024-03-11 00:00"},
+		{"0 0 ? * 1", "2024-03-05 00:00", "2024-03-11 00:00"},
+		{"0 0 13 * *", "2024-03-05 00:00", "2024-03-13 00:00"},
+		{"0 0 13 * 0-6", "2024-03-05 00:00", "2024-03-13 00:00"},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.expr, err)
+			continue
+		}
+		if next := schedule.Next(at(tt.from)); !next.Equal(at(tt.next)) {
+			t.Errorf("%q after %s: expected %s, got %s", tt.expr, tt.from, tt.next, next.Format("2006-01-02 15:04"))
+		}
+	}
+
+	schedule, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := schedule.Next(at("2024-01-01 00:00")); !next.IsZero() {
+		t.Errorf("expected no activation for February 30, got %s", next)
+	}
+}
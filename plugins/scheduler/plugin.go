@@ -0,0 +1,274 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+)
+
+// JobFunc is a scheduled job
+type JobFunc func(ctx context.Context) error
+
+type SchedulerPlugin struct {
+	gorgo.BasePlugin
+	config   SchedulerConfig
+	location *time.Location
+	eventBus *gorgo.EventBus
+	// clock is the real time outside tests
+	clock clock
+
+	mu      sync.Mutex
+	jobs    []*job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+}
+
+type SchedulerConfig struct {
+	Timezone string `toml:"timezone"`
+}
+
+// clock tells the time and waits for it
+type clock interface {
+	Now() time.Time
+	// After returns a channel that receives once d has passed, and a
+	// function that stops the wait
+	After(d time.Duration) (<-chan time.Time, func() bool)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) (<-chan time.Time, func() bool) {
+	timer := time.NewTimer(d)
+	return timer.C, timer.Stop
+}
+
+type job struct {
+	name     string
+	spec     string
+	schedule *Schedule
+	fn       JobFunc
+	busy     atomic.Bool
+}
+
+func NewSchedulerPlugin() *SchedulerPlugin {
+	metadata := gorgo.PluginMetadata{
+		Name:        "scheduler",
+		Version:     "1.0.0",
+		Description: "Cron-style scheduled task plugin",
+		Author:      "Gorgo Framework",
+		Priority:    gorgo.PriorityLow,
+		Tags:        []string{"scheduler", "cron", "jobs"},
+	}
+
+	return &SchedulerPlugin{
+		BasePlugin: gorgo.NewBasePlugin(metadata),
+		location:   time.Local,
+		clock:      realClock{},
+	}
+}
+
+// ConfigurablePlugin implementation
+func (p *SchedulerPlugin) ValidateConfig(config map[string]interface{}) error {
+	if tz := gorgo.GetStringConfig(config, "timezone", ""); tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+	}
+	return nil
+}
+
+func (p *SchedulerPlugin) GetDefaultConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"timezone": "Local",
+	}
+}
+
+// ServiceProvider implementation
+func (p *SchedulerPlugin) GetServices() map[string]interface{} {
+	return map[string]interface{}{
+		"scheduler": p,
+	}
+}
+
+// EventPublisher implementation
+func (p *SchedulerPlugin) SetEventBus(eventBus *gorgo.EventBus) {
+	p.eventBus = eventBus
+}
+
+// Main plugin methods
+func (p *SchedulerPlugin) Initialize(container *container.Container, config map[string]interface{}) error {
+	p.config = SchedulerConfig{
+		Timezone: gorgo.GetStringConfig(config, "timezone", "Local"),
+	}
+
+	location, err := time.LoadLocation(p.config.Timezone)
+	if err != nil {
+		return fmt.Errorf("failed to load timezone %s: %w", p.config.Timezone, err)
+	}
+	p.location = location
+
+	log.Printf("Scheduler Plugin: Initialized with timezone %s", p.location)
+	return p.BasePlugin.Initialize(container, config)
+}
+
+func (p *SchedulerPlugin) Start(ctx context.Context) error {
+	p.mu.Lock()
+	p.ctx, p.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	p.running = true
+	for _, j := range p.jobs {
+		p.startJob(j)
+	}
+	count := len(p.jobs)
+	p.mu.Unlock()
+
+	log.Printf("Scheduler Plugin: Started %d jobs", count)
+	return p.BasePlugin.Start(ctx)
+}
+
+func (p *SchedulerPlugin) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.running = false
+	p.mu.Unlock()
+
+	// Wait for running jobs to finish
+	p.wg.Wait()
+
+	return p.BasePlugin.Stop(ctx)
+}
+
+// Schedule registers fn to run according to the cron expression.
+// Jobs registered before Start begin running once the plugin starts;
+// jobs registered afterwards are started immediately. A run is skipped
+// if the previous run of the same job is still executing. Each run
+// publishes job.started, then job.completed or job.failed; a panicking
+// job is recovered and reported as failed.
+func (p *SchedulerPlugin) Schedule(cronExpr string, fn JobFunc) error {
+	schedule, err := ParseCron(cronExpr)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	j := &job{
+		name:     fmt.Sprintf("job-%d", len(p.jobs)+1),
+		spec:     cronExpr,
+		schedule: schedule,
+		fn:       fn,
+	}
+	p.jobs = append(p.jobs, j)
+
+	if p.running {
+		p.startJob(j)
+	}
+
+	return nil
+}
+
+// startJob must be called with p.mu held
+func (p *SchedulerPlugin) startJob(j *job) {
+	ctx := p.ctx
+	p.wg.Add(1)
+
+	gorgo.Go(func() {
+		defer p.wg.Done()
+
+		for {
+			now := p.clock.Now().In(p.location)
+			next := j.schedule.Next(now)
+			if next.IsZero() {
+				log.Printf("Scheduler Plugin: Job %s (%s) has no future activations", j.name, j.spec)
+				return
+			}
+
+			fired, stop := p.clock.After(next.Sub(now))
+			select {
+			case <-ctx.Done():
+				stop()
+				return
+			case <-fired:
+			}
+
+			if !j.busy.CompareAndSwap(false, true) {
+				log.Printf("Scheduler Plugin: Skipping job %s, previous run still executing", j.name)
+				continue
+			}
+
+			p.wg.Add(1)
+			gorgo.Go(func() {
+				defer p.wg.Done()
+				defer j.busy.Store(false)
+				p.runJob(ctx, j)
+			})
+		}
+	})
+}
+
+func (p *SchedulerPlugin) runJob(ctx context.Context, j *job) {
+	p.publish(ctx, "job.started", map[string]interface{}{
+		"job":      j.name,
+		"schedule": j.spec,
+	})
+
+	start := time.Now()
+	err := callJob(ctx, j)
+	duration := time.Since(start)
+
+	if err != nil {
+		log.Printf("Scheduler Plugin: Job %s failed: %v", j.name, err)
+		p.publish(ctx, "job.failed", map[string]interface{}{
+			"job":      j.name,
+			"schedule": j.spec,
+			"duration": duration,
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	p.publish(ctx, "job.completed", map[string]interface{}{
+		"job":      j.name,
+		"schedule": j.spec,
+		"duration": duration,
+	})
+}
+
+// callJob runs the job, turning a panic into an error so it is reported
+// as job.failed
+func callJob(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Scheduler Plugin: Job %s panicked: %v\n%s", j.name, r, debug.Stack())
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return j.fn(ctx)
+}
+
+func (p *SchedulerPlugin) publish(ctx context.Context, eventName string, data map[string]interface{}) {
+	if p.eventBus == nil {
+		return
+	}
+	if err := p.eventBus.Publish(ctx, eventName, data); err != nil {
+		log.Printf("Scheduler Plugin: %v", err)
+	}
+}
+
+// GetConfig returns the plugin configuration
+func (p *SchedulerPlugin) GetConfig() SchedulerConfig {
+	return p.config
+}
@@ -0,0 +1,330 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/internal/container"
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+)
+
+// fakeClock only moves when advanced
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters map[*fakeWaiter]struct{}
+	// timers counts every After call
+	timers int
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, waiters: make(map[*fakeWaiter]struct{})}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) (<-chan time.Time, func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timers++
+	w := &fakeWaiter{deadline: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- c.now
+		return w.ch, func() bool { return false }
+	}
+	c.waiters[w] = struct{}{}
+	return w.ch, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		_, pending := c.waiters[w]
+		delete(c.waiters, w)
+		return pending
+	}
+}
+
+// Advance moves the clock and fires the waits that are due
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for w := range c.waiters {
+		if !w.deadline.After(c.now) {
+			w.ch <- c.now
+			delete(c.waiters, w)
+		}
+	}
+}
+
+// waitForTimers waits until After has been called n times in total
+func (c *fakeClock) waitForTimers(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mu.Lock()
+		timers := c.timers
+		c.mu.Unlock()
+		if timers >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d timers, got %d", n, timers)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// deadlines returns the pending wait deadlines
+func (c *fakeClock) deadlines() []time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var deadlines []time.Time
+	for w := range c.waiters {
+		deadlines = append(deadlines, w.deadline)
+	}
+	return deadlines
+}
+
+func newTestScheduler(t *testing.T, clock *fakeClock, config map[string]interface{}) (*SchedulerPlugin, chan *gorgo.Event) {
+	t.Helper()
+	plugin := NewSchedulerPlugin()
+	plugin.clock = clock
+	if err := plugin.Initialize(container.NewContainer(), config); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	events := make(chan *gorgo.Event, 16)
+	eventBus := gorgo.NewEventBus()
+	eventBus.Subscribe("job.*", func(event *gorgo.Event) error {
+		events <- event
+		return nil
+	})
+	plugin.SetEventBus(eventBus)
+	return plugin, events
+}
+
+func expectEvent(t *testing.T, events chan *gorgo.Event, name, job string) *gorgo.Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		if event.Name != name || event.Data["job"] != job {
+			t.Fatalf("expected %s for %s, got %s %v", name, job, event.Name, event.Data)
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected %s for %s", name, job)
+		return nil
+	}
+}
+
+func expectNoEvent(t *testing.T, events chan *gorgo.Event) {
+	t.Helper()
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %s %v", event.Name, event.Data)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSchedulerPlugin_JobEvents(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 3, 10, 10, 0, 30, 0, time.UTC))
+	plugin, events := newTestScheduler(t, clock, map[string]interface{}{"timezone": "UTC"})
+
+	runs := make(chan struct{}, 4)
+	if err := plugin.Schedule("* * * * *", func(ctx context.Context) error {
+		runs <- struct{}{}
+		return nil
+	}); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if len(clock.deadlines()) != 0 {
+		t.Fatal("expected jobs to wait for Start")
+	}
+
+	if err := plugin.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer plugin.Stop(context.Background())
+
+	clock.waitForTimers(t, 1)
+	clock.Advance(30 * time.Second)
+	expectEvent(t, events, "job.started", "job-1")
+	completed := expectEvent(t, events, "job.completed", "job-1")
+	if completed.Data["schedule"] != "* * * * *" {
+		t.Errorf("expected the schedule in the event, got %v", completed.Data)
+	}
+	<-runs
+
+	// Jobs scheduled after Start run right away on their schedule
+	clock.waitForTimers(t, 2)
+	if err := plugin.Schedule("*/2 * * * *", func(ctx context.Context) error {
+		return errors.New("disk full")
+	}); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if err := plugin.Schedule("*/2 * * * *", func(ctx context.Context) error {
+		panic("nil map")
+	}); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	clock.waitForTimers(t, 4)
+
+	// 10:02 runs all three jobs
+	clock.Advance(time.Minute)
+	failed := map[string]string{}
+	for i := 0; i < 6; i++ {
+		select {
+		case event := <-events:
+			if event.Name == "job.failed" {
+				failed[event.Data["job"].(string)] = event.Data["error"].(string)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected 6 events, got %d", i)
+		}
+	}
+	if failed["job-2"] != "disk full" {
+		t.Errorf("expected job-2 to fail with its error, got %q", failed["job-2"])
+	}
+	if !strings.Contains(failed["job-3"], "panicked: nil map") {
+		t.Errorf("expected job-3 to report its panic, got %q", failed["job-3"])
+	}
+	if _, ok := failed["job-1"]; ok {
+		t.Error("expected job-1 to complete")
+	}
+}
+
+func TestSchedulerPlugin_SkipsOverlappingRuns(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC))
+	plugin, events := newTestScheduler(t, clock, map[string]interface{}{"timezone": "UTC"})
+
+	release := make(chan struct{})
+	if err := plugin.Schedule("* * * * *", func(ctx context.Context) error {
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if err := plugin.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer plugin.Stop(context.Background())
+
+	clock.waitForTimers(t, 1)
+	clock.Advance(time.Minute)
+	expectEvent(t, events, "job.started", "job-1")
+
+	// The next activation finds the job still running and skips it
+	clock.waitForTimers(t, 2)
+	clock.Advance(time.Minute)
+	clock.waitForTimers(t, 3)
+	expectNoEvent(t, events)
+
+	release <- struct{}{}
+	expectEvent(t, events, "job.completed", "job-1")
+
+	clock.Advance(time.Minute)
+	expectEvent(t, events, "job.started", "job-1")
+	release <- struct{}{}
+	expectEvent(t, events, "job.completed", "job-1")
+}
+
+func TestSchedulerPlugin_Stop(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 3, 10, 10, 0, 0, 0, time.UTC))
+	plugin, events := newTestScheduler(t, clock, map[string]interface{}{"timezone": "UTC"})
+
+	if err := plugin.Schedule("* * * * *", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if err := plugin.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	clock.waitForTimers(t, 1)
+	clock.Advance(time.Minute)
+	expectEvent(t, events, "job.started", "job-1")
+	clock.waitForTimers(t, 2)
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- plugin.Stop(context.Background()) }()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Fatalf("Stop failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not cancel the running job")
+	}
+	failed := expectEvent(t, events, "job.failed", "job-1")
+	if failed.Data["error"] != context.Canceled.Error() {
+		t.Errorf("expected the job context to be canceled, got %v", failed.Data["error"])
+	}
+
+	if deadlines := clock.deadlines(); len(deadlines) != 0 {
+		t.Errorf("expected no pending activations after Stop, got %v", deadlines)
+	}
+	clock.Advance(time.Hour)
+	expectNoEvent(t, events)
+}
+
+func TestSchedulerPlugin_Timezone(t *testing.T) {
+	// 2024-03-11 is after the DST change in New York (UTC-4)
+	clock := newFakeClock(time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC))
+	plugin, events := newTestScheduler(t, clock, map[string]interface{}{"timezone": "America/New_York"})
+	if plugin.location.String() != "America/New_York" {
+		t.Fatalf("expected the configured timezone, got %s", plugin.location)
+	}
+
+	if err := plugin.Schedule("0 9 * * *", func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if err := plugin.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer plugin.Stop(context.Background())
+
+	clock.waitForTimers(t, 1)
+	deadlines := clock.deadlines()
+	if expected := time.Date(2024, 3, 11, 13, 0, 0, 0, time.UTC); len(deadlines) != 1 || !deadlines[0].Equal(expected) {
+		t.Fatalf("expected 9:00 New York time (%s), got %v", expected, deadlines)
+	}
+	clock.Advance(time.Hour)
+	expectEvent(t, events, "job.started", "job-1")
+	expectEvent(t, events, "job.completed", "job-1")
+}
+
+func TestSchedulerPlugin_Config(t *testing.T) {
+	plugin := NewSchedulerPlugin()
+	if err := plugin.ValidateConfig(map[string]interface{}{"timezone": "Mars/Olympus"}); err == nil {
+		t.Error("expected an invalid timezone to be rejected")
+	}
+	if err := plugin.ValidateConfig(map[string]interface{}{"timezone": "Europe/Berlin"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := plugin.Initialize(container.NewContainer(), map[string]interface{}{"timezone": "Mars/Olympus"}); err == nil {
+		t.Error("expected Initialize to fail for an invalid timezone")
+	}
+	if err := plugin.Schedule("* * *", func(ctx context.Context) error { return nil }); err == nil {
+		t.Error("expected an invalid cron expression to be rejected")
+	}
+	if err := plugin.Initialize(container.NewContainer(), map[string]interface{}{}); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	if plugin.location.String() != fmt.Sprint(time.Local) {
+		t.Errorf("expected the local timezone by default, got %s", plugin.location)
+	}
+}
@@ -0,0 +1,93 @@
+package sql
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// NotificationEvent is published for every PostgreSQL NOTIFY received on
+// one of the configured listen_channels. Data contains "channel" and "payload".
+const NotificationEvent = "sql.notification"
+
+// listenReconnectDelay is the pause before re-establishing a lost LISTEN connection
+const listenReconnectDelay = 5 * time.Second
+
+// SetEventBus implements gorgo.EventPublisher
+func (p *SqlPlugin) SetEventBus(eventBus *gorgo.EventBus) {
+	p.eventBus = eventBus
+}
+
+// startListener starts a background LISTEN loop for the configured channels
+func (p *SqlPlugin) startListener() {
+	if len(p.config.ListenChannels) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.listenCancel = cancel
+	p.listenDone = make(chan struct{})
+
+	gorgo.Go(func() {
+		defer close(p.listenDone)
+
+		for {
+			if err := p.listen(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("SQL Plugin: LISTEN connection lost: %v, reconnecting in %v", err, listenReconnectDelay)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(listenReconnectDelay):
+			}
+		}
+	})
+
+	log.Printf("SQL Plugin: Listening for notifications on %v", p.config.ListenChannels)
+}
+
+// stopListener stops the LISTEN loop and waits for it to exit
+func (p *SqlPlugin) stopListener() {
+	if p.listenCancel == nil {
+		return
+	}
+	p.listenCancel()
+	<-p.listenDone
+	p.listenCancel = nil
+}
+
+func (p *SqlPlugin) listen(ctx context.Context) error {
+	conn, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	for _, channel := range p.config.ListenChannels {
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return err
+		}
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		if p.eventBus == nil {
+			continue
+		}
+
+		if err := p.eventBus.Publish(ctx, NotificationEvent, map[string]interface{}{
+			"channel": notification.Channel,
+			"payload": notification.Payload,
+		}); err != nil {
+			log.Printf("SQL Plugin: %v", err)
+		}
+	}
+}
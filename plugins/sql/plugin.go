@@ -2,21 +2,47 @@ package sql
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
+	"strings"
+	"time"
 
 	"github.com/GorgoFramework/gorgo/internal/container"
 	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type SqlPlugin struct {
 	gorgo.BasePlugin
-	pool   *pgxpool.Pool
-	config SqlConfig
+	pool *pgxpool.Pool
+	// db runs the queries of Select and QueryRow, the pool outside tests
+	db       querier
+	config   SqlConfig
+	eventBus *gorgo.EventBus
+	// target describes the database for logs, without the password
+	target string
+	// breaker guards queries when breaker_threshold is set
+	breaker *gorgo.CircuitBreaker
+
+	listenCancel context.CancelFunc
+	listenDone   chan struct{}
+}
+
+// querier is the part of pgxpool.Pool used to run queries
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
 }
 
 type SqlConfig struct {
+	// DSN is a full connection string (URL or key=value form), also read
+	// from the "url" key. When set it is used instead of the fields below
+	// and may carry any pgx option, e.g. sslmode or application_name.
+	DSN      string `toml:"dsn"`
 	Host     string `toml:"host"`
 	Port     int    `toml:"port"`
 	User     string `toml:"user"`
@@ -24,6 +50,12 @@ type SqlConfig struct {
 	Database string `toml:"db"`
 	MaxConns int    `toml:"max_conns"`
 	MinConns int    `toml:"min_conns"`
+	// ListenChannels are LISTENed on, each NOTIFY is published as a sql.notification event
+	ListenChannels []string `toml:"listen_channels"`
+	// BreakerThreshold consecutive connection failures open the circuit
+	// breaker of Select and QueryRow, zero disables it
+	BreakerThreshold int           `toml:"breaker_threshold"`
+	BreakerTimeout   time.Duration `toml:"breaker_timeout"`
 }
 
 func NewSqlPlugin() *SqlPlugin {
@@ -34,6 +66,7 @@ func NewSqlPlugin() *SqlPlugin {
 		Author:      "Gorgo Framework",
 		Priority:    gorgo.PriorityHigh,
 		Tags:        []string{"database", "postgresql", "sql"},
+		Critical:    true,
 	}
 
 	return &SqlPlugin{
@@ -43,6 +76,13 @@ func NewSqlPlugin() *SqlPlugin {
 
 // ConfigurablePlugin implementation
 func (p *SqlPlugin) ValidateConfig(config map[string]interface{}) error {
+	if dsn := configDSN(config); dsn != "" {
+		if _, err := pgxpool.ParseConfig(dsn); err != nil {
+			return fmt.Errorf("invalid dsn: %w", err)
+		}
+		return nil
+	}
+
 	host, _ := config["host"].(string)
 	if host == "" {
 		return fmt.Errorf("host is required")
@@ -68,13 +108,17 @@ func (p *SqlPlugin) ValidateConfig(config map[string]interface{}) error {
 
 func (p *SqlPlugin) GetDefaultConfig() map[string]interface{} {
 	return map[string]interface{}{
-		"host":      "localhost",
-		"port":      5432,
-		"user":      "postgres",
-		"password":  "",
-		"db":        "",
-		"max_conns": 25,
-		"min_conns": 5,
+		"dsn":               "",
+		"host":              "localhost",
+		"port":              5432,
+		"user":              "postgres",
+		"password":          "",
+		"db":                "",
+		"max_conns":         25,
+		"min_conns":         5,
+		"listen_channels":   []string{},
+		"breaker_threshold": 0,
+		"breaker_timeout":   "30s",
 	}
 }
 
@@ -163,34 +207,45 @@ func (p *SqlPlugin) OnHotReload(newConfig map[string]interface{}) error {
 func (p *SqlPlugin) Initialize(container *container.Container, config map[string]interface{}) error {
 	// Parse configuration
 	p.config = SqlConfig{
-		Host:     getStringConfig(config, "host", "localhost"),
-		Port:     getIntConfig(config, "port", 5432),
-		User:     getStringConfig(config, "user", ""),
-		Password: getStringConfig(config, "password", ""),
-		Database: getStringConfig(config, "db", ""),
-		MaxConns: getIntConfig(config, "max_conns", 25),
-		MinConns: getIntConfig(config, "min_conns", 5),
+		DSN:      configDSN(config),
+		Host:     gorgo.GetStringConfig(config, "host", "localhost"),
+		Port:     gorgo.GetIntConfig(config, "port", 5432),
+		User:     gorgo.GetStringConfig(config, "user", ""),
+		Password: gorgo.GetStringConfig(config, "password", ""),
+		Database: gorgo.GetStringConfig(config, "db", ""),
+		MaxConns: gorgo.GetIntConfig(config, "max_conns", 25),
+		MinConns: gorgo.GetIntConfig(config, "min_conns", 5),
+
+		ListenChannels: gorgo.GetStringSliceConfig(config, "listen_channels"),
+
+		BreakerThreshold: gorgo.GetIntConfig(config, "breaker_threshold", 0),
+		BreakerTimeout:   gorgo.GetDurationConfig(config, "breaker_timeout", 30*time.Second),
 	}
 
-	// Create connection string
-	connString := fmt.Sprintf(
-		"postgres://%s:%s@%s:%d/%s?pool_max_conns=%d&pool_min_conns=%d",
-		p.config.User,
-		p.config.Password,
-		p.config.Host,
-		p.config.Port,
-		p.config.Database,
-		p.config.MaxConns,
-		p.config.MinConns,
-	)
+	if p.config.BreakerThreshold > 0 {
+		p.breaker = gorgo.NewCircuitBreaker(gorgo.CircuitBreakerOptions{
+			Name:             "sql",
+			FailureThreshold: p.config.BreakerThreshold,
+			OpenTimeout:      p.config.BreakerTimeout,
+			IsFailure:        isConnectionError,
+			EventBus:         p.eventBus,
+		})
+	}
+
+	poolConfig, err := p.poolConfig()
+	if err != nil {
+		return err
+	}
+	p.target = describeTarget(poolConfig)
 
 	// Create connection pool
-	pool, err := pgxpool.New(context.Background(), connString)
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
 	p.pool = pool
+	p.db = pool
 
 	// Call base initialization
 	return p.BasePlugin.Initialize(container, config)
@@ -199,13 +254,18 @@ func (p *SqlPlugin) Initialize(container *container.Container, config map[string
 func (p *SqlPlugin) Start(ctx context.Context) error {
 	// Check connection
 	if err := p.pool.Ping(ctx); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+		return fmt.Errorf("failed to ping database %s: %w", p.target, err)
 	}
+	log.Printf("SQL Plugin: Connected to %s", p.target)
+
+	p.startListener()
 
 	return p.BasePlugin.Start(ctx)
 }
 
 func (p *SqlPlugin) Stop(ctx context.Context) error {
+	p.stopListener()
+
 	if p.pool != nil {
 		p.pool.Close()
 	}
@@ -222,11 +282,17 @@ func (p *SqlPlugin) GetConfig() SqlConfig {
 	return p.config
 }
 
+// Breaker returns the circuit breaker of Select and QueryRow, nil unless
+// breaker_threshold is set
+func (p *SqlPlugin) Breaker() *gorgo.CircuitBreaker {
+	return p.breaker
+}
+
 // Middleware for automatic transaction management
 func (p *SqlPlugin) TransactionMiddleware() gorgo.MiddlewareFunc {
 	return func(next gorgo.HandlerFunc) gorgo.HandlerFunc {
 		return func(ctx *gorgo.Context) error {
-			tx, err := p.pool.Begin(context.Background())
+			tx, err := p.pool.Begin(ctx.Context())
 			if err != nil {
 				return fmt.Errorf("failed to begin transaction: %w", err)
 			}
@@ -273,19 +339,91 @@ func (p *SqlPlugin) sqlLoggingMiddleware() gorgo.MiddlewareFunc {
 }
 
 // Helper functions
-func getStringConfig(config map[string]interface{}, key, defaultValue string) string {
-	if value, ok := config[key].(string); ok {
-		return value
+
+// guard runs fn through the circuit breaker, if enabled
+func (p *SqlPlugin) guard(fn func() error) error {
+	if p.breaker == nil {
+		return fn()
 	}
-	return defaultValue
+	return p.breaker.Execute(fn)
 }
 
-func getIntConfig(config map[string]interface{}, key string, defaultValue int) int {
-	if value, ok := config[key].(int); ok {
-		return value
+// isConnectionError reports whether err means the database could not be
+// reached. Errors reported by the server, scan errors and the caller's
+// own cancellation or deadline don't count.
+func isConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
 	}
-	if value, ok := config[key].(float64); ok {
-		return int(value)
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		pgconn.SafeToRetry(err)
+}
+
+// configDSN returns the "dsn" config value, falling back to "url"
+func configDSN(config map[string]interface{}) string {
+	if dsn := gorgo.GetStringConfig(config, "dsn", ""); dsn != "" {
+		return dsn
+	}
+	return gorgo.GetStringConfig(config, "url", "")
+}
+
+// poolConfig parses the DSN, or a connection string assembled from the
+// individual fields. Pool sizes in the DSN (pool_max_conns,
+// pool_min_conns) take precedence over max_conns and min_conns.
+func (p *SqlPlugin) poolConfig() (*pgxpool.Config, error) {
+	if p.config.DSN == "" {
+		connString := fmt.Sprintf(
+			"postgres://%s:%s@%s:%d/%s?pool_max_conns=%d&pool_min_conns=%d",
+			p.config.User,
+			p.config.Password,
+			p.config.Host,
+			p.config.Port,
+			p.config.Database,
+			p.config.MaxConns,
+			p.config.MinConns,
+		)
+		poolConfig, err := pgxpool.ParseConfig(connString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid connection settings: %w", err)
+		}
+		return poolConfig, nil
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(p.config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+	if !strings.Contains(p.config.DSN, "pool_max_conns") && p.config.MaxConns > 0 {
+		poolConfig.MaxConns = int32(p.config.MaxConns)
+	}
+	if !strings.Contains(p.config.DSN, "pool_min_conns") {
+		poolConfig.MinConns = int32(p.config.MinConns)
+	}
+
+	// Keep the effective settings visible through GetConfig and sqlcfg
+	conn := poolConfig.ConnConfig
+	p.config.Host = conn.Host
+	p.config.Port = int(conn.Port)
+	p.config.User = conn.User
+	p.config.Password = conn.Password
+	p.config.Database = conn.Database
+	p.config.MaxConns = int(poolConfig.MaxConns)
+	p.config.MinConns = int(poolConfig.MinConns)
+
+	return poolConfig, nil
+}
+
+// describeTarget formats the connection target with the password redacted
+func describeTarget(poolConfig *pgxpool.Config) string {
+	conn := poolConfig.ConnConfig
+	target := fmt.Sprintf("postgres://%s@%s:%d/%s", conn.User, conn.Host, conn.Port, conn.Database)
+	if conn.Password != "" {
+		target = fmt.Sprintf("postgres://%s:%s@%s:%d/%s", conn.User, gorgo.RedactedValue, conn.Host, conn.Port, conn.Database)
 	}
-	return defaultValue
+	return target
 }
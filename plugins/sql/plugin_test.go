@@ -0,0 +1,270 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeRows is an in-memory pgx.Rows
+type fakeRows struct {
+	columns []string
+	values  [][]interface{}
+	current int
+	closed  bool
+}
+
+func (r *fakeRows) Close()                        { r.closed = true }
+func (r *fakeRows) Err() error                    { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag { return pgconn.CommandTag{} }
+func (r *fakeRows) RawValues() [][]byte           { return nil }
+func (r *fakeRows) Conn() *pgx.Conn               { return nil }
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	fields := make([]pgconn.FieldDescription, len(r.columns))
+	for i, name := range r.columns {
+		fields[i] = pgconn.FieldDescription{Name: name}
+	}
+	return fields
+}
+
+func (r *fakeRows) Next() bool {
+	if r.closed || r.current >= len(r.values) {
+		return false
+	}
+	r.current++
+	return true
+}
+
+func (r *fakeRows) Values() ([]interface{}, error) {
+	return r.values[r.current-1], nil
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	values := r.values[r.current-1]
+	if len(dest) != len(values) {
+		return fmt.Errorf("expected %d destinations, got %d", len(values), len(dest))
+	}
+	for i, value := range values {
+		target := reflect.ValueOf(dest[i]).Elem()
+		if value == nil {
+			target.Set(reflect.Zero(target.Type()))
+			continue
+		}
+		v := reflect.ValueOf(value)
+		if target.Kind() == reflect.Ptr {
+			ptr := reflect.New(target.Type().Elem())
+			ptr.Elem().Set(v)
+			v = ptr
+		}
+		target.Set(v)
+	}
+	return nil
+}
+
+// fakeQuerier answers every query with rows, or fails it with err
+type fakeQuerier struct {
+	rows *fakeRows
+	err  error
+}
+
+func (q *fakeQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	return q.rows, nil
+}
+
+func newTestPlugin(db querier) *SqlPlugin {
+	plugin := NewSqlPlugin()
+	plugin.db = db
+	return plugin
+}
+
+type Audit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type user struct {
+	Audit
+	ID       int
+	Username string  `db:"user_name"`
+	Email    *string `db:"email"`
+	Password string  `db:"-"`
+	secret   string
+	Ignored  string
+}
+
+func TestSqlPlugin_Select(t *testing.T) {
+	email := "alice@example.com"
+	rows := &fakeRows{
+		columns: []string{"id", "user_name", "email", "created_by"},
+		values: [][]interface{}{
+			{1, "alice", email, "admin"},
+			{2, "bob", nil, "system"},
+		},
+	}
+	plugin := newTestPlugin(&fakeQuerier{rows: rows})
+
+	var users []user
+	if err := plugin.Select(context.Background(), &users, "SELECT"); err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	expected := []user{
+		{ID: 1, Username: "alice", Email: &email, Audit: Audit{CreatedBy: "admin"}},
+		{ID: 2, Username: "bob", Audit: Audit{CreatedBy: "system"}},
+	}
+	if !reflect.DeepEqual(users, expected) {
+		t.Errorf("expected %+v, got %+v", expected, users)
+	}
+	if !rows.closed {
+		t.Error("expected the rows to be closed")
+	}
+
+	// Pointer elements
+	plugin = newTestPlugin(&fakeQuerier{rows: &fakeRows{columns: []string{"id"}, values: [][]interface{}{{7}}}})
+	var pointers []*user
+	if err := plugin.Select(context.Background(), &pointers, "SELECT"); err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if len(pointers) != 1 || pointers[0].ID != 7 {
+		t.Errorf("expected one user with ID 7, got %+v", pointers)
+	}
+
+	// No rows leaves an empty, non-nil slice
+	plugin = newTestPlugin(&fakeQuerier{rows: &fakeRows{columns: []string{"id"}}})
+	if err := plugin.Select(context.Background(), &users, "SELECT"); err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if users == nil || len(users) != 0 {
+		t.Errorf("expected an empty slice, got %#v", users)
+	}
+}
+
+func TestSqlPlugin_SelectUnmatchedColumns(t *testing.T) {
+	// Columns that only match skipped, unexported or misnamed fields
+	for _, column := range []string{"password", "secret", "username", "missing"} {
+		plugin := newTestPlugin(&fakeQuerier{rows: &fakeRows{columns: []string{"id", column}, values: [][]interface{}{{1, "x"}}}})
+		var users []user
+		err := plugin.Select(context.Background(), &users, "SELECT")
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("column %q has no matching field", column)) {
+			t.Errorf("column %s: expected a missing field error, got %v", column, err)
+		}
+	}
+
+	plugin := newTestPlugin(&fakeQuerier{rows: &fakeRows{}})
+	var ids []int
+	if err := plugin.Select(context.Background(), &ids, "SELECT"); err == nil {
+		t.Error("expected an error for a slice of non-structs")
+	}
+	var users []user
+	if err := plugin.Select(context.Background(), users, "SELECT"); err == nil {
+		t.Error("expected an error for a non-pointer dest")
+	}
+}
+
+func TestSqlPlugin_QueryRowNotFound(t *testing.T) {
+	plugin := newTestPlugin(&fakeQuerier{rows: &fakeRows{columns: []string{"id"}}})
+
+	var username string
+	err := plugin.QueryRow(context.Background(), "SELECT").Scan(&username)
+	if !errors.Is(err, gorgo.ErrNotFound) {
+		t.Errorf("expected gorgo.ErrNotFound from Scan, got %v", err)
+	}
+	var u user
+	if err := plugin.QueryRow(context.Background(), "SELECT").ScanStruct(&u); !errors.Is(err, gorgo.ErrNotFound) {
+		t.Errorf("expected gorgo.ErrNotFound from ScanStruct, got %v", err)
+	}
+
+	// Other errors are returned as is
+	queryErr := errors.New("connection reset")
+	plugin = newTestPlugin(&fakeQuerier{err: queryErr})
+	if err := plugin.QueryRow(context.Background(), "SELECT").Scan(&username); err != queryErr {
+		t.Errorf("expected the query error, got %v", err)
+	}
+
+	plugin = newTestPlugin(&fakeQuerier{rows: &fakeRows{columns: []string{"username"}, values: [][]interface{}{{"dave"}}}})
+	if err := plugin.QueryRow(context.Background(), "SELECT").Scan(&username); err != nil || username != "dave" {
+		t.Errorf("expected dave, got %q (%v)", username, err)
+	}
+
+	plugin = newTestPlugin(&fakeQuerier{rows: &fakeRows{columns: []string{"id", "user_name"}, values: [][]interface{}{{3, "carol"}}}})
+	if err := plugin.QueryRow(context.Background(), "SELECT").ScanStruct(&u); err != nil {
+		t.Fatalf("ScanStruct failed: %v", err)
+	}
+	if u.ID != 3 || u.Username != "carol" {
+		t.Errorf("expected user 3 carol, got %+v", u)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		err        error
+		connection bool
+	}{
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{fmt.Errorf("query: %w", io.EOF), true},
+		{io.ErrUnexpectedEOF, true},
+		{&pgconn.PgError{Code: "23505"}, false},
+		{pgx.ErrNoRows, false},
+		{pgx.ScanArgError{ColumnIndex: 0, Err: errors.New("cannot scan text into *int")}, false},
+		{errors.New("number of field descriptions must equal number of destinations"), false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, false},
+		{fmt.Errorf("timeout: %w", context.DeadlineExceeded), false},
+	}
+	for _, tt := range tests {
+		if got := isConnectionError(tt.err); got != tt.connection {
+			t.Errorf("%v: expected %v, got %v", tt.err, tt.connection, got)
+		}
+	}
+}
+
+func TestSqlPlugin_ScanErrorsKeepBreakerClosed(t *testing.T) {
+	rows := func() *fakeRows {
+		return &fakeRows{columns: []string{"id", "user_name"}, values: [][]interface{}{{1, "alice"}}}
+	}
+	plugin := newTestPlugin(&fakeQuerier{})
+	plugin.breaker = gorgo.NewCircuitBreaker(gorgo.CircuitBreakerOptions{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Hour,
+		IsFailure:        isConnectionError,
+	})
+
+	for i := 0; i < 5; i++ {
+		plugin.db = &fakeQuerier{rows: rows()}
+		var id int
+		if err := plugin.QueryRow(context.Background(), "SELECT").Scan(&id); err == nil {
+			t.Fatal("expected a scan error for a wrong destination count")
+		}
+		plugin.db = &fakeQuerier{rows: rows()}
+		var ids []struct{ ID int }
+		if err := plugin.Select(context.Background(), &ids, "SELECT"); err == nil {
+			t.Fatal("expected an error for an unmatched column")
+		}
+		plugin.db = &fakeQuerier{err: fmt.Errorf("query: %w", context.DeadlineExceeded)}
+		if err := plugin.Select(context.Background(), &ids, "SELECT"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the deadline error, got %v", err)
+		}
+	}
+	if state := plugin.breaker.State(); state != gorgo.CircuitClosed {
+		t.Fatalf("expected scan errors to leave the breaker closed, got %s", state)
+	}
+
+	plugin.db = &fakeQuerier{err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}
+	var id int
+	plugin.QueryRow(context.Background(), "SELECT").Scan(&id)
+	if state := plugin.breaker.State(); state != gorgo.CircuitOpen {
+		t.Errorf("expected a connection error to open the breaker, got %s", state)
+	}
+}
@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/GorgoFramework/gorgo/pkg/gorgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// Row is a single row query result. The query runs when Scan or
+// ScanStruct is called, which return gorgo.ErrNotFound when the query
+// matches no rows.
+type Row struct {
+	plugin *SqlPlugin
+	ctx    context.Context
+	sql    string
+	args   []interface{}
+}
+
+// QueryRow prepares a query returning at most one row
+//
+//	var username string
+//	err := plugin.QueryRow(ctx, "SELECT username FROM users WHERE id = $1", id).Scan(&username)
+//	if errors.Is(err, gorgo.ErrNotFound) { ... }
+func (p *SqlPlugin) QueryRow(ctx context.Context, sql string, args ...interface{}) *Row {
+	return &Row{plugin: p, ctx: ctx, sql: sql, args: args}
+}
+
+// Scan scans the columns of the first row into dest
+func (r *Row) Scan(dest ...interface{}) error {
+	rows, err := r.query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return gorgo.ErrNotFound
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	rows.Close()
+	return rows.Err()
+}
+
+// ScanStruct scans the first row into the struct pointed to by dest,
+// mapping columns to fields like Select
+func (r *Row) ScanStruct(dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("scan struct: dest must be a pointer to a struct, got %T", dest)
+	}
+
+	rows, err := r.query()
+	if err != nil {
+		return fmt.Errorf("scan struct: query failed: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("scan struct: %w", err)
+		}
+		return gorgo.ErrNotFound
+	}
+
+	fields, err := columnFields(rows.FieldDescriptions(), value.Elem().Type())
+	if err != nil {
+		return fmt.Errorf("scan struct: %w", err)
+	}
+	if err := rows.Scan(scanTargets(value.Elem(), fields)...); err != nil {
+		return fmt.Errorf("scan struct: scan failed: %w", err)
+	}
+
+	return nil
+}
+
+// query runs the query through the circuit breaker. Only the query is
+// guarded: scan errors are the caller's mistakes and never open the
+// breaker.
+func (r *Row) query() (pgx.Rows, error) {
+	var rows pgx.Rows
+	err := r.plugin.guard(func() (err error) {
+		rows, err = r.plugin.db.Query(r.ctx, r.sql, r.args...)
+		return err
+	})
+	return rows, err
+}
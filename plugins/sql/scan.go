@@ -0,0 +1,126 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Select runs the query and scans all rows into dest, which must be a
+// pointer to a slice of structs (or of pointers to structs). Columns are
+// matched to fields by their `db:"column"` tag, falling back to the
+// lowercased field name; `db:"-"` skips a field. Use pointer fields for
+// nullable columns. Every column must have a matching field, fields
+// without a column are left untouched.
+//
+//	var users []User
+//	err := plugin.Select(ctx, &users, "SELECT id, username FROM users")
+func (p *SqlPlugin) Select(ctx context.Context, dest interface{}, sql string, args ...interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("select: dest must be a pointer to a slice, got %T", dest)
+	}
+	slice = slice.Elem()
+
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("select: dest must be a slice of structs, got %s", slice.Type())
+	}
+
+	var rows pgx.Rows
+	err := p.guard(func() (err error) {
+		rows, err = p.db.Query(ctx, sql, args...)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("select: query failed: %w", err)
+	}
+	defer rows.Close()
+
+	fields, err := columnFields(rows.FieldDescriptions(), structType)
+	if err != nil {
+		return fmt.Errorf("select: %w", err)
+	}
+
+	result := reflect.MakeSlice(slice.Type(), 0, 0)
+	for rows.Next() {
+		elem := reflect.New(structType)
+		if err := rows.Scan(scanTargets(elem.Elem(), fields)...); err != nil {
+			return fmt.Errorf("select: scan failed: %w", err)
+		}
+
+		if elemType.Kind() == reflect.Ptr {
+			result = reflect.Append(result, elem)
+		} else {
+			result = reflect.Append(result, elem.Elem())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("select: %w", err)
+	}
+
+	slice.Set(result)
+	return nil
+}
+
+// columnFields returns the struct field index for each result column
+func columnFields(columns []pgconn.FieldDescription, structType reflect.Type) ([][]int, error) {
+	byName := make(map[string][]int)
+	collectFields(structType, nil, byName)
+
+	fields := make([][]int, len(columns))
+	for i, column := range columns {
+		index, ok := byName[column.Name]
+		if !ok {
+			return nil, fmt.Errorf("column %q has no matching field in %s", column.Name, structType)
+		}
+		fields[i] = index
+	}
+	return fields, nil
+}
+
+func collectFields(structType reflect.Type, parent []int, byName map[string][]int) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		index := append(append([]int{}, parent...), i)
+
+		tag := field.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+
+		// Flatten embedded structs without an explicit column name
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			collectFields(field.Type, index, byName)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if _, exists := byName[name]; !exists {
+			byName[name] = index
+		}
+	}
+}
+
+func scanTargets(elem reflect.Value, fields [][]int) []interface{} {
+	targets := make([]interface{}, len(fields))
+	for i, index := range fields {
+		targets[i] = elem.FieldByIndex(index).Addr().Interface()
+	}
+	return targets
+}